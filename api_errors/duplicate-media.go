@@ -0,0 +1,38 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type DuplicateMediaError struct {
+	ExistingID uuid.UUID
+}
+
+func (err *DuplicateMediaError) Error() string {
+	return fmt.Sprintf("the uploaded file has the same content as media {%s}", err.ExistingID.String())
+}
+
+func NewDuplicateMediaError(existingID uuid.UUID) error {
+	return &DuplicateMediaError{
+		ExistingID: existingID,
+	}
+}
+
+// DuplicateMediaResponse points to the existing media so clients can use it instead
+type DuplicateMediaResponse struct {
+	ErrorResponse
+	ExistingMedia uuid.UUID `json:"existingMedia"`
+}
+
+func HandleDuplicateMediaError(ctx context.Context, err *DuplicateMediaError) (int, any) {
+	return http.StatusConflict, DuplicateMediaResponse{
+		ErrorResponse: ErrorResponse{
+			Error: err.Error(),
+		},
+		ExistingMedia: err.ExistingID,
+	}
+}
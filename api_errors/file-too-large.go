@@ -0,0 +1,27 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type FileTooLargeError struct {
+	maxSize int64
+}
+
+func (err *FileTooLargeError) Error() string {
+	return fmt.Sprintf("uploaded file is too large, maximum size is %d bytes", err.maxSize)
+}
+
+func NewFileTooLargeError(maxSize int64) error {
+	return &FileTooLargeError{
+		maxSize: maxSize,
+	}
+}
+
+func HandleFileTooLargeError(ctx context.Context, err *FileTooLargeError) (int, any) {
+	return http.StatusRequestEntityTooLarge, ErrorResponse{
+		Error: err.Error(),
+	}
+}
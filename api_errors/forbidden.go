@@ -0,0 +1,28 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ForbiddenError is returned when an authenticated caller lacks the role or ownership an operation requires
+type ForbiddenError struct {
+	reason string
+}
+
+func (err *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: %s", err.reason)
+}
+
+func NewForbiddenError(reason string) error {
+	return &ForbiddenError{
+		reason: reason,
+	}
+}
+
+func HandleForbiddenError(ctx context.Context, err *ForbiddenError) (int, any) {
+	return http.StatusForbidden, ErrorResponse{
+		Error: err.Error(),
+	}
+}
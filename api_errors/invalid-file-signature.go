@@ -0,0 +1,30 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// InvalidFileSignatureError is returned when a file is requested without a valid, unexpired signed URL
+type InvalidFileSignatureError struct {
+	key    string
+	reason string
+}
+
+func (err *InvalidFileSignatureError) Error() string {
+	return fmt.Sprintf("access to file {%s} denied: %s", err.key, err.reason)
+}
+
+func NewInvalidFileSignatureError(key string, reason string) error {
+	return &InvalidFileSignatureError{
+		key:    key,
+		reason: reason,
+	}
+}
+
+func HandleInvalidFileSignatureError(ctx context.Context, err *InvalidFileSignatureError) (int, any) {
+	return http.StatusForbidden, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -7,16 +7,32 @@ import (
 )
 
 type InvalidFileTypeError struct {
-	expectedFileType string
+	claimedType  string
+	detectedType string
+	reason       string
 }
 
 func (err *InvalidFileTypeError) Error() string {
-	return fmt.Sprintf("invalid file type for uploaded file,expected %s", err.expectedFileType)
+	claimedType := err.claimedType
+	if claimedType == "" {
+		claimedType = "nothing"
+	}
+	return fmt.Sprintf("invalid file type for uploaded file, claimed %s and detected %s: %s", claimedType, err.detectedType, err.reason)
+}
+
+func (err *InvalidFileTypeError) ClaimedType() string {
+	return err.claimedType
+}
+
+func (err *InvalidFileTypeError) DetectedType() string {
+	return err.detectedType
 }
 
-func NewInvalidFileTypeError(expectedFileType string) error {
+func NewInvalidFileTypeError(claimedType string, detectedType string, reason string) error {
 	return &InvalidFileTypeError{
-		expectedFileType: expectedFileType,
+		claimedType:  claimedType,
+		detectedType: detectedType,
+		reason:       reason,
 	}
 }
 
@@ -0,0 +1,29 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type InvalidParameterError struct {
+	parameter string
+	reason    string
+}
+
+func (err *InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter %s: %s", err.parameter, err.reason)
+}
+
+func NewInvalidParameterError(parameter string, reason string) error {
+	return &InvalidParameterError{
+		parameter: parameter,
+		reason:    reason,
+	}
+}
+
+func HandleInvalidParameterError(ctx context.Context, err *InvalidParameterError) (int, any) {
+	return http.StatusBadRequest, ErrorResponse{
+		Error: err.Error(),
+	}
+}
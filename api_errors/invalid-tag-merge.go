@@ -0,0 +1,33 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type InvalidTagMergeError struct {
+	sourceID uuid.UUID
+	targetID uuid.UUID
+	reason   string
+}
+
+func (err *InvalidTagMergeError) Error() string {
+	return fmt.Sprintf("tag %s cannot be merged into %s: %s", err.sourceID, err.targetID, err.reason)
+}
+
+func NewInvalidTagMergeError(sourceID uuid.UUID, targetID uuid.UUID, reason string) error {
+	return &InvalidTagMergeError{
+		sourceID: sourceID,
+		targetID: targetID,
+		reason:   reason,
+	}
+}
+
+func HandleInvalidTagMergeError(ctx context.Context, err *InvalidTagMergeError) (int, any) {
+	return http.StatusConflict, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -43,3 +43,23 @@ func NewRecordsNotFoundWithIDs(IDs []uuid.UUID) error {
 		IDs: IDs,
 	}
 }
+
+type FileNotFoundError struct {
+	Key string
+}
+
+func (err *FileNotFoundError) Error() string {
+	return fmt.Sprintf("File {%s} not found", err.Key)
+}
+
+func NewFileNotFoundError(key string) error {
+	return &FileNotFoundError{
+		Key: key,
+	}
+}
+
+func HandleFileNotFoundError(ctx context.Context, err *FileNotFoundError) (int, any) {
+	return http.StatusNotFound, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -0,0 +1,31 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type NotRenderableError struct {
+	ID     uuid.UUID
+	reason string
+}
+
+func (err *NotRenderableError) Error() string {
+	return fmt.Sprintf("media with ID {%s} can't be rendered: %s", err.ID.String(), err.reason)
+}
+
+func NewNotRenderableError(ID uuid.UUID, reason string) error {
+	return &NotRenderableError{
+		ID:     ID,
+		reason: reason,
+	}
+}
+
+func HandleNotRenderableError(ctx context.Context, err *NotRenderableError) (int, any) {
+	return http.StatusUnprocessableEntity, ErrorResponse{
+		Error: err.Error(),
+	}
+}
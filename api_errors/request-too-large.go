@@ -0,0 +1,27 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type RequestTooLargeError struct {
+	maxSize int64
+}
+
+func (err *RequestTooLargeError) Error() string {
+	return fmt.Sprintf("request body is too large, maximum size is %d bytes", err.maxSize)
+}
+
+func NewRequestTooLargeError(maxSize int64) error {
+	return &RequestTooLargeError{
+		maxSize: maxSize,
+	}
+}
+
+func HandleRequestTooLargeError(ctx context.Context, err *RequestTooLargeError) (int, any) {
+	return http.StatusRequestEntityTooLarge, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -0,0 +1,31 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type TagCycleError struct {
+	tagID    uuid.UUID
+	parentID uuid.UUID
+}
+
+func (err *TagCycleError) Error() string {
+	return fmt.Sprintf("tag %s cannot be placed below %s, the tag would become its own ancestor", err.tagID, err.parentID)
+}
+
+func NewTagCycleError(tagID uuid.UUID, parentID uuid.UUID) error {
+	return &TagCycleError{
+		tagID:    tagID,
+		parentID: parentID,
+	}
+}
+
+func HandleTagCycleError(ctx context.Context, err *TagCycleError) (int, any) {
+	return http.StatusConflict, ErrorResponse{
+		Error: err.Error(),
+	}
+}
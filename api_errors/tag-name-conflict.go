@@ -0,0 +1,27 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type TagNameConflictError struct {
+	name string
+}
+
+func (err *TagNameConflictError) Error() string {
+	return fmt.Sprintf("the name %s is already used by a tag or alias", err.name)
+}
+
+func NewTagNameConflictError(name string) error {
+	return &TagNameConflictError{
+		name: name,
+	}
+}
+
+func HandleTagNameConflictError(ctx context.Context, err *TagNameConflictError) (int, any) {
+	return http.StatusConflict, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -0,0 +1,28 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// UnauthorizedError is returned when a request to the API comes without valid credentials
+type UnauthorizedError struct {
+	reason string
+}
+
+func (err *UnauthorizedError) Error() string {
+	return fmt.Sprintf("authentication required: %s", err.reason)
+}
+
+func NewUnauthorizedError(reason string) error {
+	return &UnauthorizedError{
+		reason: reason,
+	}
+}
+
+func HandleUnauthorizedError(ctx context.Context, err *UnauthorizedError) (int, any) {
+	return http.StatusUnauthorized, ErrorResponse{
+		Error: err.Error(),
+	}
+}
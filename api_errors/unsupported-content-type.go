@@ -0,0 +1,29 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type UnsupportedContentTypeError struct {
+	contentType string
+	expected    string
+}
+
+func (err *UnsupportedContentTypeError) Error() string {
+	return fmt.Sprintf("unsupported content type %q, expected %s", err.contentType, err.expected)
+}
+
+func NewUnsupportedContentTypeError(contentType string, expected string) error {
+	return &UnsupportedContentTypeError{
+		contentType: contentType,
+		expected:    expected,
+	}
+}
+
+func HandleUnsupportedContentTypeError(ctx context.Context, err *UnsupportedContentTypeError) (int, any) {
+	return http.StatusUnsupportedMediaType, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -0,0 +1,27 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type UnsupportedTusVersionError struct {
+	version string
+}
+
+func (err *UnsupportedTusVersionError) Error() string {
+	return fmt.Sprintf("unsupported tus version %q", err.version)
+}
+
+func NewUnsupportedTusVersionError(version string) error {
+	return &UnsupportedTusVersionError{
+		version: version,
+	}
+}
+
+func HandleUnsupportedTusVersionError(ctx context.Context, err *UnsupportedTusVersionError) (int, any) {
+	return http.StatusPreconditionFailed, ErrorResponse{
+		Error: err.Error(),
+	}
+}
@@ -0,0 +1,28 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// UploadConflictError is returned when a chunk of a resumable upload doesn't continue where the upload left off
+type UploadConflictError struct {
+	reason string
+}
+
+func (err *UploadConflictError) Error() string {
+	return fmt.Sprintf("upload conflict: %s", err.reason)
+}
+
+func NewUploadConflictError(reason string) error {
+	return &UploadConflictError{
+		reason: reason,
+	}
+}
+
+func HandleUploadConflictError(ctx context.Context, err *UploadConflictError) (int, any) {
+	return http.StatusConflict, ErrorResponse{
+		Error: err.Error(),
+	}
+}
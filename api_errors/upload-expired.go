@@ -0,0 +1,29 @@
+package apierrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type UploadExpiredError struct {
+	ID uuid.UUID
+}
+
+func (err *UploadExpiredError) Error() string {
+	return fmt.Sprintf("upload with ID {%s} has expired", err.ID.String())
+}
+
+func NewUploadExpiredError(ID uuid.UUID) error {
+	return &UploadExpiredError{
+		ID: ID,
+	}
+}
+
+func HandleUploadExpiredError(ctx context.Context, err *UploadExpiredError) (int, any) {
+	return http.StatusGone, ErrorResponse{
+		Error: err.Error(),
+	}
+}
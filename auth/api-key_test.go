@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewAPIKey_ReturnsTheHashOfARandomKey(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	key, hash, err := NewAPIKey()
+	other, _, otherErr := NewAPIKey()
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, otherErr)
+	assert.True(t, IsAPIKey(key))
+	assert.Equal(t, HashAPIKey(key), hash)
+	assert.NotEqual(t, key, other)
+	assert.NotContains(t, hash, key)
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+
+	// MinSecretLength is the minimum length of an HS256 secret, shorter secrets can be brute forced from a token
+	MinSecretLength = 32
+
+	// leeway allows for clocks that are slightly apart when checking the expiry and start of tokens
+	leeway = time.Minute
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnknownKey       = errors.New("the token is not signed with a known key")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrExpiredToken     = errors.New("the token has expired")
+	ErrInvalidClaims    = errors.New("invalid token claims")
+)
+
+// JWTConfig configures the keys and claims of accepted JSON Web Tokens, tokens are rejected without any keys
+type JWTConfig struct {
+	// HMACSecrets verify HS256 signed tokens
+	HMACSecrets []string `yaml:"hmacSecrets"`
+	// PublicKeyFiles are PEM encoded RSA public keys verifying RS256 signed tokens
+	PublicKeyFiles []string `yaml:"publicKeyFiles"`
+	// JWKSFile is a JSON Web Key Set holding RSA and symmetric keys, they are matched with tokens by their key ID
+	JWKSFile string `yaml:"jwksFile"`
+	// Issuer is the required iss claim, empty accepts any issuer
+	Issuer string `yaml:"issuer"`
+	// Audience has to be one of the aud claims, empty accepts any audience
+	Audience string `yaml:"audience"`
+	// DefaultRole is the role of tokens without a known role in their roles claim, viewer when empty
+	DefaultRole string `yaml:"defaultRole"`
+}
+
+// Empty reports whether no keys are configured
+func (config *JWTConfig) Empty() bool {
+	return len(config.HMACSecrets) == 0 && len(config.PublicKeyFiles) == 0 && config.JWKSFile == ""
+}
+
+// Claims are the claims of a verified token
+type Claims struct {
+	Subject   string     `json:"sub"`
+	Name      string     `json:"name"`
+	Issuer    string     `json:"iss"`
+	Audience  stringList `json:"aud"`
+	ExpiresAt *float64   `json:"exp"`
+	NotBefore *float64   `json:"nbf"`
+	Roles     stringList `json:"roles"`
+	// Role is the most privileged known role of the roles claim, or the default role when it has none
+	Role Role `json:"-"`
+}
+
+// stringList is a claim holding either a single string or an array of strings
+type stringList []string
+
+func (claim *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*claim = stringList{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*claim = multiple
+	return nil
+}
+
+// verificationKey verifies the signatures of a single algorithm
+type verificationKey struct {
+	// id is the kid of a key from a key set, empty for keys that are configured directly
+	id        string
+	algorithm string
+	secret    []byte
+	public    *rsa.PublicKey
+}
+
+func (key *verificationKey) verify(input string, signature []byte) bool {
+	switch key.algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write([]byte(input))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case AlgorithmRS256:
+		digest := sha256.Sum256([]byte(input))
+		return rsa.VerifyPKCS1v15(key.public, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// TokenVerifier verifies HS256 and RS256 signed JSON Web Tokens along with their time, issuer and audience claims
+type TokenVerifier struct {
+	keys        []*verificationKey
+	issuer      string
+	audience    string
+	defaultRole Role
+	now         func() time.Time
+}
+
+// NewTokenVerifier loads the keys of the configuration
+func NewTokenVerifier(config JWTConfig) (*TokenVerifier, error) {
+	verifier := &TokenVerifier{issuer: config.Issuer, audience: config.Audience, defaultRole: RoleViewer, now: time.Now}
+	if config.DefaultRole != "" {
+		role, err := ParseRole(config.DefaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default role: %w", err)
+		}
+		verifier.defaultRole = role
+	}
+
+	for _, secret := range config.HMACSecrets {
+		if len(secret) < MinSecretLength {
+			return nil, fmt.Errorf("HMAC secrets need at least %d characters", MinSecretLength)
+		}
+		verifier.keys = append(verifier.keys, &verificationKey{algorithm: AlgorithmHS256, secret: []byte(secret)})
+	}
+	for _, path := range config.PublicKeyFiles {
+		public, err := readPublicKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = append(verifier.keys, &verificationKey{algorithm: AlgorithmRS256, public: public})
+	}
+	if config.JWKSFile != "" {
+		keys, err := readKeySetFile(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = append(verifier.keys, keys...)
+	}
+
+	return verifier, nil
+}
+
+// Verify checks the signature and claims of a token in compact serialization
+func (verifier *TokenVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	// the algorithm has to match the key, so a public key is never used as an HMAC secret
+	candidates := slices.DeleteFunc(slices.Clone(verifier.keys), func(key *verificationKey) bool {
+		return key.algorithm != header.Algorithm || (header.KeyID != "" && key.id != "" && key.id != header.KeyID)
+	})
+	if len(candidates) == 0 {
+		return nil, ErrUnknownKey
+	}
+	valid := slices.ContainsFunc(candidates, func(key *verificationKey) bool {
+		return key.verify(parts[0]+"."+parts[1], signature)
+	})
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := verifier.validate(claims); err != nil {
+		return nil, err
+	}
+
+	role, found := highestRole(claims.Roles)
+	if !found {
+		role = verifier.defaultRole
+	}
+	claims.Role = role
+
+	return claims, nil
+}
+
+// validate checks the claims of a token with a valid signature, tokens without an expiry are rejected
+func (verifier *TokenVerifier) validate(claims *Claims) error {
+	now := verifier.now()
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: the exp claim is required", ErrInvalidClaims)
+	}
+	if now.Add(-leeway).After(numericDate(*claims.ExpiresAt)) {
+		return ErrExpiredToken
+	}
+	if claims.NotBefore != nil && now.Add(leeway).Before(numericDate(*claims.NotBefore)) {
+		return fmt.Errorf("%w: the token is not valid yet", ErrInvalidClaims)
+	}
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: the sub claim is required", ErrInvalidClaims)
+	}
+	if verifier.issuer != "" && claims.Issuer != verifier.issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidClaims, claims.Issuer)
+	}
+	if verifier.audience != "" && !slices.Contains(claims.Audience, verifier.audience) {
+		return fmt.Errorf("%w: the token is not meant for this API", ErrInvalidClaims)
+	}
+
+	return nil
+}
+
+// numericDate converts seconds since the epoch, which may have a fraction, to a time
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, target any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, target)
+}
@@ -0,0 +1,313 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var secret = strings.Repeat("s", MinSecretLength)
+
+// signToken creates a token signed with an HMAC secret or an RSA private key
+func signToken(t *testing.T, header map[string]any, claims map[string]any, key any) string {
+	t.Helper()
+
+	encode := func(value any) string {
+		encoded, err := json.Marshal(value)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(encoded)
+	}
+	input := encode(header) + "." + encode(claims)
+
+	var signature []byte
+	switch key := key.(type) {
+	case string:
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims are claims of a token that expires in an hour
+func validClaims() map[string]any {
+	return map[string]any{"sub": "user-1", "name": "User", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+// writeFile writes the content to a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+func Test_TokenVerifier_Verify_AcceptsHS256Tokens(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	verifier, err := NewTokenVerifier(JWTConfig{HMACSecrets: []string{secret}})
+	require.NoError(t, err)
+	token := signToken(t, map[string]any{"alg": "HS256", "typ": "JWT"}, validClaims(), secret)
+
+	// Act
+	claims, err := verifier.Verify(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "User", claims.Name)
+}
+
+func Test_TokenVerifier_Verify_UsesTheHighestKnownRole(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		roles        any
+		defaultRole  string
+		expectedRole Role
+	}{
+		"single role": {
+			roles:        "uploader",
+			expectedRole: RoleUploader,
+		},
+		"multiple roles": {
+			roles:        []string{"viewer", "admin", "uploader"},
+			expectedRole: RoleAdmin,
+		},
+		"unknown roles": {
+			roles:        []string{"owner", "editor"},
+			expectedRole: RoleViewer,
+		},
+		"configured default role": {
+			defaultRole:  "uploader",
+			expectedRole: RoleUploader,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			verifier, err := NewTokenVerifier(JWTConfig{HMACSecrets: []string{secret}, DefaultRole: test.defaultRole})
+			require.NoError(t, err)
+			claims := validClaims()
+			if test.roles != nil {
+				claims["roles"] = test.roles
+			}
+			token := signToken(t, map[string]any{"alg": "HS256"}, claims, secret)
+
+			// Act
+			verified, err := verifier.Verify(token)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRole, verified.Role)
+		})
+	}
+}
+
+func Test_TokenVerifier_Verify_AcceptsRS256TokensOfPublicKeyFiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	key := newRSAKey(t)
+	encoded, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	path := writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: encoded}))
+
+	verifier, err := NewTokenVerifier(JWTConfig{PublicKeyFiles: []string{path}})
+	require.NoError(t, err)
+	token := signToken(t, map[string]any{"alg": "RS256"}, validClaims(), key)
+
+	// Act
+	claims, err := verifier.Verify(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+}
+
+func Test_TokenVerifier_Verify_AcceptsTokensOfKeySetKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	key := newRSAKey(t)
+	other := newRSAKey(t)
+	keySet := map[string]any{"keys": []map[string]any{
+		{"kty": "EC", "kid": "unsupported", "crv": "P-256"},
+		{"kty": "RSA", "kid": "encryption", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{
+			"kty": "RSA", "kid": "other", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(other.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(other.E)).Bytes()),
+		},
+		{
+			"kty": "RSA", "kid": "current", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+		{"kty": "oct", "kid": "shared", "k": base64.RawURLEncoding.EncodeToString([]byte(secret))},
+	}}
+	encoded, err := json.Marshal(keySet)
+	require.NoError(t, err)
+
+	verifier, err := NewTokenVerifier(JWTConfig{JWKSFile: writeFile(t, "jwks.json", encoded)})
+	require.NoError(t, err)
+
+	// Act
+	_, rsaErr := verifier.Verify(signToken(t, map[string]any{"alg": "RS256", "kid": "current"}, validClaims(), key))
+	_, hmacErr := verifier.Verify(signToken(t, map[string]any{"alg": "HS256", "kid": "shared"}, validClaims(), secret))
+	_, otherKeyErr := verifier.Verify(signToken(t, map[string]any{"alg": "RS256", "kid": "other"}, validClaims(), key))
+
+	// Assert
+	assert.NoError(t, rsaErr)
+	assert.NoError(t, hmacErr)
+	assert.ErrorIs(t, otherKeyErr, ErrInvalidSignature)
+}
+
+func Test_TokenVerifier_Verify_RejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	key := newRSAKey(t)
+	encoded := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+	verifier, err := NewTokenVerifier(JWTConfig{
+		HMACSecrets:    []string{secret},
+		PublicKeyFiles: []string{writeFile(t, "key.pem", encoded)},
+		Issuer:         "https://issuer.example.com",
+		Audience:       "media-tags",
+	})
+	require.NoError(t, err)
+
+	withClaims := func(changes map[string]any) map[string]any {
+		claims := validClaims()
+		claims["iss"] = "https://issuer.example.com"
+		claims["aud"] = []string{"other", "media-tags"}
+		for claim, value := range changes {
+			if value == nil {
+				delete(claims, claim)
+			} else {
+				claims[claim] = value
+			}
+		}
+		return claims
+	}
+	hs256 := map[string]any{"alg": "HS256"}
+
+	tests := map[string]struct {
+		token         string
+		expectedError error
+	}{
+		"malformed": {
+			token:         "not.a-token",
+			expectedError: ErrMalformedToken,
+		},
+		"unsigned": {
+			token:         signToken(t, map[string]any{"alg": "none"}, withClaims(nil), nil),
+			expectedError: ErrUnknownKey,
+		},
+		"public key used as HMAC secret": {
+			token:         signToken(t, hs256, withClaims(nil), string(encoded)),
+			expectedError: ErrInvalidSignature,
+		},
+		"other secret": {
+			token:         signToken(t, hs256, withClaims(nil), strings.Repeat("o", MinSecretLength)),
+			expectedError: ErrInvalidSignature,
+		},
+		"other RSA key": {
+			token:         signToken(t, map[string]any{"alg": "RS256"}, withClaims(nil), newRSAKey(t)),
+			expectedError: ErrInvalidSignature,
+		},
+		"expired": {
+			token:         signToken(t, hs256, withClaims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}), secret),
+			expectedError: ErrExpiredToken,
+		},
+		"without expiry": {
+			token:         signToken(t, hs256, withClaims(map[string]any{"exp": nil}), secret),
+			expectedError: ErrInvalidClaims,
+		},
+		"not valid yet": {
+			token:         signToken(t, hs256, withClaims(map[string]any{"nbf": time.Now().Add(time.Hour).Unix()}), secret),
+			expectedError: ErrInvalidClaims,
+		},
+		"without subject": {
+			token:         signToken(t, hs256, withClaims(map[string]any{"sub": nil}), secret),
+			expectedError: ErrInvalidClaims,
+		},
+		"other issuer": {
+			token:         signToken(t, hs256, withClaims(map[string]any{"iss": "https://other.example.com"}), secret),
+			expectedError: ErrInvalidClaims,
+		},
+		"other audience": {
+			token:         signToken(t, hs256, withClaims(map[string]any{"aud": "other"}), secret),
+			expectedError: ErrInvalidClaims,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := verifier.Verify(test.token)
+
+			// Assert
+			assert.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
+
+func Test_NewTokenVerifier_RejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]JWTConfig{
+		"short secret":           {HMACSecrets: []string{"secret"}},
+		"missing public key":     {PublicKeyFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}},
+		"public key without PEM": {PublicKeyFiles: []string{writeFile(t, "key.pem", []byte("key"))}},
+		"invalid key set":        {JWKSFile: writeFile(t, "jwks.json", []byte("{"))},
+		"unknown default role":   {DefaultRole: "owner"},
+	}
+
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := NewTokenVerifier(config)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// readPublicKeyFile reads a PEM encoded RSA public key in PKIX or PKCS #1 form
+func readPublicKeyFile(path string) (*rsa.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading public key: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", path)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+		}
+		public, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key %s is not an RSA key", path)
+		}
+		return public, nil
+	case "RSA PUBLIC KEY":
+		public, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+		}
+		return public, nil
+	default:
+		return nil, fmt.Errorf("public key %s holds a %s instead of a public key", path, block.Type)
+	}
+}
+
+// jsonWebKey holds the members of RSA and symmetric JSON Web Keys
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// N and E are the modulus and exponent of an RSA key
+	N string `json:"n"`
+	E string `json:"e"`
+	// K is the secret of a symmetric key
+	K string `json:"k"`
+}
+
+// readKeySetFile reads the signing keys of a JSON Web Key Set, keys of other types or uses are skipped
+func readKeySetFile(path string) ([]*verificationKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading key set: %w", err)
+	}
+
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &keySet); err != nil {
+		return nil, fmt.Errorf("invalid key set %s: %w", path, err)
+	}
+
+	var keys []*verificationKey
+	for _, webKey := range keySet.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+
+		key, err := webKey.verificationKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in key set %s: %w", webKey.KeyID, path, err)
+		}
+		if key != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// verificationKey converts the web key, nil is returned for keys of unsupported types or algorithms
+func (webKey *jsonWebKey) verificationKey() (*verificationKey, error) {
+	switch {
+	case webKey.KeyType == "RSA" && (webKey.Algorithm == "" || webKey.Algorithm == AlgorithmRS256):
+		modulus, err := base64.RawURLEncoding.DecodeString(webKey.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(webKey.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		if len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+			return nil, errors.New("missing or invalid modulus or exponent")
+		}
+
+		public := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+		return &verificationKey{id: webKey.KeyID, algorithm: AlgorithmRS256, public: public}, nil
+	case webKey.KeyType == "oct" && (webKey.Algorithm == "" || webKey.Algorithm == AlgorithmHS256):
+		secret, err := base64.RawURLEncoding.DecodeString(webKey.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret: %w", err)
+		}
+		if len(secret) < MinSecretLength {
+			return nil, fmt.Errorf("secrets need at least %d bytes", MinSecretLength)
+		}
+		return &verificationKey{id: webKey.KeyID, algorithm: AlgorithmHS256, secret: secret}, nil
+	default:
+		return nil, nil
+	}
+}
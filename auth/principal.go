@@ -0,0 +1,60 @@
+// Package auth authenticates the callers of the API using API keys or JSON Web Tokens
+package auth
+
+import "context"
+
+// Method is the way a principal authenticated
+type Method string
+
+const (
+	MethodAPIKey Method = "apiKey"
+	MethodJWT    Method = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller and is what records are owned by, see APIKeySubject and TokenSubject
+	Subject string
+	// Name describes the caller in logs, the name of an API key or the name claim of a token
+	Name   string
+	Method Method
+	// Role limits the operations the caller can perform
+	Role Role
+}
+
+// APIKeySubject is the subject of requests made with the API key with the ID, formatted as apikey:<id>
+func APIKeySubject(id string) string {
+	return "apikey:" + id
+}
+
+// TokenSubject is the subject of requests made with a token, formatted as jwt:<iss>:<sub>. The sub claim is only
+// unique per issuer, and without the prefixes a token could claim the ID of an API key as its subject
+func TokenSubject(issuer string, subject string) string {
+	return "jwt:" + issuer + ":" + subject
+}
+
+// Owns reports whether the principal may change a record owned by the owner, admins may change every record
+func (principal *Principal) Owns(owner string) bool {
+	return principal.Role == RoleAdmin || principal.Subject == owner
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// SubjectFrom returns the subject of the principal of the context, empty if the context wasn't authenticated
+func SubjectFrom(ctx context.Context) string {
+	if principal, ok := PrincipalFrom(ctx); ok {
+		return principal.Subject
+	}
+	return ""
+}
+
+// PrincipalFrom returns the principal of the context, false if the context wasn't authenticated
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Role grants a principal the operations of its own role and of every role below it
+type Role string
+
+const (
+	// RoleViewer can read tags and media
+	RoleViewer Role = "viewer"
+	// RoleUploader can also upload media and create tags, and change or delete what it created
+	RoleUploader Role = "uploader"
+	// RoleAdmin can also change and delete the tags and media of others
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists every role from the least to the most privileged
+var Roles = []Role{RoleViewer, RoleUploader, RoleAdmin}
+
+// ParseRole validates the name of a role
+func ParseRole(value string) (Role, error) {
+	role := Role(value)
+	if !slices.Contains(Roles, role) {
+		return "", fmt.Errorf("expected one of %v, got %q", Roles, value)
+	}
+	return role, nil
+}
+
+// Includes reports whether the role grants everything the other role grants
+func (role Role) Includes(other Role) bool {
+	rank, otherRank := slices.Index(Roles, role), slices.Index(Roles, other)
+	return rank >= 0 && otherRank >= 0 && rank >= otherRank
+}
+
+// highestRole returns the most privileged of the known roles, false if none of them is known
+func highestRole(values []string) (Role, bool) {
+	highest, found := RoleViewer, false
+	for _, value := range values {
+		role, err := ParseRole(value)
+		if err != nil {
+			continue
+		}
+		if !found || role.Includes(highest) {
+			highest, found = role, true
+		}
+	}
+	return highest, found
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Role_Includes_GrantsLowerRoles(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		role     Role
+		other    Role
+		expected bool
+	}{
+		"same role":    {role: RoleUploader, other: RoleUploader, expected: true},
+		"lower role":   {role: RoleAdmin, other: RoleViewer, expected: true},
+		"higher role":  {role: RoleViewer, other: RoleUploader, expected: false},
+		"unknown role": {role: Role("owner"), other: RoleViewer, expected: false},
+		"no role":      {role: Role(""), other: RoleViewer, expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result := test.role.Includes(test.other)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/metadata"
+	"github.com/TheSandyDave/Media-Tags/storage"
+)
+
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Storage    storage.Config   `yaml:"storage"`
+	Files      FilesConfig      `yaml:"files"`
+	Uploads    UploadsConfig    `yaml:"uploads"`
+	Thumbnails ThumbnailsConfig `yaml:"thumbnails"`
+	Render     RenderConfig     `yaml:"render"`
+	Privacy    PrivacyConfig    `yaml:"privacy"`
+	Log        LogConfig        `yaml:"log"`
+}
+
+type ServerConfig struct {
+	// Address the API listens on, in host:port form
+	Address           string        `yaml:"address"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	// ShutdownTimeout is how long in-flight requests get to finish once a shutdown signal is received
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// PublicBaseURL is the URL clients reach the API at, the URLs of stored files start with it. A path such as /
+	// makes them relative, empty derives the URL from every request
+	PublicBaseURL string `yaml:"publicBaseURL"`
+	// TrustedProxies are the IP addresses and CIDR ranges of reverse proxies, their X-Forwarded-* headers are used
+	// to derive the URL of a request and the address of the client
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+// TrustedProxyPrefixes parses the trusted proxies, a single address is a range of one address
+func (server *ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(server.TrustedProxies))
+	for _, proxy := range server.TrustedProxies {
+		if address, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(address, address.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("expected an IP address or CIDR range, got %q", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+type DatabaseConfig struct {
+	// DSN of the SQLite database, either a file path or ":memory:"
+	DSN string `yaml:"dsn"`
+}
+
+// AuthConfig configures how clients of the API authenticate, with API keys created through the create-api-key command
+// or with JSON Web Tokens issued by an identity provider
+type AuthConfig struct {
+	// Enabled requires credentials for every API endpoint, disabling it leaves the API open to anyone who can reach it
+	Enabled bool           `yaml:"enabled"`
+	JWT     auth.JWTConfig `yaml:"jwt"`
+}
+
+// FilesConfig configures the signed URLs stored files are downloaded through
+type FilesConfig struct {
+	// SigningKeys are the secrets file URLs are signed with. The first key signs new URLs, the others are still
+	// accepted so URLs signed before a key rotation keep working until they expire. Without keys a random key is
+	// generated on startup, which invalidates every URL handed out before a restart
+	SigningKeys []string `yaml:"signingKeys"`
+	// URLExpiration is how long a signed file URL can be used
+	URLExpiration time.Duration `yaml:"urlExpiration"`
+}
+
+type UploadsConfig struct {
+	// MaxSize is the maximum size in bytes of a single uploaded file of any kind
+	MaxSize int64 `yaml:"maxSize"`
+	// MaxRequestSize is the maximum size in bytes of an upload request including its form fields, requests announcing
+	// a larger body are rejected before it is read
+	MaxRequestSize int64 `yaml:"maxRequestSize"`
+	// Duplicates decides what happens to an upload with the same content as existing media, link stores it sharing
+	// the file of the existing media and reject fails the upload
+	Duplicates string          `yaml:"duplicates"`
+	Resumable  ResumableConfig `yaml:"resumable"`
+	Image      KindConfig      `yaml:"image"`
+	Video      KindConfig      `yaml:"video"`
+	Audio      KindConfig      `yaml:"audio"`
+	Document   KindConfig      `yaml:"document"`
+}
+
+// ResumableConfig configures resumable uploads using the tus protocol
+type ResumableConfig struct {
+	// Expiration is how long an unfinished upload can be resumed after the last chunk was received
+	Expiration time.Duration `yaml:"expiration"`
+	// CleanupInterval is how often expired uploads are removed along with the chunks they received
+	CleanupInterval time.Duration `yaml:"cleanupInterval"`
+}
+
+// KindConfig restricts the uploads of a single media kind, a kind without allowed types can't be uploaded
+type KindConfig struct {
+	// MaxSize is the maximum size in bytes of an uploaded file of the kind, uploads.maxSize still applies when it is lower
+	MaxSize int64 `yaml:"maxSize"`
+	// AllowedTypes are the MIME types detected from the content of a file that are accepted, type/* allows all subtypes
+	AllowedTypes []string `yaml:"allowedTypes"`
+	// AllowedExtensions are the accepted file name extensions, including the dot
+	AllowedExtensions []string `yaml:"allowedExtensions"`
+}
+
+// Kinds returns the configuration of every media kind
+func (uploads *UploadsConfig) Kinds() map[domain.MediaKind]KindConfig {
+	return map[domain.MediaKind]KindConfig{
+		domain.MediaKindImage:    uploads.Image,
+		domain.MediaKindVideo:    uploads.Video,
+		domain.MediaKindAudio:    uploads.Audio,
+		domain.MediaKindDocument: uploads.Document,
+	}
+}
+
+type ThumbnailsConfig struct {
+	// Sizes are the maximum width and height in pixels of the thumbnails generated for every image, empty disables them
+	Sizes []int `yaml:"sizes"`
+	// Quality of the JPEG encoded thumbnails, from 1 to 100
+	Quality int `yaml:"quality"`
+	// MaxSourcePixels is the largest width times height of an image thumbnails are generated for
+	MaxSourcePixels int64 `yaml:"maxSourcePixels"`
+	// WebP enables generating thumbnails for WebP images
+	WebP bool `yaml:"webp"`
+}
+
+type RenderConfig struct {
+	// MaxDimension is the largest width or height in pixels an image can be rendered at
+	MaxDimension int `yaml:"maxDimension"`
+	// CacheDirectory keeps rendered images so they are only rendered once
+	CacheDirectory string `yaml:"cacheDirectory"`
+	// CacheSize is the number of bytes of rendered images kept, the least recently used ones are evicted first
+	CacheSize int64 `yaml:"cacheSize"`
+}
+
+type PrivacyConfig struct {
+	// Strip lists the metadata blocks removed from every stored image: location, serialNumbers or all.
+	// Uploads can strip more blocks but never less
+	Strip []string `yaml:"strip"`
+	// RecordStripped keeps the extracted fields that are stripped from the stored file in the database only, the API
+	// neither returns them nor matches them in filters
+	RecordStripped bool `yaml:"recordStripped"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+// Default returns the configuration used for every value that is not configured explicitly
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:           "localhost:8080",
+			ReadHeaderTimeout: 20 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			DSN: "db",
+		},
+		Storage: storage.Config{
+			Type: storage.TypeLocal,
+			Local: storage.LocalConfig{
+				Directory: "static",
+			},
+		},
+		Auth: AuthConfig{
+			Enabled: true,
+			JWT: auth.JWTConfig{
+				DefaultRole: string(auth.RoleViewer),
+			},
+		},
+		Files: FilesConfig{
+			URLExpiration: time.Hour,
+		},
+		Uploads: UploadsConfig{
+			// large enough for videos, which is only safe since uploaded files are streamed to the storage
+			MaxSize:        512 << 20,
+			MaxRequestSize: 513 << 20,
+			Duplicates:     "link",
+			Resumable: ResumableConfig{
+				Expiration:      24 * time.Hour,
+				CleanupInterval: time.Hour,
+			},
+			Image: KindConfig{
+				MaxSize:           32 << 20,
+				AllowedTypes:      []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+				AllowedExtensions: []string{".jpg", ".jpeg", ".png", ".gif", ".webp"},
+			},
+			Video: KindConfig{
+				MaxSize:           512 << 20,
+				AllowedTypes:      []string{"video/mp4", "video/x-m4v", "video/webm", "video/quicktime"},
+				AllowedExtensions: []string{".mp4", ".m4v", ".webm", ".mov"},
+			},
+			Audio: KindConfig{
+				MaxSize:           64 << 20,
+				AllowedTypes:      []string{"audio/mpeg", "audio/ogg", "audio/wav", "audio/flac", "audio/mp4", "audio/x-m4a"},
+				AllowedExtensions: []string{".mp3", ".ogg", ".oga", ".wav", ".flac", ".m4a"},
+			},
+			Document: KindConfig{
+				MaxSize:           32 << 20,
+				AllowedTypes:      []string{"application/pdf", "text/plain"},
+				AllowedExtensions: []string{".pdf", ".txt"},
+			},
+		},
+		Thumbnails: ThumbnailsConfig{
+			Sizes:           []int{256, 1024},
+			Quality:         85,
+			MaxSourcePixels: 50_000_000,
+			WebP:            true,
+		},
+		Render: RenderConfig{
+			MaxDimension:   4096,
+			CacheDirectory: "cache/render",
+			CacheSize:      1 << 30,
+		},
+		Privacy: PrivacyConfig{
+			Strip:          []string{string(metadata.BlockLocation)},
+			RecordStripped: true,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+	}
+}
+
+// settings lists every configurable value, the key is used as the flag name, the path in the configuration file
+// and, upper cased and prefixed with MEDIA_TAGS_, as the environment variable
+func (config *Config) settings() []setting {
+	return []setting{
+		{"server.address", "address the API listens on", &config.Server.Address},
+		{"server.readHeaderTimeout", "maximum duration for reading request headers", &config.Server.ReadHeaderTimeout},
+		{"server.shutdownTimeout", "time given to in-flight requests on shutdown", &config.Server.ShutdownTimeout},
+		{"server.publicBaseURL", "URL clients reach the API at, empty derives it from every request", &config.Server.PublicBaseURL},
+		{"server.trustedProxies", "comma separated addresses and CIDR ranges of proxies whose X-Forwarded-* headers are trusted", &config.Server.TrustedProxies},
+
+		{"database.dsn", "SQLite database file", &config.Database.DSN},
+
+		{"auth.enabled", "require an API key or token for every API endpoint", &config.Auth.Enabled},
+		{"auth.jwt.hmacSecrets", "comma separated secrets verifying HS256 signed tokens", &config.Auth.JWT.HMACSecrets},
+		{"auth.jwt.publicKeyFiles", "comma separated PEM files of RSA public keys verifying RS256 signed tokens", &config.Auth.JWT.PublicKeyFiles},
+		{"auth.jwt.jwksFile", "JSON Web Key Set file with the keys verifying tokens", &config.Auth.JWT.JWKSFile},
+		{"auth.jwt.issuer", "required issuer of tokens, empty accepts any issuer", &config.Auth.JWT.Issuer},
+		{"auth.jwt.audience", "audience tokens have to be issued for, empty accepts any audience", &config.Auth.JWT.Audience},
+		{"auth.jwt.defaultRole", "role of tokens without a known role in their roles claim, viewer, uploader or admin", &config.Auth.JWT.DefaultRole},
+
+		{"storage.type", "file storage backend, local or s3", &config.Storage.Type},
+		{"storage.local.directory", "directory used by the local file storage", &config.Storage.Local.Directory},
+		{"storage.s3.endpoint", "host:port of the S3 compatible object storage", &config.Storage.S3.Endpoint},
+		{"storage.s3.region", "region of the S3 bucket", &config.Storage.S3.Region},
+		{"storage.s3.bucket", "S3 bucket to store files in", &config.Storage.S3.Bucket},
+		{"storage.s3.accessKeyId", "S3 access key ID", &config.Storage.S3.AccessKeyID},
+		{"storage.s3.secretAccessKey", "S3 secret access key", &config.Storage.S3.SecretAccessKey},
+		{"storage.s3.prefix", "prefix prepended to every S3 object key", &config.Storage.S3.Prefix},
+		{"storage.s3.useSSL", "connect to the object storage using HTTPS", &config.Storage.S3.UseSSL},
+
+		{"files.signingKeys", "comma separated secrets file URLs are signed with, the first one signs new URLs", &config.Files.SigningKeys},
+		{"files.urlExpiration", "how long a signed file URL can be used", &config.Files.URLExpiration},
+
+		{"uploads.maxSize", "maximum size in bytes of an uploaded file", &config.Uploads.MaxSize},
+		{"uploads.maxRequestSize", "maximum size in bytes of an upload request including its form fields", &config.Uploads.MaxRequestSize},
+		{"uploads.duplicates", "handling of uploads with the same content as existing media, link or reject", &config.Uploads.Duplicates},
+		{"uploads.resumable.expiration", "how long an unfinished resumable upload can be resumed", &config.Uploads.Resumable.Expiration},
+		{"uploads.resumable.cleanupInterval", "how often expired resumable uploads are removed", &config.Uploads.Resumable.CleanupInterval},
+		{"uploads.image.maxSize", "maximum size in bytes of an uploaded image", &config.Uploads.Image.MaxSize},
+		{"uploads.image.allowedTypes", "comma separated image MIME types accepted after content detection", &config.Uploads.Image.AllowedTypes},
+		{"uploads.image.allowedExtensions", "comma separated file extensions accepted for images", &config.Uploads.Image.AllowedExtensions},
+		{"uploads.video.maxSize", "maximum size in bytes of an uploaded video", &config.Uploads.Video.MaxSize},
+		{"uploads.video.allowedTypes", "comma separated video MIME types accepted after content detection", &config.Uploads.Video.AllowedTypes},
+		{"uploads.video.allowedExtensions", "comma separated file extensions accepted for videos", &config.Uploads.Video.AllowedExtensions},
+		{"uploads.audio.maxSize", "maximum size in bytes of an uploaded audio file", &config.Uploads.Audio.MaxSize},
+		{"uploads.audio.allowedTypes", "comma separated audio MIME types accepted after content detection", &config.Uploads.Audio.AllowedTypes},
+		{"uploads.audio.allowedExtensions", "comma separated file extensions accepted for audio", &config.Uploads.Audio.AllowedExtensions},
+		{"uploads.document.maxSize", "maximum size in bytes of an uploaded document", &config.Uploads.Document.MaxSize},
+		{"uploads.document.allowedTypes", "comma separated document MIME types accepted after content detection", &config.Uploads.Document.AllowedTypes},
+		{"uploads.document.allowedExtensions", "comma separated file extensions accepted for documents", &config.Uploads.Document.AllowedExtensions},
+
+		{"thumbnails.sizes", "comma separated maximum widths and heights of generated thumbnails", &config.Thumbnails.Sizes},
+		{"thumbnails.quality", "JPEG quality of generated thumbnails", &config.Thumbnails.Quality},
+		{"thumbnails.maxSourcePixels", "largest number of pixels of an image thumbnails are generated for", &config.Thumbnails.MaxSourcePixels},
+		{"thumbnails.webp", "generate thumbnails for WebP images", &config.Thumbnails.WebP},
+
+		{"render.maxDimension", "largest width or height images are rendered at", &config.Render.MaxDimension},
+		{"render.cacheDirectory", "directory caching rendered images", &config.Render.CacheDirectory},
+		{"render.cacheSize", "bytes of rendered images kept in the cache", &config.Render.CacheSize},
+
+		{"privacy.strip", "comma separated metadata blocks stripped from every stored image, location, serialNumbers or all", &config.Privacy.Strip},
+		{"privacy.recordStripped", "keep the metadata stripped from stored images in the database, without returning or filtering on it", &config.Privacy.RecordStripped},
+
+		{"log.level", "log level, one of trace, debug, info, warn, error", &config.Log.Level},
+	}
+}
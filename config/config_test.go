@@ -0,0 +1,310 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func environment(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func Test_Load_UsesDefaultsWithoutConfiguration(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	config, err := Load(nil, environment(nil))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, Default(), config)
+}
+
+func Test_Load_DefaultsMatchTheExampleConfiguration(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	config, err := Load([]string{"-config", "../docs/config.example.yaml"}, environment(nil))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, Default().Privacy, config.Privacy)
+	assert.Equal(t, []string{"location"}, config.Privacy.Strip, "location is stripped unless configured otherwise")
+}
+
+func Test_Load_AppliesSourcesInOrderOfPrecedence(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	path := writeConfigFile(t, `
+server:
+  address: "file:1"
+  shutdownTimeout: 5s
+database:
+  dsn: file.db
+log:
+  level: debug
+`)
+
+	tests := map[string]struct {
+		args            []string
+		env             map[string]string
+		expectedAddress string
+		expectedDSN     string
+	}{
+		"configuration file overrides defaults": {
+			args:            []string{"-config", path},
+			expectedAddress: "file:1",
+			expectedDSN:     "file.db",
+		},
+		"environment overrides configuration file": {
+			args: []string{"-config", path},
+			env: map[string]string{
+				"MEDIA_TAGS_SERVER_ADDRESS": "env:2",
+			},
+			expectedAddress: "env:2",
+			expectedDSN:     "file.db",
+		},
+		"flags override environment": {
+			args: []string{"-config", path, "-server.address", "flag:3"},
+			env: map[string]string{
+				"MEDIA_TAGS_SERVER_ADDRESS": "env:2",
+				"MEDIA_TAGS_DATABASE_DSN":   "env.db",
+			},
+			expectedAddress: "flag:3",
+			expectedDSN:     "env.db",
+		},
+		"configuration file can be passed through the environment": {
+			env: map[string]string{
+				"MEDIA_TAGS_CONFIG": path,
+			},
+			expectedAddress: "file:1",
+			expectedDSN:     "file.db",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			config, err := Load(testData.args, environment(testData.env))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedAddress, config.Server.Address)
+			assert.Equal(t, testData.expectedDSN, config.Database.DSN)
+			assert.Equal(t, 5*time.Second, config.Server.ShutdownTimeout)
+			assert.Equal(t, "debug", config.Log.Level)
+			assert.Equal(t, Default().Server.ReadHeaderTimeout, config.Server.ReadHeaderTimeout, "unset values should keep their default")
+		})
+	}
+}
+
+func Test_Load_ParsesTypedSettings(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	env := environment(map[string]string{
+		"MEDIA_TAGS_STORAGE_TYPE":                 "s3",
+		"MEDIA_TAGS_STORAGE_S3_ENDPOINT":          "localhost:9000",
+		"MEDIA_TAGS_STORAGE_S3_BUCKET":            "media",
+		"MEDIA_TAGS_STORAGE_S3_ACCESS_KEY_ID":     "access",
+		"MEDIA_TAGS_STORAGE_S3_USE_SSL":           "true",
+		"MEDIA_TAGS_SERVER_READ_HEADER_TIMEOUT":   "3s",
+		"MEDIA_TAGS_UPLOADS_MAX_SIZE":             "1024",
+		"MEDIA_TAGS_UPLOADS_IMAGE_MAX_SIZE":       "512",
+		"MEDIA_TAGS_THUMBNAILS_SIZES":             "64, 128",
+		"MEDIA_TAGS_STORAGE_S3_SECRET_ACCESS_KEY": "secret",
+	})
+
+	// Act
+	config, err := Load(nil, env)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "s3", config.Storage.Type)
+	assert.Equal(t, "localhost:9000", config.Storage.S3.Endpoint)
+	assert.Equal(t, "media", config.Storage.S3.Bucket)
+	assert.Equal(t, "access", config.Storage.S3.AccessKeyID)
+	assert.Equal(t, "secret", config.Storage.S3.SecretAccessKey)
+	assert.True(t, config.Storage.S3.UseSSL)
+	assert.Equal(t, 3*time.Second, config.Server.ReadHeaderTimeout)
+	assert.Equal(t, int64(1024), config.Uploads.MaxSize)
+	assert.Equal(t, int64(512), config.Uploads.Image.MaxSize)
+	assert.Equal(t, []int{64, 128}, config.Thumbnails.Sizes)
+}
+
+func Test_Load_ParsesBooleanFlags(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	config, err := Load([]string{"-thumbnails.webp=false", "-storage.s3.useSSL", "-auth.enabled=false"}, environment(nil))
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, config.Thumbnails.WebP)
+	assert.True(t, config.Storage.S3.UseSSL)
+	assert.False(t, config.Auth.Enabled)
+}
+
+func Test_Load_FailsOnInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args          []string
+		env           map[string]string
+		expectedError string
+	}{
+		"unparsable environment variable": {
+			env:           map[string]string{"MEDIA_TAGS_SERVER_SHUTDOWN_TIMEOUT": "soon"},
+			expectedError: `environment variable MEDIA_TAGS_SERVER_SHUTDOWN_TIMEOUT: invalid duration "soon"`,
+		},
+		"unparsable flag": {
+			args:          []string{"-uploads.maxSize", "big"},
+			expectedError: `flag -uploads.maxSize: invalid integer "big"`,
+		},
+		"invalid address": {
+			args:          []string{"-server.address", "localhost"},
+			expectedError: `server.address: expected host:port, got "localhost"`,
+		},
+		"invalid public URL settings": {
+			args:          []string{"-server.publicBaseURL", "media.example.com", "-server.trustedProxies", "10.0.0.0/8,proxy"},
+			expectedError: "server.publicBaseURL: expected an http(s) URL or a path starting with /, got \"media.example.com\"\nserver.trustedProxies: expected an IP address or CIDR range, got \"proxy\"",
+		},
+		"unknown storage type": {
+			args:          []string{"-storage.type", "ftp"},
+			expectedError: `storage.type: expected "local" or "s3", got "ftp"`,
+		},
+		"missing s3 settings": {
+			args:          []string{"-storage.type", "s3"},
+			expectedError: "storage.s3.endpoint: is required for s3 storage\nstorage.s3.bucket: is required for s3 storage",
+		},
+		"invalid upload allowlist": {
+			args:          []string{"-uploads.image.allowedTypes", "image", "-uploads.image.allowedExtensions", "png"},
+			expectedError: "uploads.image.allowedTypes: expected type/subtype or type/*, got \"image\"\nuploads.image.allowedExtensions: expected an extension starting with a dot, got \"png\"",
+		},
+		"type of another kind": {
+			args:          []string{"-uploads.video.allowedTypes", "video/mp4,audio/mpeg"},
+			expectedError: `uploads.video.allowedTypes: "audio/mpeg" is not a video type`,
+		},
+		"invalid token settings": {
+			args:          []string{"-auth.jwt.hmacSecrets", "secret", "-auth.jwt.defaultRole", "owner"},
+			expectedError: "auth.jwt.hmacSecrets: secrets need at least 32 characters\nauth.jwt.defaultRole: expected one of [viewer uploader admin], got \"owner\"",
+		},
+		"invalid file signing": {
+			args:          []string{"-files.signingKeys", "secret", "-files.urlExpiration", "0s"},
+			expectedError: "files.signingKeys: keys need at least 32 characters\nfiles.urlExpiration: must be positive",
+		},
+		"request size below file size": {
+			args:          []string{"-uploads.maxRequestSize", "1024"},
+			expectedError: "uploads.maxRequestSize: must be at least uploads.maxSize",
+		},
+		"invalid resumable expiration": {
+			args:          []string{"-uploads.resumable.expiration", "0s"},
+			expectedError: "uploads.resumable.expiration: must be positive",
+		},
+		"invalid kind size": {
+			args:          []string{"-uploads.audio.maxSize", "0"},
+			expectedError: "uploads.audio.maxSize: must be positive",
+		},
+		"every kind disabled": {
+			args: []string{
+				"-uploads.image.allowedTypes", "", "-uploads.video.allowedTypes", "",
+				"-uploads.audio.allowedTypes", "", "-uploads.document.allowedTypes", "",
+			},
+			expectedError: "uploads: at least one media kind needs allowed types",
+		},
+		"invalid thumbnail settings": {
+			args:          []string{"-thumbnails.sizes", "256,0", "-thumbnails.quality", "101"},
+			expectedError: "thumbnails.sizes: expected sizes between 1 and 4096, got 0\nthumbnails.quality: expected a quality between 1 and 100",
+		},
+		"unparsable list of integers": {
+			args:          []string{"-thumbnails.sizes", "256,large"},
+			expectedError: `flag -thumbnails.sizes: invalid integer "large"`,
+		},
+		"unknown duplicate handling": {
+			args:          []string{"-uploads.duplicates", "ignore"},
+			expectedError: `uploads.duplicates: expected link or reject, got "ignore"`,
+		},
+		"unknown privacy block": {
+			args:          []string{"-privacy.strip", "location,faces"},
+			expectedError: `privacy.strip: unknown metadata block "faces", expected one of [location serialNumbers all]`,
+		},
+		"invalid render settings": {
+			args:          []string{"-render.maxDimension", "20000", "-render.cacheDirectory", "", "-render.cacheSize", "0"},
+			expectedError: "render.maxDimension: expected a dimension between 1 and 16384\nrender.cacheDirectory: is required\nrender.cacheSize: must be positive",
+		},
+		"unknown log level": {
+			args:          []string{"-log.level", "loud"},
+			expectedError: `log.level: unknown level "loud"`,
+		},
+		"unknown flag": {
+			args:          []string{"-port", "80"},
+			expectedError: "flag provided but not defined: -port",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := Load(testData.args, environment(testData.env))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+		})
+	}
+}
+
+func Test_Load_FailsOnUnknownConfigurationFileKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	path := writeConfigFile(t, `
+server:
+  adress: "localhost:80"
+`)
+
+	// Act
+	_, err := Load([]string{"-config", path}, environment(nil))
+
+	// Assert
+	assert.ErrorContains(t, err, "field adress not found")
+}
+
+func Test_Load_ReturnsErrHelpWhenHelpIsRequested(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := Load([]string{"-help"}, environment(nil))
+
+	// Assert
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
+
+func Test_environmentVariable_ConvertsKeys(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "MEDIA_TAGS_SERVER_READ_HEADER_TIMEOUT", environmentVariable("server.readHeaderTimeout"))
+	assert.Equal(t, "MEDIA_TAGS_STORAGE_S3_USE_SSL", environmentVariable("storage.s3.useSSL"))
+	assert.Equal(t, "MEDIA_TAGS_STORAGE_S3_ACCESS_KEY_ID", environmentVariable("storage.s3.accessKeyId"))
+}
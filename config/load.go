@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	environmentPrefix = "MEDIA_TAGS_"
+	configFileFlag    = "config"
+)
+
+type setting struct {
+	key         string
+	description string
+	// target is a pointer to the field of the Config the setting is stored in
+	target any
+}
+
+// Load resolves the configuration, in order of increasing precedence, from the defaults, the optional YAML
+// configuration file, environment variables and command line flags. the configuration file is passed using
+// the -config flag or the MEDIA_TAGS_CONFIG environment variable
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("media-tags", flag.ContinueOnError)
+	configFile := flags.String(configFileFlag, "", "path to a YAML configuration file (env "+environmentVariable(configFileFlag)+")")
+	for _, setting := range settings {
+		usage := fmt.Sprintf("%s (env %s, default %s)", setting.description, environmentVariable(setting.key), format(setting.target))
+		// boolean settings are real boolean flags, so -storage.s3.useSSL enables them without a value
+		if _, ok := setting.target.(*bool); ok {
+			flags.Bool(setting.key, false, usage)
+			continue
+		}
+		flags.String(setting.key, "", usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile == "" {
+		*configFile, _ = lookupEnv(environmentVariable(configFileFlag))
+	}
+	if *configFile != "" {
+		if err := config.readFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, setting := range settings {
+		if value, ok := lookupEnv(environmentVariable(setting.key)); ok {
+			if err := parse(value, setting.target); err != nil {
+				errs = append(errs, fmt.Errorf("environment variable %s: %w", environmentVariable(setting.key), err))
+			}
+		}
+	}
+
+	flags.Visit(func(flag *flag.Flag) {
+		for _, setting := range settings {
+			if setting.key == flag.Name {
+				if err := parse(flag.Value.String(), setting.target); err != nil {
+					errs = append(errs, fmt.Errorf("flag -%s: %w", flag.Name, err))
+				}
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (config *Config) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed reading configuration file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// reject misspelled keys rather than silently ignoring them
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// environmentVariable converts a setting key such as "server.readHeaderTimeout" to MEDIA_TAGS_SERVER_READ_HEADER_TIMEOUT
+func environmentVariable(key string) string {
+	var builder strings.Builder
+	builder.WriteString(environmentPrefix)
+
+	previous := rune(0)
+	for _, char := range key {
+		switch {
+		case char == '.':
+			builder.WriteRune('_')
+		case unicode.IsUpper(char) && unicode.IsLower(previous):
+			builder.WriteRune('_')
+			builder.WriteRune(char)
+		default:
+			builder.WriteRune(unicode.ToUpper(char))
+		}
+		previous = char
+	}
+
+	return builder.String()
+}
+
+func parse(value string, target any) error {
+	switch target := target.(type) {
+	case *string:
+		*target = value
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*target = parsed
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*target = parsed
+	case *int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*target = parsed
+	case *time.Duration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*target = parsed
+	case *[]string:
+		*target = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*target = append(*target, item)
+			}
+		}
+	case *[]int:
+		*target = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			parsed, err := strconv.Atoi(item)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", item)
+			}
+			*target = append(*target, parsed)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported setting type %T", target))
+	}
+
+	return nil
+}
+
+func format(target any) string {
+	switch target := target.(type) {
+	case *string:
+		return strconv.Quote(*target)
+	case *[]string:
+		return strconv.Quote(strings.Join(*target, ","))
+	case *[]int:
+		items := make([]string, len(*target))
+		for i, item := range *target {
+			items[i] = strconv.Itoa(item)
+		}
+		return strconv.Quote(strings.Join(items, ","))
+	case *bool:
+		return strconv.FormatBool(*target)
+	case *int:
+		return strconv.Itoa(*target)
+	case *int64:
+		return strconv.FormatInt(*target, 10)
+	case *time.Duration:
+		return target.String()
+	default:
+		return fmt.Sprint(target)
+	}
+}
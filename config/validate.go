@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/metadata"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxThumbnailSize keeps thumbnails from becoming larger than a reasonable preview
+	maxThumbnailSize = 4096
+	// maxRenderDimension bounds renditions, a 16384x16384 image already takes 1 GiB of memory to render
+	maxRenderDimension = 16384
+)
+
+// Validate reports every invalid value of the configuration at once
+func (config *Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if _, _, err := net.SplitHostPort(config.Server.Address); err != nil {
+		invalid("server.address", "expected host:port, got %q", config.Server.Address)
+	}
+	if config.Server.ReadHeaderTimeout <= 0 {
+		invalid("server.readHeaderTimeout", "must be positive")
+	}
+	if config.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdownTimeout", "must be positive")
+	}
+	if config.Server.PublicBaseURL != "" {
+		baseURL, err := url.Parse(config.Server.PublicBaseURL)
+		absolute := err == nil && (baseURL.Scheme == "http" || baseURL.Scheme == "https") && baseURL.Host != ""
+		relative := err == nil && baseURL.Scheme == "" && baseURL.Host == "" && strings.HasPrefix(baseURL.Path, "/")
+		if (!absolute && !relative) || baseURL.RawQuery != "" || baseURL.Fragment != "" {
+			invalid("server.publicBaseURL", "expected an http(s) URL or a path starting with /, got %q", config.Server.PublicBaseURL)
+		}
+	}
+	if _, err := config.Server.TrustedProxyPrefixes(); err != nil {
+		invalid("server.trustedProxies", "%s", err)
+	}
+
+	if config.Database.DSN == "" {
+		invalid("database.dsn", "is required")
+	}
+
+	for _, secret := range config.Auth.JWT.HMACSecrets {
+		if len(secret) < auth.MinSecretLength {
+			invalid("auth.jwt.hmacSecrets", "secrets need at least %d characters", auth.MinSecretLength)
+			break
+		}
+	}
+
+	if _, err := auth.ParseRole(config.Auth.JWT.DefaultRole); err != nil {
+		invalid("auth.jwt.defaultRole", "%s", err)
+	}
+
+	switch config.Storage.Type {
+	case storage.TypeLocal:
+		if config.Storage.Local.Directory == "" {
+			invalid("storage.local.directory", "is required for local storage")
+		}
+	case storage.TypeS3:
+		if config.Storage.S3.Endpoint == "" {
+			invalid("storage.s3.endpoint", "is required for s3 storage")
+		}
+		if config.Storage.S3.Bucket == "" {
+			invalid("storage.s3.bucket", "is required for s3 storage")
+		}
+	default:
+		invalid("storage.type", "expected %q or %q, got %q", storage.TypeLocal, storage.TypeS3, config.Storage.Type)
+	}
+
+	for _, key := range config.Files.SigningKeys {
+		if len(key) < urlsign.MinKeyLength {
+			invalid("files.signingKeys", "keys need at least %d characters", urlsign.MinKeyLength)
+			break
+		}
+	}
+	if config.Files.URLExpiration <= 0 {
+		invalid("files.urlExpiration", "must be positive")
+	}
+
+	if config.Uploads.MaxSize <= 0 {
+		invalid("uploads.maxSize", "must be positive")
+	}
+	if config.Uploads.MaxRequestSize < config.Uploads.MaxSize {
+		invalid("uploads.maxRequestSize", "must be at least uploads.maxSize")
+	}
+	if config.Uploads.Duplicates != "link" && config.Uploads.Duplicates != "reject" {
+		invalid("uploads.duplicates", "expected link or reject, got %q", config.Uploads.Duplicates)
+	}
+	if config.Uploads.Resumable.Expiration <= 0 {
+		invalid("uploads.resumable.expiration", "must be positive")
+	}
+	if config.Uploads.Resumable.CleanupInterval <= 0 {
+		invalid("uploads.resumable.cleanupInterval", "must be positive")
+	}
+	enabled := false
+	for _, kind := range domain.MediaKinds {
+		kindConfig := config.Uploads.Kinds()[kind]
+		key := fmt.Sprintf("uploads.%s", kind)
+
+		if len(kindConfig.AllowedTypes) == 0 {
+			// the kind can't be uploaded, its other settings don't matter
+			continue
+		}
+		enabled = true
+
+		if kindConfig.MaxSize <= 0 {
+			invalid(key+".maxSize", "must be positive")
+		}
+		for _, allowedType := range kindConfig.AllowedTypes {
+			if mediaType, subtype, found := strings.Cut(allowedType, "/"); !found || mediaType == "" || subtype == "" {
+				invalid(key+".allowedTypes", "expected type/subtype or type/*, got %q", allowedType)
+			} else if typeKind, _ := domain.MediaKindOf(allowedType); typeKind != kind {
+				invalid(key+".allowedTypes", "%q is not a %s type", allowedType, kind)
+			}
+		}
+		if len(kindConfig.AllowedExtensions) == 0 {
+			invalid(key+".allowedExtensions", "at least one extension is required")
+		}
+		for _, extension := range kindConfig.AllowedExtensions {
+			if len(extension) < 2 || !strings.HasPrefix(extension, ".") {
+				invalid(key+".allowedExtensions", "expected an extension starting with a dot, got %q", extension)
+			}
+		}
+	}
+	if !enabled {
+		invalid("uploads", "at least one media kind needs allowed types")
+	}
+
+	for _, size := range config.Thumbnails.Sizes {
+		if size <= 0 || size > maxThumbnailSize {
+			invalid("thumbnails.sizes", "expected sizes between 1 and %d, got %d", maxThumbnailSize, size)
+		}
+	}
+	if config.Thumbnails.Quality < 1 || config.Thumbnails.Quality > 100 {
+		invalid("thumbnails.quality", "expected a quality between 1 and 100")
+	}
+	if config.Thumbnails.MaxSourcePixels <= 0 {
+		invalid("thumbnails.maxSourcePixels", "must be positive")
+	}
+
+	if config.Render.MaxDimension <= 0 || config.Render.MaxDimension > maxRenderDimension {
+		invalid("render.maxDimension", "expected a dimension between 1 and %d", maxRenderDimension)
+	}
+	if config.Render.CacheDirectory == "" {
+		invalid("render.cacheDirectory", "is required")
+	}
+	if config.Render.CacheSize <= 0 {
+		invalid("render.cacheSize", "must be positive")
+	}
+
+	if _, err := metadata.ParsePolicy(config.Privacy.Strip); err != nil {
+		invalid("privacy.strip", "%s", err)
+	}
+
+	if _, err := logrus.ParseLevel(config.Log.Level); err != nil {
+		invalid("log.level", "unknown level %q", config.Log.Level)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
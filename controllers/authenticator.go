@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// Authenticator only lets requests with a valid API key or token through, the principal they authenticate as is
+// attached to the context of the request
+type Authenticator struct {
+	APIKeyService services.IAPIKeyService
+	// Tokens verifies JSON Web Tokens, nil rejects every token
+	Tokens *auth.TokenVerifier
+}
+
+// Require wraps the handler so it only runs for authenticated requests of principals with at least the role
+func (authenticator *Authenticator) Require(role auth.Role, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.authenticate(c)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="media-tags"`)
+			_ = c.Error(err)
+			return
+		}
+		if !principal.Role.Includes(role) {
+			_ = c.Error(apierrors.NewForbiddenError(fmt.Sprintf("the %s role is required", role)))
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		next(c)
+	}
+}
+
+// RequireUnlessSigned wraps the handler like Require, requests without credentials carrying a URL signature are let
+// through without a principal for the handler to verify the signature
+func (authenticator *Authenticator) RequireUnlessSigned(role auth.Role, next gin.HandlerFunc) gin.HandlerFunc {
+	authenticated := authenticator.Require(role, next)
+	return func(c *gin.Context) {
+		if c.GetHeader(apiKeyHeader) == "" && c.GetHeader("Authorization") == "" && c.Query(urlsign.SignatureParameter) != "" {
+			next(c)
+			return
+		}
+		authenticated(c)
+	}
+}
+
+// authenticate resolves the principal of the credentials sent with the request
+func (authenticator *Authenticator) authenticate(c *gin.Context) (*auth.Principal, error) {
+	logger := utils.NewLogger(c.Request.Context())
+
+	credential := c.GetHeader(apiKeyHeader)
+	if credential == "" {
+		scheme, value, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(value) == "" {
+			return nil, apierrors.NewUnauthorizedError("expected an API key or a bearer token")
+		}
+		credential = strings.TrimSpace(value)
+	}
+
+	if auth.IsAPIKey(credential) {
+		keys, err := authenticator.APIKeyService.Get(c.Request.Context(), authenticator.APIKeyService.FilterByHashOption(auth.HashAPIKey(credential)))
+		if err != nil {
+			logger.WithError(err).Error("failed looking up API key")
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, apierrors.NewUnauthorizedError("invalid API key")
+		}
+
+		return &auth.Principal{Subject: auth.APIKeySubject(keys[0].ID.String()), Name: keys[0].Name, Method: auth.MethodAPIKey, Role: auth.Role(keys[0].Role)}, nil
+	}
+
+	if authenticator.Tokens == nil {
+		return nil, apierrors.NewUnauthorizedError("tokens are not accepted")
+	}
+	claims, err := authenticator.Tokens.Verify(credential)
+	if err != nil {
+		logger.WithError(err).Debug("rejected token")
+		return nil, apierrors.NewUnauthorizedError(err.Error())
+	}
+
+	return &auth.Principal{Subject: auth.TokenSubject(claims.Issuer, claims.Subject), Name: claims.Name, Method: auth.MethodJWT, Role: claims.Role}, nil
+}
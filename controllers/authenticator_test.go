@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	gocontext "context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	mock_services "github.com/TheSandyDave/Media-Tags/generated/mock/services"
+	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var tokenSecret = strings.Repeat("t", auth.MinSecretLength)
+
+// newHS256Token signs the claims with the token secret
+func newHS256Token(claims string) string {
+	input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(tokenSecret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newAuthenticator creates an authenticator knowing a single API key, the key is returned along with its record
+func newAuthenticator(t *testing.T) (*Authenticator, string, *domain.APIKey) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	apiKeyService := mock_services.NewMockIAPIKeyService(ctrl)
+
+	key, hash, err := auth.NewAPIKey()
+	require.NoError(t, err)
+	stored := &domain.APIKey{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "importer", Hash: hash, Role: "uploader"}
+
+	var requestedHash string
+	apiKeyService.EXPECT().FilterByHashOption(gomock.Any()).DoAndReturn(func(hash string) services.Option[domain.APIKey] {
+		requestedHash = hash
+		return nil
+	}).AnyTimes()
+	apiKeyService.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(gocontext.Context, ...services.Option[domain.APIKey]) ([]*domain.APIKey, error) {
+		if requestedHash == hash {
+			return []*domain.APIKey{stored}, nil
+		}
+		return nil, nil
+	}).AnyTimes()
+
+	tokens, err := auth.NewTokenVerifier(auth.JWTConfig{HMACSecrets: []string{tokenSecret}})
+	require.NoError(t, err)
+
+	return &Authenticator{APIKeyService: apiKeyService, Tokens: tokens}, key, stored
+}
+
+func Test_Authenticator_Require_AttachesThePrincipal(t *testing.T) {
+	t.Parallel()
+
+	expires := time.Now().Add(time.Hour).Unix()
+
+	tests := map[string]struct {
+		// header returns the name and value of the header holding the credential
+		header            func(key string) (string, string)
+		expectedPrincipal func(stored *domain.APIKey) auth.Principal
+	}{
+		"API key header": {
+			header: func(key string) (string, string) { return "X-API-Key", key },
+			expectedPrincipal: func(stored *domain.APIKey) auth.Principal {
+				return auth.Principal{Subject: "apikey:" + stored.ID.String(), Name: "importer", Method: auth.MethodAPIKey, Role: auth.RoleUploader}
+			},
+		},
+		"API key as bearer": {
+			header: func(key string) (string, string) { return "Authorization", "Bearer " + key },
+			expectedPrincipal: func(stored *domain.APIKey) auth.Principal {
+				return auth.Principal{Subject: "apikey:" + stored.ID.String(), Name: "importer", Method: auth.MethodAPIKey, Role: auth.RoleUploader}
+			},
+		},
+		"token": {
+			header: func(string) (string, string) {
+				return "Authorization", "Bearer " + newHS256Token(fmt.Sprintf(`{"iss":"https://id.example.com","sub":"user-1","name":"User","exp":%d,"roles":["admin"]}`, expires))
+			},
+			expectedPrincipal: func(*domain.APIKey) auth.Principal {
+				return auth.Principal{Subject: "jwt:https://id.example.com:user-1", Name: "User", Method: auth.MethodJWT, Role: auth.RoleAdmin}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			authenticator, key, stored := newAuthenticator(t)
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "/tags", nil)
+			context.Request.Header.Set(test.header(key))
+
+			var principal *auth.Principal
+			next := func(c *gin.Context) {
+				principal, _ = auth.PrincipalFrom(c.Request.Context())
+			}
+
+			// act
+			authenticator.Require(auth.RoleUploader, next)(context)
+
+			// Assert
+			assert.Empty(t, context.Errors)
+			if assert.NotNil(t, principal) {
+				assert.Equal(t, test.expectedPrincipal(stored), *principal)
+			}
+		})
+	}
+}
+
+func Test_Authenticator_Require_RejectsMissingAndInvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		header string
+		value  string
+	}{
+		"no credentials": {},
+		"basic authentication": {
+			header: "Authorization",
+			value:  "Basic dXNlcjpwYXNzd29yZA==",
+		},
+		"unknown API key": {
+			header: "X-API-Key",
+			value:  auth.APIKeyPrefix + "unknown",
+		},
+		"expired token": {
+			header: "Authorization",
+			value:  "Bearer " + newHS256Token(fmt.Sprintf(`{"sub":"user-1","exp":%d}`, time.Now().Add(-time.Hour).Unix())),
+		},
+		"forged token": {
+			header: "Authorization",
+			value:  "Bearer " + newHS256Token(`{"sub":"user-1","exp":99999999999}`) + "forged",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			authenticator, _, _ := newAuthenticator(t)
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "/tags", nil)
+			if test.header != "" {
+				context.Request.Header.Set(test.header, test.value)
+			}
+
+			called := false
+			next := func(*gin.Context) { called = true }
+
+			// act
+			authenticator.Require(auth.RoleUploader, next)(context)
+
+			// Assert
+			assert.False(t, called)
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, &apierrors.UnauthorizedError{}, context.Errors.Last().Err)
+			}
+			assert.NotEmpty(t, writer.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func Test_Authenticator_Require_RejectsPrincipalsWithoutTheRole(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	authenticator, _, _ := newAuthenticator(t)
+	token := newHS256Token(fmt.Sprintf(`{"sub":"user-1","exp":%d,"roles":"viewer"}`, time.Now().Add(time.Hour).Unix()))
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPost, "/tags", nil)
+	context.Request.Header.Set("Authorization", "Bearer "+token)
+
+	called := false
+	next := func(*gin.Context) { called = true }
+
+	// act
+	authenticator.Require(auth.RoleUploader, next)(context)
+
+	// Assert
+	assert.False(t, called)
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.ForbiddenError{}, context.Errors.Last().Err)
+	}
+}
+
+func Test_Authenticator_RequireUnlessSigned_LetsSignedRequestsThrough(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		target string
+		header string
+		value  string
+		// expectedCalled is whether the handler runs, signed requests without credentials are verified by it
+		expectedCalled bool
+	}{
+		"signed":                {target: "/media/1/content?expires=1&signature=signed", expectedCalled: true},
+		"unsigned":              {target: "/media/1/content?expires=1"},
+		"signed with bad token": {target: "/media/1/content?expires=1&signature=signed", header: "Authorization", value: "Bearer forged"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			authenticator, _, _ := newAuthenticator(t)
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, test.target, nil)
+			if test.header != "" {
+				context.Request.Header.Set(test.header, test.value)
+			}
+
+			called := false
+			next := func(c *gin.Context) {
+				called = true
+				_, authenticated := auth.PrincipalFrom(c.Request.Context())
+				assert.False(t, authenticated)
+			}
+
+			// act
+			authenticator.RequireUnlessSigned(auth.RoleViewer, next)(context)
+
+			// Assert
+			assert.Equal(t, test.expectedCalled, called)
+			if !test.expectedCalled && assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, &apierrors.UnauthorizedError{}, context.Errors.Last().Err)
+			}
+		})
+	}
+}
@@ -10,15 +10,15 @@ import (
 	"github.com/google/uuid"
 )
 
-type listFunction[Input any, Output any] interface {
-	func(ctx context.Context, input Input) ([]Output, error)
-}
-
 type function[Input any, Output any] interface {
 	func(ctx context.Context, input Input) (*Output, error)
 }
 
-func list[Input, Output any, F listFunction[Input, Output]](c *gin.Context, callback F) {
+type updateFunction[Input any, Output any] interface {
+	func(ctx context.Context, id uuid.UUID, input Input) (*Output, error)
+}
+
+func list[Input, Output any, F function[Input, Output]](c *gin.Context, callback F) {
 	logger := utils.NewLogger(c.Request.Context())
 
 	var input Input
@@ -56,26 +56,75 @@ func create[Input, Output any, F function[Input, Output]](c *gin.Context, callba
 func getWithID[Output any, F function[uuid.UUID, Output]](c *gin.Context, callback F) {
 	logger := utils.NewLogger(c.Request.Context())
 
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+	output, err := callback(c.Request.Context(), id)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("getWithID operation failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func update[Input, Output any, F updateFunction[Input, Output]](c *gin.Context, callback F) {
+	logger := utils.NewLogger(c.Request.Context())
+
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := c.Bind(&input); err != nil {
+		logger.WithError(c.Error(err)).Error("failed Binding update input")
+		return
+	}
+	output, err := callback(c.Request.Context(), id, input)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("update operation failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func deleteWithID(c *gin.Context, callback func(ctx context.Context, id uuid.UUID) error) {
+	logger := utils.NewLogger(c.Request.Context())
+
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+	if err := callback(c.Request.Context(), id); err != nil {
+		logger.WithError(c.Error(err)).Error("delete operation failed")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindID parses the "id" URI parameter, on failure the error is added to the context and false is returned
+func bindID(c *gin.Context) (uuid.UUID, bool) {
+	logger := utils.NewLogger(c.Request.Context())
+
 	var input struct {
 		ID string `uri:"id" binding:"required"`
 	}
 
 	if err := c.BindUri(&input); err != nil {
 		logger.WithError(c.Error(err)).Error("failed Binding URI input")
-		return
+		return uuid.Nil, false
 	}
 
 	id, err := uuid.Parse(input.ID)
 	if err != nil {
 		logger.WithField("ID", input.ID).WithError(err).Error("failed parsing id")
 		c.Error(apierrors.NewInvalidUUIDError(input.ID))
-		return
-	}
-	output, err := callback(c.Request.Context(), id)
-	if err != nil {
-		logger.WithError(c.Error(err)).Error("getWithID operation failed")
-		return
+		return uuid.Nil, false
 	}
 
-	c.JSON(http.StatusOK, output)
+	return id, true
 }
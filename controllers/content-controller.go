@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ContentController struct {
+	MediaService services.IMediaService
+	FileStore    storage.FileStore
+	// URLSigner verifies the signature of requests without credentials, nil rejects them
+	URLSigner *urlsign.Signer
+}
+
+// GetMediaContent streams the stored file of the media. Range and conditional requests are answered using the quoted
+// content hash as strong ETag and the last modification of the media, media without a content hash only support
+// If-Modified-Since. The download query parameter makes browsers save the file instead of showing it. Requests
+// without credentials need the signature of the content URL of the media
+func (controller *ContentController) GetMediaContent(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+
+	download := false
+	if value := c.Query("download"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.WithError(c.Error(apierrors.NewInvalidParameterError("download", "expected true or false"))).Error("invalid download parameter")
+			return
+		}
+		download = parsed
+	}
+
+	// the signature is checked before the media is looked up, so requests without credentials can't tell which media
+	// exists
+	if _, authenticated := auth.PrincipalFrom(ctx); !authenticated && c.Query(urlsign.SignatureParameter) != "" {
+		if controller.URLSigner == nil {
+			logger.WithError(c.Error(errors.New("no signer to verify media content URLs with"))).Error("rejected media content URL")
+			return
+		}
+		if err := controller.URLSigner.Verify(c.Request.URL.EscapedPath(), c.Request.URL.Query()); err != nil {
+			logger.WithError(c.Error(apierrors.NewInvalidFileSignatureError(contentPath(id), err.Error()))).Error("rejected media content URL")
+			return
+		}
+	}
+
+	media, err := controller.MediaService.GetWithID(ctx, id)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed retrieving media")
+		return
+	}
+
+	file, err := controller.FileStore.Get(ctx, media.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			err = apierrors.NewFileNotFoundError(media.StorageKey)
+		}
+		logger.WithError(c.Error(err)).Error("failed opening media file")
+		return
+	}
+	defer file.Close()
+
+	// media sharing a file have the same content hash, the stored file never changes once the media is created. Media
+	// stored before content hashes were recorded has no ETag
+	if media.ContentHash != "" {
+		c.Header("ETag", fmt.Sprintf(`"%s"`, media.ContentHash))
+	}
+	if media.ContentType != "" {
+		// set explicitly so ServeContent doesn't sniff the type
+		c.Header("Content-Type", media.ContentType)
+	}
+	c.Header("Content-Disposition", contentDisposition(media, download))
+
+	// ServeContent takes care of Range, If-Range, If-None-Match and If-Modified-Since as well as HEAD requests
+	http.ServeContent(c.Writer, c.Request, "", media.UpdatedAt, file)
+}
+
+// contentDisposition names the file after the media, keeping the extension of the stored file
+func contentDisposition(media *domain.Media, download bool) string {
+	disposition := "inline"
+	if download {
+		disposition = "attachment"
+	}
+
+	extension := path.Ext(media.StorageKey)
+	filename := media.Name
+	if filename == "" {
+		filename = media.ID.String()
+	}
+	if !strings.EqualFold(path.Ext(filename), extension) {
+		filename += extension
+	}
+
+	// non ASCII names are encoded following RFC 2231
+	return mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+}
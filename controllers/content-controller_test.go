@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"bytes"
+	gocontext "context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	mock_services "github.com/TheSandyDave/Media-Tags/generated/mock/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testContent = "0123456789abcdefghij"
+
+// newContentController creates a controller serving the media whose file holds testContent
+func newContentController(t *testing.T, media *domain.Media) *ContentController {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetWithID(gomock.Any(), media.ID).Return(media, nil).AnyTimes()
+
+	fileStore := storage.NewMemoryFileStore()
+	require.NoError(t, fileStore.Put(gocontext.Background(), media.StorageKey, bytes.NewReader([]byte(testContent)), int64(len(testContent))))
+
+	signer, err := urlsign.New([]string{strings.Repeat("k", urlsign.MinKeyLength)}, time.Hour)
+	require.NoError(t, err)
+
+	return &ContentController{MediaService: mediaService, FileStore: fileStore, URLSigner: signer}
+}
+
+// newContentMedia creates media whose file is stored under its content hash
+func newContentMedia() *domain.Media {
+	return &domain.Media{
+		BaseObject: domain.BaseObject{
+			ID:        uuid.New(),
+			UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		},
+		Name:        "notes",
+		StorageKey:  "abc.txt",
+		ContentHash: "abc",
+		ContentType: "text/plain",
+	}
+}
+
+func Test_ContentController_GetMediaContent_WritesFileWithValidators(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	media := newContentMedia()
+	ContentController := newContentController(t, media)
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com/media/"+media.ID.String()+"/content", nil)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: media.ID.String()})
+
+	// act
+	ContentController.GetMediaContent(context)
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, testContent, writer.Body.String())
+	assert.Equal(t, `"abc"`, writer.Header().Get("ETag"))
+	assert.Equal(t, "Wed, 01 May 2024 12:00:00 GMT", writer.Header().Get("Last-Modified"))
+	assert.Equal(t, "text/plain", writer.Header().Get("Content-Type"))
+	assert.Equal(t, "bytes", writer.Header().Get("Accept-Ranges"))
+	assert.Equal(t, "inline; filename=notes.txt", writer.Header().Get("Content-Disposition"))
+}
+
+func Test_ContentController_GetMediaContent_AnswersRangeAndConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query           string
+		headers         map[string]string
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		"range": {
+			headers:         map[string]string{"Range": "bytes=5-9"},
+			expectedStatus:  http.StatusPartialContent,
+			expectedBody:    "56789",
+			expectedHeaders: map[string]string{"Content-Range": "bytes 5-9/20"},
+		},
+		"range of an unchanged file": {
+			headers:        map[string]string{"Range": "bytes=-3", "If-Range": `"abc"`},
+			expectedStatus: http.StatusPartialContent,
+			expectedBody:   "hij",
+		},
+		"range of a changed file": {
+			headers:        map[string]string{"Range": "bytes=-3", "If-Range": `"def"`},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testContent,
+		},
+		"range outside the file": {
+			headers:        map[string]string{"Range": "bytes=30-40"},
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		"matching ETag": {
+			headers:        map[string]string{"If-None-Match": `"abc"`},
+			expectedStatus: http.StatusNotModified,
+		},
+		"weak matching ETag": {
+			headers:        map[string]string{"If-None-Match": `W/"abc"`},
+			expectedStatus: http.StatusNotModified,
+		},
+		"unmodified since": {
+			headers:        map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"},
+			expectedStatus: http.StatusNotModified,
+		},
+		"modified since": {
+			headers:        map[string]string{"If-Modified-Since": "Tue, 30 Apr 2024 12:00:00 GMT"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testContent,
+		},
+		"download": {
+			query:           "?download=true",
+			expectedStatus:  http.StatusOK,
+			expectedBody:    testContent,
+			expectedHeaders: map[string]string{"Content-Disposition": "attachment; filename=notes.txt"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			media := newContentMedia()
+			ContentController := newContentController(t, media)
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com/media/"+media.ID.String()+"/content"+test.query, nil)
+			for key, value := range test.headers {
+				context.Request.Header.Set(key, value)
+			}
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: media.ID.String()})
+
+			// act
+			ContentController.GetMediaContent(context)
+			context.Writer.WriteHeaderNow()
+
+			// Assert
+			require.Empty(t, context.Errors)
+			assert.Equal(t, test.expectedStatus, writer.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, writer.Body.String())
+			}
+			for key, value := range test.expectedHeaders {
+				assert.Equal(t, value, writer.Header().Get(key))
+			}
+		})
+	}
+}
+
+func Test_ContentController_GetMediaContent_FailsIfTheFileIsMissing(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	media := newContentMedia()
+	ContentController := newContentController(t, media)
+	require.NoError(t, ContentController.FileStore.Delete(gocontext.Background(), media.StorageKey))
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com/media/"+media.ID.String()+"/content", nil)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: media.ID.String()})
+
+	// act
+	ContentController.GetMediaContent(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.FileNotFoundError{}, context.Errors.Last().Err)
+	}
+}
+
+func Test_ContentController_GetMediaContent_VerifiesSignaturesOfRequestsWithoutCredentials(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		// query returns the query of the request for the media, signed with the signer of the controller
+		query         func(signer *urlsign.Signer, id uuid.UUID) string
+		authenticated bool
+		// missing requests media that doesn't exist
+		missing       bool
+		expectedError error
+	}{
+		"signature of the content": {
+			query: func(signer *urlsign.Signer, id uuid.UUID) string { return signer.Sign(contentPath(id)).Encode() },
+		},
+		"signature of the file": {
+			query:         func(signer *urlsign.Signer, _ uuid.UUID) string { return signer.Sign("/files/abc.txt").Encode() },
+			expectedError: &apierrors.InvalidFileSignatureError{},
+		},
+		"signature of other content": {
+			query:         func(signer *urlsign.Signer, _ uuid.UUID) string { return signer.Sign(contentPath(uuid.New())).Encode() },
+			expectedError: &apierrors.InvalidFileSignatureError{},
+		},
+		"forged signature": {
+			query:         func(*urlsign.Signer, uuid.UUID) string { return "expires=99999999999&signature=forged" },
+			expectedError: &apierrors.InvalidFileSignatureError{},
+		},
+		"forged signature of missing media": {
+			query:         func(*urlsign.Signer, uuid.UUID) string { return "expires=99999999999&signature=forged" },
+			missing:       true,
+			expectedError: &apierrors.InvalidFileSignatureError{},
+		},
+		"signature of missing media": {
+			query:         func(signer *urlsign.Signer, id uuid.UUID) string { return signer.Sign(contentPath(id)).Encode() },
+			missing:       true,
+			expectedError: &apierrors.RecordNotFoundError{},
+		},
+		"authenticated with a forged signature": {
+			query:         func(*urlsign.Signer, uuid.UUID) string { return "expires=99999999999&signature=forged" },
+			authenticated: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			media := newContentMedia()
+			ContentController := newContentController(t, media)
+			id := media.ID
+			if test.missing {
+				id = uuid.New()
+				mediaService := ContentController.MediaService.(*mock_services.MockIMediaService)
+				mediaService.EXPECT().GetWithID(gomock.Any(), id).Return(nil, apierrors.NewNotFoundError(id)).MaxTimes(1)
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com"+contentPath(id)+"?"+test.query(ContentController.URLSigner, id), nil)
+			if test.authenticated {
+				principal := &auth.Principal{Subject: "viewer", Role: auth.RoleViewer}
+				context.Request = context.Request.WithContext(auth.WithPrincipal(context.Request.Context(), principal))
+			}
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: id.String()})
+
+			// act
+			ContentController.GetMediaContent(context)
+
+			// Assert
+			if test.expectedError != nil {
+				if assert.NotEmpty(t, context.Errors) {
+					assert.IsType(t, test.expectedError, context.Errors.Last().Err)
+				}
+				assert.Empty(t, writer.Body.String())
+				return
+			}
+			require.Empty(t, context.Errors)
+			assert.Equal(t, testContent, writer.Body.String())
+		})
+	}
+}
+
+func Test_contentDisposition_NamesFileAfterTheMedia(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		name     string
+		expected string
+	}{
+		"without extension": {name: "holiday", expected: "inline; filename=holiday.jpg"},
+		"with extension":    {name: "holiday.JPG", expected: "inline; filename=holiday.JPG"},
+		"with spaces":       {name: "summer holiday", expected: `inline; filename="summer holiday.jpg"`},
+		"non ASCII":         {name: "été", expected: "inline; filename*=utf-8''%C3%A9t%C3%A9.jpg"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			disposition := contentDisposition(&domain.Media{Name: test.name, StorageKey: "abc.jpg"}, false)
+
+			// Assert
+			assert.Equal(t, test.expected, disposition)
+		})
+	}
+}
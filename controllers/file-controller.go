@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type FileController struct {
+	FileStore storage.FileStore
+	// URLSigner verifies the signature of every file URL, files are only served through the signed URLs of media.
+	// Without one no file is served
+	URLSigner *urlsign.Signer
+}
+
+// GetFile streams a stored file, the key is taken from the wildcard "key" route parameter. The URL needs to carry a
+// valid signature that hasn't expired
+func (controller *FileController) GetFile(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	if controller.URLSigner == nil {
+		logger.WithError(c.Error(errors.New("no signer to verify file URLs with"))).Error("rejected file URL")
+		return
+	}
+	if err := controller.URLSigner.Verify(c.Request.URL.EscapedPath(), c.Request.URL.Query()); err != nil {
+		logger.WithError(c.Error(apierrors.NewInvalidFileSignatureError(key, err.Error()))).Error("rejected file URL")
+		return
+	}
+
+	info, err := controller.FileStore.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			err = apierrors.NewFileNotFoundError(key)
+		}
+		logger.WithError(c.Error(err)).Error("failed retrieving file info")
+		return
+	}
+
+	file, err := controller.FileStore.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			err = apierrors.NewFileNotFoundError(key)
+		}
+		logger.WithError(c.Error(err)).Error("failed opening file")
+		return
+	}
+	defer file.Close()
+
+	// ServeContent takes care of content type detection, range requests and conditional requests
+	http.ServeContent(c.Writer, c.Request, path.Base(key), info.ModifiedAt, file)
+}
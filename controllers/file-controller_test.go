@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	gocontext "context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileController creates a controller serving a single stored file from memory
+func newFileController(t *testing.T, key string, content []byte) *FileController {
+	t.Helper()
+
+	fileStore := storage.NewMemoryFileStore()
+	require.NoError(t, fileStore.Put(gocontext.Background(), key, bytes.NewReader(content), int64(len(content))))
+	signer, err := urlsign.New([]string{strings.Repeat("k", urlsign.MinKeyLength)}, time.Hour)
+	require.NoError(t, err)
+
+	return &FileController{FileStore: fileStore, URLSigner: signer}
+}
+
+func Test_FileController_GetFile_ServesSignedURLs(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	FileController := newFileController(t, "abc.txt", []byte("content"))
+	signedURL := "https://example.com/files/abc.txt?" + FileController.URLSigner.Sign("/files/abc.txt").Encode()
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, signedURL, nil)
+	context.Params = append(context.Params, gin.Param{Key: "key", Value: "/abc.txt"})
+
+	// act
+	FileController.GetFile(context)
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "content", writer.Body.String())
+}
+
+func Test_FileController_GetFile_RejectsURLsWithoutAValidSignature(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		target string
+		// signedPath is the path the signature added to the target was made for, empty leaves the target as is
+		signedPath string
+	}{
+		"unsigned": {
+			target: "https://example.com/files/abc.txt",
+		},
+		"signature of another file": {
+			target:     "https://example.com/files/abc.txt",
+			signedPath: "/files/other.txt",
+		},
+		"forged signature": {
+			target: "https://example.com/files/abc.txt?expires=99999999999&signature=forged",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			FileController := newFileController(t, "abc.txt", []byte("content"))
+			target := test.target
+			if test.signedPath != "" {
+				target += "?" + FileController.URLSigner.Sign(test.signedPath).Encode()
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, target, nil)
+			context.Params = append(context.Params, gin.Param{Key: "key", Value: "/abc.txt"})
+
+			// act
+			FileController.GetFile(context)
+
+			// Assert
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, &apierrors.InvalidFileSignatureError{}, context.Errors.Last().Err)
+			}
+			assert.Empty(t, writer.Body.String())
+		})
+	}
+}
+
+func Test_FileController_GetFile_RejectsEveryURLWithoutASigner(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	FileController := newFileController(t, "abc.txt", []byte("content"))
+	signedURL := "https://example.com/files/abc.txt?" + FileController.URLSigner.Sign("/files/abc.txt").Encode()
+	FileController.URLSigner = nil
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, signedURL, nil)
+	context.Params = append(context.Params, gin.Param{Key: "key", Value: "/abc.txt"})
+
+	// act
+	FileController.GetFile(context)
+
+	// Assert
+	assert.NotEmpty(t, context.Errors)
+	assert.Empty(t, writer.Body.String())
+}
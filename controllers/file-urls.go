@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/TheSandyDave/Media-Tags/conversion"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
+	"github.com/google/uuid"
+)
+
+// filesPath is the path stored files are served under by the FileController
+const filesPath = "/files/"
+
+// FileURLs builds the URLs of stored files and media content when media is returned, so they follow the address the
+// API is reached at
+type FileURLs struct {
+	// BaseURL is the public URL of the API such as https://media.example.com, a path like / makes the URLs relative.
+	// Empty derives the URL from every request
+	BaseURL string
+	// TrustedProxies are the networks whose X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix headers are
+	// used to derive the URL from a request
+	TrustedProxies []netip.Prefix
+	// Signer signs the URLs so they expire, they are returned unsigned without one
+	Signer *urlsign.Signer
+}
+
+// For returns the functions building the URLs of the response to the request, nil builds relative unsigned URLs
+func (urls *FileURLs) For(request *http.Request) conversion.MediaURLs {
+	build := func(path string) string {
+		return path
+	}
+	if urls != nil {
+		base := urls.baseURL(request)
+		build = func(path string) string {
+			if urls.Signer == nil {
+				return base + path
+			}
+			// proxies may strip the prefix of the base URL, only the path the API sees is signed
+			return base + path + "?" + urls.Signer.Sign(path).Encode()
+		}
+	}
+
+	return conversion.MediaURLs{
+		File: func(key string) string {
+			return build(filePath(key))
+		},
+		Content: func(id uuid.UUID) string {
+			return build(contentPath(id))
+		},
+	}
+}
+
+// filePath returns the escaped path the FileController serves the stored file with the key under
+func filePath(key string) string {
+	return (&url.URL{Path: filesPath + key}).EscapedPath()
+}
+
+// contentPath returns the path the ContentController serves the file of the media with the ID under
+func contentPath(id uuid.UUID) string {
+	return "/media/" + id.String() + "/content"
+}
+
+// baseURL returns the configured base URL without trailing slash, or the one the request was sent to
+func (urls *FileURLs) baseURL(request *http.Request) string {
+	if urls.BaseURL != "" {
+		return strings.TrimSuffix(urls.BaseURL, "/")
+	}
+
+	scheme, host, prefix := "http", request.Host, ""
+	if request.TLS != nil {
+		scheme = "https"
+	}
+	if urls.trusts(request.RemoteAddr) {
+		if proto := forwardedHeader(request, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := forwardedHeader(request, "X-Forwarded-Host"); forwardedHost != "" {
+			host = forwardedHost
+		}
+		if forwardedPrefix := forwardedHeader(request, "X-Forwarded-Prefix"); strings.HasPrefix(forwardedPrefix, "/") {
+			prefix = strings.TrimSuffix(forwardedPrefix, "/")
+		}
+	}
+
+	return scheme + "://" + host + prefix
+}
+
+// trusts reports whether the address the request came from belongs to a trusted proxy
+func (urls *FileURLs) trusts(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	address, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	for _, proxy := range urls.TrustedProxies {
+		if proxy.Contains(address.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHeader returns the value added by the trusted proxy the request came from. Proxies append to the values
+// sent by the client, so only the last one can be trusted
+func forwardedHeader(request *http.Request, header string) string {
+	values := request.Header.Values(header)
+	if len(values) == 0 {
+		return ""
+	}
+	last := values[len(values)-1]
+	return strings.TrimSpace(last[strings.LastIndex(last, ",")+1:])
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileURLs_For_BuildsURLsOfStoredFiles(t *testing.T) {
+	t.Parallel()
+
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	forwarded := map[string][]string{
+		"X-Forwarded-Proto":  {"https"},
+		"X-Forwarded-Host":   {"proxy.internal, media.example.com"},
+		"X-Forwarded-Prefix": {"/api/"},
+	}
+
+	tests := map[string]struct {
+		urls       *FileURLs
+		remoteAddr string
+		tls        bool
+		headers    map[string][]string
+		expected   string
+	}{
+		"without configuration": {
+			expected: "/files/thumbnails/a%20b.jpg",
+		},
+		"configured base URL": {
+			urls:     &FileURLs{BaseURL: "https://cdn.example.com/media/"},
+			headers:  forwarded,
+			expected: "https://cdn.example.com/media/files/thumbnails/a%20b.jpg",
+		},
+		"relative": {
+			urls:     &FileURLs{BaseURL: "/"},
+			expected: "/files/thumbnails/a%20b.jpg",
+		},
+		"derived from the request": {
+			urls:     &FileURLs{},
+			expected: "http://localhost:8080/files/thumbnails/a%20b.jpg",
+		},
+		"derived from a TLS request": {
+			urls:     &FileURLs{},
+			tls:      true,
+			expected: "https://localhost:8080/files/thumbnails/a%20b.jpg",
+		},
+		"forwarded by a trusted proxy": {
+			urls:       &FileURLs{TrustedProxies: trusted},
+			remoteAddr: "10.1.2.3:41234",
+			headers:    forwarded,
+			expected:   "https://media.example.com/api/files/thumbnails/a%20b.jpg",
+		},
+		"forwarded by an untrusted client": {
+			urls:       &FileURLs{TrustedProxies: trusted},
+			remoteAddr: "192.0.2.1:41234",
+			headers:    forwarded,
+			expected:   "http://localhost:8080/files/thumbnails/a%20b.jpg",
+		},
+		"spoofed by the client through a trusted proxy": {
+			urls:       &FileURLs{TrustedProxies: trusted},
+			remoteAddr: "10.1.2.3:41234",
+			headers: map[string][]string{
+				"X-Forwarded-Proto":  {"https, http"},
+				"X-Forwarded-Host":   {"evil.example", "media.example.com"},
+				"X-Forwarded-Prefix": {"/evil, /api"},
+			},
+			expected: "http://media.example.com/api/files/thumbnails/a%20b.jpg",
+		},
+		"forwarded with an unknown scheme": {
+			urls:       &FileURLs{TrustedProxies: trusted},
+			remoteAddr: "10.1.2.3:41234",
+			headers:    map[string][]string{"X-Forwarded-Proto": {"javascript"}},
+			expected:   "http://localhost:8080/files/thumbnails/a%20b.jpg",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			request := httptest.NewRequest(http.MethodGet, "http://localhost:8080/media", nil)
+			if test.remoteAddr != "" {
+				request.RemoteAddr = test.remoteAddr
+			}
+			if test.tls {
+				request.TLS = &tls.ConnectionState{}
+			}
+			for key, values := range test.headers {
+				for _, value := range values {
+					request.Header.Add(key, value)
+				}
+			}
+
+			// Act
+			fileURL := test.urls.For(request).File("thumbnails/a b.jpg")
+
+			// Assert
+			assert.Equal(t, test.expected, fileURL)
+		})
+	}
+}
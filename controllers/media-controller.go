@@ -1,17 +1,29 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"mime/multipart"
-	"path/filepath"
-	"strings"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
 	"github.com/TheSandyDave/Media-Tags/conversion"
 	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/filetype"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
+	"github.com/TheSandyDave/Media-Tags/metadata"
+	"github.com/TheSandyDave/Media-Tags/phash"
 	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/tagquery"
+	"github.com/TheSandyDave/Media-Tags/thumbnail"
 	"github.com/TheSandyDave/Media-Tags/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,26 +32,152 @@ import (
 type MediaController struct {
 	MediaService services.IMediaService
 	TagService   services.ITagService
-	IsTest       bool
+	// StoredFileService counts the references to the stored files, which media with the same content share
+	StoredFileService services.IStoredFileService
+	FileStore         storage.FileStore
+	// MaxFileSize is the maximum size in bytes of an uploaded file of any kind, 0 means no limit
+	MaxFileSize int64
+	// MaxRequestSize is the maximum size in bytes of an upload request including its form fields, 0 means no limit
+	MaxRequestSize int64
+	// Kinds restricts the uploads of every media kind, kinds without rules can't be uploaded
+	Kinds map[domain.MediaKind]UploadRules
+	// Thumbnails generates the thumbnails stored along with uploaded images
+	Thumbnails thumbnail.Generator
+	// Privacy is the metadata stripped from every stored image, uploads can strip more but never less
+	Privacy metadata.Policy
+	// RecordStripped keeps the extracted fields that are stripped from the stored file, without exposing or filtering
+	// on them
+	RecordStripped bool
+	// Duplicates decides what happens to uploads with the same content as existing media
+	Duplicates DuplicatePolicy
+	// FileURLs builds the URLs of the files of returned media, without it they are relative and unsigned
+	FileURLs *FileURLs
+}
+
+const (
+	// defaultSimilarDistance finds resized and re-encoded copies while rarely matching unrelated images
+	defaultSimilarDistance = 8
+	// maxSimilarDistance bounds the number of band values looked up to find similar media
+	maxSimilarDistance = 16
+)
+
+// DuplicatePolicy decides what happens to an upload with the same content as existing media
+type DuplicatePolicy string
+
+const (
+	// LinkDuplicates creates the media sharing the stored file of the existing media
+	LinkDuplicates DuplicatePolicy = "link"
+	// RejectDuplicates fails the upload with an error pointing to the existing media
+	RejectDuplicates DuplicatePolicy = "reject"
+)
+
+// UploadRules restrict the uploads of a single media kind
+type UploadRules struct {
+	// MaxFileSize is the maximum size in bytes of an uploaded file of the kind, 0 means no limit
+	MaxFileSize int64
+	// FileTypes restricts uploads based on the type detected from their content
+	FileTypes filetype.Allowlist
 }
 
 func (controller *MediaController) GetMedia(c *gin.Context) {
 	type inputFilters struct {
-		Tag string `form:"tag"`
+		paginationInput
+		Tags               []string `form:"tag"`
+		IncludeDescendants string   `form:"includeDescendants"`
+		Match              string   `form:"match"`
+		ExcludeTags        []string `form:"excludeTag"`
+		Query              string   `form:"query"`
+		Kind               string   `form:"kind"`
+		TakenAfter         string   `form:"takenAfter"`
+		TakenBefore        string   `form:"takenBefore"`
+		Camera             string   `form:"camera"`
 	}
 
-	list(c, func(ctx context.Context, input inputFilters) ([]*restgen.Media, error) {
+	list(c, func(ctx context.Context, input inputFilters) (*restgen.MediaPage, error) {
+		pagination, err := input.toPagination(services.SortByCreatedAt, services.SortByName)
+		if err != nil {
+			return nil, err
+		}
+
+		scope := services.ExactTag
+		if input.IncludeDescendants != "" {
+			includeDescendants, err := strconv.ParseBool(input.IncludeDescendants)
+			if err != nil {
+				return nil, apierrors.NewInvalidParameterError("includeDescendants", "expected true or false")
+			}
+			if includeDescendants {
+				scope = services.TagWithDescendants
+			}
+		}
+
 		var opts = []services.Option[domain.Media]{}
-		if input.Tag != "" {
-			opts = append(opts, controller.MediaService.FilterByTagOption(input.Tag))
+		if tags := nonEmpty(input.Tags); len(tags) > 0 {
+			switch input.Match {
+			case "", "all":
+				opts = append(opts, controller.MediaService.FilterByAllTagsOption(tags, scope))
+			case "any":
+				opts = append(opts, controller.MediaService.FilterByAnyTagOption(tags, scope))
+			default:
+				return nil, apierrors.NewInvalidParameterError("match", "expected all or any")
+			}
+		}
+		if excludedTags := nonEmpty(input.ExcludeTags); len(excludedTags) > 0 {
+			opts = append(opts, controller.MediaService.ExcludeTagsOption(excludedTags, scope))
+		}
+		if input.Query != "" {
+			query, err := tagquery.Parse(input.Query)
+			if err != nil {
+				return nil, apierrors.NewInvalidParameterError("query", err.Error())
+			}
+			opts = append(opts, controller.MediaService.FilterByTagQueryOption(query, scope))
+		}
+		if input.Kind != "" {
+			kind, ok := domain.ParseMediaKind(input.Kind)
+			if !ok {
+				return nil, apierrors.NewInvalidParameterError("kind", "expected image, video, audio or document")
+			}
+			opts = append(opts, controller.MediaService.FilterByKindOption(kind))
+		}
+		if input.TakenAfter != "" {
+			takenAfter, err := parseTime("takenAfter", input.TakenAfter)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, controller.MediaService.FilterByTakenAfterOption(takenAfter))
+		}
+		if input.TakenBefore != "" {
+			takenBefore, err := parseTime("takenBefore", input.TakenBefore)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, controller.MediaService.FilterByTakenBeforeOption(takenBefore))
+		}
+		if input.Camera != "" {
+			opts = append(opts, controller.MediaService.FilterByCameraOption(input.Camera))
 		}
 
-		media, err := controller.MediaService.Get(ctx, opts...)
+		page, err := controller.MediaService.GetPage(ctx, pagination, opts...)
 		if err != nil {
 			return nil, err
 		}
 
-		return conversion.EncodeSlice(media, conversion.EncodeMedia), nil
+		return conversion.EncodeMediaPage(page, controller.FileURLs.For(c.Request)), nil
+	})
+}
+
+// parseTime parses an RFC 3339 date time query parameter
+func parseTime(parameter string, value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apierrors.NewInvalidParameterError(parameter, "expected an RFC 3339 date time like 2024-05-01T12:00:00Z")
+	}
+	return parsed, nil
+}
+
+// nonEmpty drops empty values, which are sent for parameters without a value like ?tag=
+func nonEmpty(values []string) []string {
+	return slices.DeleteFunc(slices.Clone(values), func(value string) bool {
+		return value == ""
 	})
 }
 
@@ -50,73 +188,588 @@ func (controller *MediaController) GetMediaWithId(c *gin.Context) {
 			return nil, err
 		}
 
-		return conversion.EncodeMedia(media), nil
+		return conversion.EncodeMedia(media, controller.FileURLs.For(c.Request)), nil
+	})
+}
+
+// GetSimilarMedia lists the media whose perceptual hash is close to the one of the media, closest first
+func (controller *MediaController) GetSimilarMedia(c *gin.Context) {
+	getWithID(c, func(ctx context.Context, id uuid.UUID) (*restgen.SimilarMediaList, error) {
+		maxDistance := defaultSimilarDistance
+		if value := c.Query("maxDistance"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 || parsed > maxSimilarDistance {
+				return nil, apierrors.NewInvalidParameterError("maxDistance", fmt.Sprintf("expected a number between 0 and %d", maxSimilarDistance))
+			}
+			maxDistance = parsed
+		}
+		limit := services.DefaultPageSize
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > services.MaxPageSize {
+				return nil, apierrors.NewInvalidParameterError("limit", fmt.Sprintf("expected a number between 1 and %d", services.MaxPageSize))
+			}
+			limit = parsed
+		}
+
+		media, err := controller.MediaService.GetWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &restgen.SimilarMediaList{Items: []restgen.SimilarMedia{}}
+		if media.PerceptualHash == nil {
+			return result, nil
+		}
+
+		urls := controller.FileURLs.For(c.Request)
+		// the media itself is always found as well, one more is requested to make up for it
+		similar, err := controller.MediaService.GetSimilar(ctx, uint64(*media.PerceptualHash), maxDistance, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range similar {
+			if item.Media.ID == media.ID || len(result.Items) == limit {
+				continue
+			}
+			result.Items = append(result.Items, *conversion.EncodeSimilarMedia(item, urls))
+		}
+
+		return result, nil
 	})
 }
 
 func (controller *MediaController) CreateMedia(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
 
-	// Autogenerated input structs don't behave nicely with form data, just make it on the spot
-	type createMediaInput struct {
-		Name string                `form:"name"`
-		Tags []string              `form:"tags"`
-		File *multipart.FileHeader `form:"file"`
+	upload, err := controller.readUpload(c)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed reading upload")
+		return
+	}
+	// the temporary file is moved or copied once the upload is stored, either way it is no longer needed
+	defer controller.removeFiles(ctx, upload.TemporaryKey)
+
+	media, err := controller.createMedia(ctx, controller.FileURLs.For(c.Request), upload)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("create operation failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// createMedia stores the file of an upload under the key derived from its content and creates the media referring
+// to it, urls build the URLs of the returned media
+func (controller *MediaController) createMedia(ctx context.Context, urls conversion.MediaURLs, upload *mediaUpload) (*restgen.Media, error) {
+	if upload.TemporaryKey == "" {
+		return nil, apierrors.NewRequiredValueMissingError("file")
+	}
+
+	requested, err := metadata.ParsePolicy(upload.Strip)
+	if err != nil {
+		return nil, apierrors.NewInvalidParameterError("strip", err.Error())
+	}
+	privacy := controller.Privacy.Merge(requested)
+
+	// Check that all the tags actually exist
+	if len(upload.Tags) == 0 {
+		return nil, apierrors.NewRequiredValueMissingError("tags")
+	}
+	tags, err := controller.getTags(ctx, upload.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if upload.Name == "" {
+		return nil, apierrors.NewRequiredValueMissingError("name")
 	}
-	create(c, func(ctx context.Context, input createMediaInput) (*restgen.Media, error) {
-		// Check that all the tags actually exist
-		if input.File == nil {
-			return nil, apierrors.NewRequiredValueMissingError("file")
-		}
 
-		//validate that the content is an image
-		if !strings.Contains(input.File.Header.Get("Content-Type"), "image") {
-			return nil, apierrors.NewInvalidFileTypeError("image")
+	mimeType := upload.Detected.MIMEType
+	stripped, err := controller.stripMetadata(ctx, upload.TemporaryKey, mimeType, privacy)
+	if err != nil {
+		return nil, err
+	}
+	hash := upload.Hash
+	// metadata is extracted from the file as it was uploaded, which is only kept apart when it has been stripped
+	original := ""
+	if stripped != nil {
+		sum := sha256.Sum256(stripped)
+		hash = hex.EncodeToString(sum[:])
+		original = upload.TemporaryKey
+	}
+
+	existing, err := controller.findDuplicate(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && controller.Duplicates == RejectDuplicates {
+		return nil, apierrors.NewDuplicateMediaError(existing.ID)
+	}
+
+	// the file is named after its content so identical uploads share it, the extension of the detected type
+	// is used so the stored file is served with the right type
+	filename := fmt.Sprintf("%s%s", hash, upload.Detected.Extension)
+	if err := controller.StoredFileService.Acquire(ctx, filename); err != nil {
+		return nil, err
+	}
+	err = controller.storeOnce(ctx, filename, func() error {
+		if stripped != nil {
+			return controller.FileStore.Put(ctx, filename, bytes.NewReader(stripped), int64(len(stripped)))
 		}
+		return controller.FileStore.Move(ctx, upload.TemporaryKey, filename)
+	})
+	if err != nil {
+		controller.releaseFiles(ctx, filename)
+		return nil, err
+	}
+	if original == "" {
+		original = filename
+	}
+	// the references become the ones of the media once it is created, a failed upload releases them instead
+	cleanup := func(media *domain.Media) {
+		controller.releaseFiles(ctx, storageKeys(media)...)
+	}
 
-		if len(input.Tags) == 0 {
-			return nil, apierrors.NewRequiredValueMissingError("tags")
+	var thumbnails []*domain.Thumbnail
+	var perceptualHash *int64
+	if existing != nil {
+		thumbnails, err = controller.shareThumbnails(ctx, existing.Thumbnails)
+		if err != nil {
+			cleanup(&domain.Media{StorageKey: filename})
+			return nil, err
 		}
-		tagIds, err := utils.StringSliceToUUID(input.Tags)
+		perceptualHash = existing.PerceptualHash
+	}
+	if thumbnails == nil {
+		thumbnails, err = controller.storeThumbnails(ctx, filename, mimeType, hash)
 		if err != nil {
+			cleanup(&domain.Media{StorageKey: filename})
 			return nil, err
 		}
-
-		tags, err := controller.TagService.GetWithIDs(ctx, tagIds)
+	}
+	if existing == nil {
+		perceptualHash, err = controller.perceptualHash(ctx, filename, mimeType)
 		if err != nil {
-			recordsNotFoundError, ok := err.(*apierrors.RecordsNotFoundWithIDs)
-			if ok {
-				return nil, apierrors.NewInvalidTagsError(recordsNotFoundError.IDs)
-			} else {
-				return nil, err
+			cleanup(&domain.Media{StorageKey: filename, Thumbnails: thumbnails})
+			return nil, err
+		}
+	}
+
+	mediaMetadata, err := controller.extractMetadata(ctx, original, mimeType)
+	if err != nil {
+		cleanup(&domain.Media{StorageKey: filename, Thumbnails: thumbnails})
+		return nil, err
+	}
+	privacy.Redact(mediaMetadata, controller.RecordStripped)
+
+	media := &domain.Media{
+		BaseObject:     domain.BaseObject{ID: upload.ID},
+		Name:           upload.Name,
+		Owned:          domain.Owned{OwnerID: upload.Owner},
+		Tags:           tags,
+		StorageKey:     filename,
+		ContentHash:    hash,
+		ContentType:    mimeType,
+		Kind:           upload.Kind,
+		Thumbnails:     thumbnails,
+		Metadata:       mediaMetadata,
+		PerceptualHash: perceptualHash,
+	}
+
+	if err := controller.MediaService.Create(ctx, media); err != nil {
+		// don't leave behind files that no media refers to
+		cleanup(media)
+		return nil, err
+	}
+
+	result := conversion.EncodeMedia(media, urls)
+	if existing != nil {
+		duplicateOf := existing.ID.String()
+		result.DuplicateOf = &duplicateOf
+	}
+	return result, nil
+}
+
+// findDuplicate returns the oldest media with the content hash, nil if there is none
+func (controller *MediaController) findDuplicate(ctx context.Context, hash string) (*domain.Media, error) {
+	duplicates, err := controller.MediaService.Get(
+		ctx,
+		controller.MediaService.FilterByContentHashOption(hash),
+		services.SortOption[domain.Media](services.SortByCreatedAt, false),
+		services.LimitOption[domain.Media](1),
+	)
+	if err != nil || len(duplicates) == 0 {
+		return nil, err
+	}
+
+	return duplicates[0], nil
+}
+
+// storeOnce stores a file using store unless the key exists already, keys derived from the content always have the
+// same content. The file has to be acquired first, otherwise it could be removed right after it was found
+func (controller *MediaController) storeOnce(ctx context.Context, key string, store func() error) error {
+	if _, err := controller.FileStore.Stat(ctx, key); err == nil {
+		return nil
+	} else if !errors.Is(err, storage.ErrFileNotFound) {
+		return err
+	}
+
+	return store()
+}
+
+// shareThumbnails acquires the stored thumbnails of existing media with the same content, nil if any of them has
+// been removed along with the last media referring to it so the thumbnails have to be generated again
+func (controller *MediaController) shareThumbnails(ctx context.Context, thumbnails []*domain.Thumbnail) ([]*domain.Thumbnail, error) {
+	copies := copyThumbnails(thumbnails)
+	keys := storageKeys(&domain.Media{Thumbnails: copies})
+	if err := controller.StoredFileService.Acquire(ctx, keys...); err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if _, err := controller.FileStore.Stat(ctx, key); err != nil {
+			controller.releaseFiles(ctx, keys...)
+			if errors.Is(err, storage.ErrFileNotFound) {
+				return nil, nil
 			}
+			return nil, err
 		}
+	}
+	return copies, nil
+}
+
+// copyThumbnails creates new thumbnails referring to the same stored files
+func copyThumbnails(thumbnails []*domain.Thumbnail) []*domain.Thumbnail {
+	copies := make([]*domain.Thumbnail, len(thumbnails))
+	for i, thumbnail := range thumbnails {
+		copies[i] = &domain.Thumbnail{
+			Size:        thumbnail.Size,
+			Width:       thumbnail.Width,
+			Height:      thumbnail.Height,
+			ContentType: thumbnail.ContentType,
+			StorageKey:  thumbnail.StorageKey,
+		}
+	}
+	return copies
+}
 
+// stripMetadata returns the uploaded image without the metadata blocks of the policy, nil if nothing is stripped
+// from the file so it can be stored as is
+func (controller *MediaController) stripMetadata(ctx context.Context, key string, mimeType string, policy metadata.Policy) ([]byte, error) {
+	if policy.Empty() || !metadata.CanStrip(mimeType) {
+		return nil, nil
+	}
+
+	content, err := controller.FileStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.Strip(data, mimeType, policy), nil
+}
+
+// storeThumbnails generates and stores the thumbnails of an uploaded image, images that fail to decode are
+// stored without thumbnails instead of failing the upload
+func (controller *MediaController) storeThumbnails(
+	ctx context.Context,
+	key string,
+	mimeType string,
+	name string,
+) ([]*domain.Thumbnail, error) {
+	if !controller.Thumbnails.Supports(mimeType) {
+		return nil, nil
+	}
+
+	// the original has been streamed to the file store already, it is read back from there
+	content, err := controller.FileStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	generated, err := controller.Thumbnails.Generate(content, mimeType)
+	if err != nil {
+		utils.NewLogger(ctx).WithError(err).Warn("failed generating thumbnails")
+		return nil, nil
+	}
+
+	thumbnails := make([]*domain.Thumbnail, 0, len(generated))
+	for _, generatedThumbnail := range generated {
+		key := fmt.Sprintf("thumbnails/%s-%d%s", name, generatedThumbnail.Size, generatedThumbnail.Extension)
+		size := int64(len(generatedThumbnail.Content))
+		if err := controller.StoredFileService.Acquire(ctx, key); err != nil {
+			controller.releaseFiles(ctx, storageKeys(&domain.Media{Thumbnails: thumbnails})...)
+			return nil, err
+		}
+		if err := controller.FileStore.Put(ctx, key, bytes.NewReader(generatedThumbnail.Content), size); err != nil {
+			controller.releaseFiles(ctx, append(storageKeys(&domain.Media{Thumbnails: thumbnails}), key)...)
+			return nil, err
+		}
+
+		thumbnails = append(thumbnails, &domain.Thumbnail{
+			Size:        generatedThumbnail.Size,
+			Width:       generatedThumbnail.Width,
+			Height:      generatedThumbnail.Height,
+			ContentType: generatedThumbnail.ContentType,
+			StorageKey:  key,
+		})
+	}
+
+	return thumbnails, nil
+}
+
+// perceptualHash computes the difference hash of an uploaded image, images that fail to decode are stored without
+// a hash instead of failing the upload
+func (controller *MediaController) perceptualHash(ctx context.Context, key string, mimeType string) (*int64, error) {
+	if !controller.Thumbnails.Decodes(mimeType) {
+		return nil, nil
+	}
+
+	content, err := controller.FileStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	source, err := controller.Thumbnails.Decode(content, mimeType)
+	if err != nil {
+		utils.NewLogger(ctx).WithError(err).Warn("failed computing the perceptual hash")
+		return nil, nil
+	}
+
+	// the bits are stored as is, SQLite has no unsigned integers
+	hash := int64(phash.Difference(source))
+	return &hash, nil
+}
+
+// extractMetadata reads the dimensions and EXIF metadata of an uploaded image, images that fail to decode are
+// stored without metadata instead of failing the upload
+func (controller *MediaController) extractMetadata(ctx context.Context, key string, mimeType string) (*domain.MediaMetadata, error) {
+	if !metadata.Supports(mimeType) {
+		return nil, nil
+	}
+
+	content, err := controller.FileStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	extracted, err := metadata.Extract(content, mimeType)
+	if err != nil {
+		utils.NewLogger(ctx).WithError(err).Warn("failed extracting metadata")
+		return nil, nil
+	}
+
+	return extracted, nil
+}
+
+// storageKeys lists every stored file of the media, the original followed by its thumbnails
+func storageKeys(media *domain.Media) []string {
+	var keys []string
+	if media.StorageKey != "" {
+		keys = append(keys, media.StorageKey)
+	}
+	for _, thumbnail := range media.Thumbnails {
+		keys = append(keys, thumbnail.StorageKey)
+	}
+	return keys
+}
+
+// removeFiles deletes stored files, failures are only logged since nothing refers to the files anymore
+func (controller *MediaController) removeFiles(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := controller.FileStore.Delete(ctx, key); err != nil {
+			utils.NewLogger(ctx).WithField("key", key).WithError(err).Error("failed removing stored file")
+		}
+	}
+}
+
+// releaseFiles releases the references of a failed upload, removing the files nothing else refers to. Failures
+// are only logged, at worst the files stay stored without being referred to
+func (controller *MediaController) releaseFiles(ctx context.Context, keys ...string) {
+	remove := func(keys ...string) { controller.removeFiles(ctx, keys...) }
+	if err := controller.StoredFileService.Release(ctx, remove, keys...); err != nil {
+		utils.NewLogger(ctx).WithField("keys", keys).WithError(err).Error("failed releasing stored files")
+	}
+}
+
+// rulesFor returns the kind of the detected type along with the upload rules of the kind
+func (controller *MediaController) rulesFor(detected *filetype.Type) (domain.MediaKind, UploadRules, error) {
+	kind, ok := domain.MediaKindOf(detected.MIMEType)
+	if !ok {
+		return "", UploadRules{}, filetype.ErrTypeNotAllowed
+	}
+
+	rules, ok := controller.Kinds[kind]
+	if !ok {
+		return "", UploadRules{}, filetype.ErrTypeNotAllowed
+	}
+
+	return kind, rules, nil
+}
+
+func (controller *MediaController) UpdateMedia(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.UpdateMedia) (*restgen.Media, error) {
+		media, err := controller.MediaService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if input.Name != "" {
+			media.Name = input.Name
+		}
+
+		if err := controller.MediaService.Update(ctx, media); err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeMedia(media, controller.FileURLs.For(c.Request)), nil
+	})
+}
+
+// ReplaceMedia replaces the fields of the media, its file and tags have their own endpoints
+func (controller *MediaController) ReplaceMedia(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.ReplaceMedia) (*restgen.Media, error) {
 		if input.Name == "" {
 			return nil, apierrors.NewRequiredValueMissingError("name")
 		}
 
-		// replace the file name with a UUID to avoid overwritting if multiple uploads have the same name
-		filename := fmt.Sprintf("%s%s", uuid.NewString(), filepath.Ext(input.File.Filename))
+		media, err := controller.MediaService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
 
-		// Skip file creation during tests
-		if !controller.IsTest {
-			err = c.SaveUploadedFile(input.File, fmt.Sprintf("static/%s", filename))
-			if err != nil {
-				return nil, err
+		media.Name = input.Name
+		if err := controller.MediaService.Update(ctx, media); err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeMedia(media, controller.FileURLs.For(c.Request)), nil
+	})
+}
+
+func (controller *MediaController) DeleteMedia(c *gin.Context) {
+	deleteWithID(c, func(ctx context.Context, id uuid.UUID) error {
+		// files other media still refer to are kept, files that fail to be removed are only logged
+		err := controller.MediaService.DeleteWithFiles(ctx, id, func(keys ...string) {
+			controller.removeFiles(ctx, keys...)
+		}, services.OwnedByOption[domain.Media](ctx))
+		var notFound *apierrors.RecordNotFoundError
+		if errors.As(err, &notFound) {
+			// media of others is reported as forbidden rather than as missing
+			if _, ownedErr := controller.MediaService.GetOwnedWithID(ctx, id); ownedErr != nil {
+				return ownedErr
 			}
+		}
+		return err
+	})
+}
 
+func (controller *MediaController) AddMediaTags(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.MediaTags) (*restgen.Media, error) {
+		if len(input.Tags) == 0 {
+			return nil, apierrors.NewRequiredValueMissingError("tags")
 		}
 
-		media := &domain.Media{
-			Name:    input.Name,
-			Tags:    tags,
-			FileUrl: fmt.Sprintf("%s/files/%s", c.Request.Host, filename),
+		media, err := controller.changeTags(ctx, id, input.Tags, controller.MediaService.AddTags)
+		if err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeMedia(media, controller.FileURLs.For(c.Request)), nil
+	})
+}
+
+// ReplaceMediaTags replaces all tags of the media, like uploads media always keeps at least one tag
+func (controller *MediaController) ReplaceMediaTags(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.MediaTags) (*restgen.Media, error) {
+		if len(input.Tags) == 0 {
+			return nil, apierrors.NewRequiredValueMissingError("tags")
 		}
 
-		if err := controller.MediaService.Create(ctx, media); err != nil {
+		media, err := controller.changeTags(ctx, id, input.Tags, controller.MediaService.ReplaceTags)
+		if err != nil {
 			return nil, err
 		}
 
-		return conversion.EncodeMedia(media), nil
+		return conversion.EncodeMedia(media, controller.FileURLs.For(c.Request)), nil
 	})
 }
+
+func (controller *MediaController) RemoveMediaTag(c *gin.Context) {
+	logger := utils.NewLogger(c.Request.Context())
+
+	tagIDParam := c.Param("tagId")
+	tagID, err := uuid.Parse(tagIDParam)
+	if err != nil {
+		logger.WithField("tagId", tagIDParam).WithError(err).Error("failed parsing tag id")
+		c.Error(apierrors.NewInvalidUUIDError(tagIDParam))
+		return
+	}
+
+	deleteWithID(c, func(ctx context.Context, id uuid.UUID) error {
+		media, err := controller.MediaService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return controller.MediaService.RemoveTag(ctx, media, tagID)
+	})
+}
+
+func (controller *MediaController) changeTags(
+	ctx context.Context,
+	id uuid.UUID,
+	tagIDs []string,
+	change func(ctx context.Context, media *domain.Media, tags []*domain.Tag) error,
+) (*domain.Media, error) {
+	tags, err := controller.getTags(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := controller.MediaService.GetOwnedWithID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := change(ctx, media, tags); err != nil {
+		return nil, err
+	}
+
+	// reload so the response reflects the stored associations
+	return controller.MediaService.GetWithID(ctx, id)
+}
+
+// getTags retrieves the tags with the given IDs, failing with an InvalidTagsError if any of them do not exist
+func (controller *MediaController) getTags(ctx context.Context, tagIDs []string) ([]*domain.Tag, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	ids, err := utils.StringSliceToUUID(tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := controller.TagService.GetWithIDs(ctx, ids)
+	if err != nil {
+		recordsNotFoundError, ok := err.(*apierrors.RecordsNotFoundWithIDs)
+		if ok {
+			return nil, apierrors.NewInvalidTagsError(recordsNotFoundError.IDs)
+		} else {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
@@ -2,6 +2,10 @@ package controllers
 
 import (
 	"bytes"
+	gocontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -10,17 +14,32 @@ import (
 	"net/textproto"
 	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
 	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/filetype"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
 	mock_services "github.com/TheSandyDave/Media-Tags/generated/mock/services"
+	mock_storage "github.com/TheSandyDave/Media-Tags/generated/mock/storage"
+	"github.com/TheSandyDave/Media-Tags/metadata"
+	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/tagquery"
+	"github.com/TheSandyDave/Media-Tags/thumbnail"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
 	"github.com/TheSandyDave/Media-Tags/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -74,7 +93,7 @@ func Test_MediaController_Get_WritesCorrectOutput(t *testing.T) {
 			tagService := mock_services.NewMockITagService(ctrl)
 			mediaService := mock_services.NewMockIMediaService(ctrl)
 
-			mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(testData.models, nil)
+			mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Media]{Items: testData.models}, nil)
 
 			MediaController := MediaController{
 				TagService:   tagService,
@@ -92,10 +111,10 @@ func Test_MediaController_Get_WritesCorrectOutput(t *testing.T) {
 			MediaController.GetMedia(context)
 
 			// Assert
-			var result []restgen.Media
+			var result restgen.MediaPage
 			if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
-				assert.Equal(t, len(testData.output), len(result))
-				equal := slices.EqualFunc(testData.output, result, func(a, b restgen.Media) bool {
+				assert.Equal(t, len(testData.output), len(result.Items))
+				equal := slices.EqualFunc(testData.output, result.Items, func(a, b restgen.Media) bool {
 					return a.Name == b.Name
 				})
 				assert.True(t, equal)
@@ -126,8 +145,8 @@ func Test_MediaController_Get_CreatesAFilterWhenQueryParamIsPassed(t *testing.T)
 	mediaService := mock_services.NewMockIMediaService(ctrl)
 	expectedFilter := "test filter tag"
 
-	mediaService.EXPECT().FilterByTagOption(expectedFilter).Return(nil)
-	mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(models, nil)
+	mediaService.EXPECT().FilterByAllTagsOption([]string{expectedFilter}, services.ExactTag).Return(nil)
+	mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Media]{Items: models}, nil)
 
 	MediaController := MediaController{
 		TagService:   tagService,
@@ -149,16 +168,358 @@ func Test_MediaController_Get_CreatesAFilterWhenQueryParamIsPassed(t *testing.T)
 	MediaController.GetMedia(context)
 
 	// Assert
-	var result []restgen.Media
+	var result restgen.MediaPage
 	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
-		assert.Equal(t, len(output), len(result))
-		equal := slices.EqualFunc(output, result, func(a, b restgen.Media) bool {
+		assert.Equal(t, len(output), len(result.Items))
+		equal := slices.EqualFunc(output, result.Items, func(a, b restgen.Media) bool {
 			return a.Name == b.Name
 		})
 		assert.True(t, equal)
 	}
 }
 
+func Test_MediaController_Get_CreatesTagFilters(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query  url.Values
+		expect func(mediaService *mock_services.MockIMediaService)
+	}{
+		"all tags by default": {
+			query: url.Values{"tag": {"cat", "garden"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().FilterByAllTagsOption([]string{"cat", "garden"}, services.ExactTag).Return(nil)
+			},
+		},
+		"any tag": {
+			query: url.Values{"tag": {"cat", "dog"}, "match": {"any"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().FilterByAnyTagOption([]string{"cat", "dog"}, services.ExactTag).Return(nil)
+			},
+		},
+		"excluded tags": {
+			query: url.Values{"excludeTag": {"blurry", "dark"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().ExcludeTagsOption([]string{"blurry", "dark"}, services.ExactTag).Return(nil)
+			},
+		},
+		"descendants": {
+			query: url.Values{"tag": {"animal"}, "excludeTag": {"cat"}, "includeDescendants": {"true"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().FilterByAllTagsOption([]string{"animal"}, services.TagWithDescendants).Return(nil)
+				mediaService.EXPECT().ExcludeTagsOption([]string{"cat"}, services.TagWithDescendants).Return(nil)
+			},
+		},
+		"combined": {
+			query: url.Values{"tag": {"cat", ""}, "match": {"all"}, "excludeTag": {"blurry"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().FilterByAllTagsOption([]string{"cat"}, services.ExactTag).Return(nil)
+				mediaService.EXPECT().ExcludeTagsOption([]string{"blurry"}, services.ExactTag).Return(nil)
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mediaService := mock_services.NewMockIMediaService(ctrl)
+			testData.expect(mediaService)
+			mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Media]{}, nil)
+
+			MediaController := MediaController{
+				MediaService: mediaService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?"+testData.query.Encode(), nil)
+
+			// act
+			MediaController.GetMedia(context)
+
+			// Assert
+			assert.Empty(t, context.Errors)
+			assert.Equal(t, http.StatusOK, writer.Code)
+		})
+	}
+}
+
+func Test_MediaController_Get_PassesPaginationAndWritesPage(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	cursor := services.Cursor{Sort: services.SortByName.Name, Descending: true, Value: "b", ID: uuid.New()}
+	total := int64(12)
+
+	mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, pagination services.Pagination, _ ...services.Option[domain.Media]) (*domain.Page[domain.Media], error) {
+			assert.Equal(t, 10, pagination.Limit)
+			assert.Equal(t, services.SortByName, pagination.Sort)
+			assert.True(t, pagination.Descending)
+			assert.Equal(t, &cursor, pagination.Cursor)
+			return &domain.Page[domain.Media]{
+				Items:      []*domain.Media{{Name: "a"}},
+				NextCursor: "next",
+				Total:      &total,
+			}, nil
+		})
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	query := url.Values{}
+	query.Add("limit", "10")
+	query.Add("sort", "name")
+	query.Add("order", "desc")
+	query.Add("cursor", cursor.Encode())
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?"+query.Encode(), nil)
+
+	// act
+	MediaController.GetMedia(context)
+
+	// Assert
+	var result restgen.MediaPage
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, "next", result.NextCursor)
+		assert.Equal(t, &total, result.Total)
+	}
+}
+
+func Test_MediaController_Get_RejectsInvalidPagination(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]url.Values{
+		"limit too small":   {"limit": {"0"}},
+		"limit too large":   {"limit": {"101"}},
+		"limit not number":  {"limit": {"ten"}},
+		"unknown sort":      {"sort": {"size"}},
+		"unknown order":     {"order": {"sideways"}},
+		"unknown match":     {"tag": {"cat"}, "match": {"most"}},
+		"invalid boolean":   {"tag": {"cat"}, "includeDescendants": {"maybe"}},
+		"malformed cursor":  {"cursor": {"not a cursor"}},
+		"cursor other sort": {"sort": {"name"}, "cursor": {services.Cursor{Sort: "createdAt", ID: uuid.New()}.Encode()}},
+	}
+
+	for name, query := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			MediaController := MediaController{
+				MediaService: mock_services.NewMockIMediaService(ctrl),
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?"+query.Encode(), nil)
+
+			// act
+			MediaController.GetMedia(context)
+
+			// Assert
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+			}
+		})
+	}
+}
+
+func Test_MediaController_Get_ParsesTagQuery(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().FilterByTagQueryOption(gomock.Any(), services.ExactTag).
+		DoAndReturn(func(query tagquery.Node, _ services.TagScope) services.Option[domain.Media] {
+			assert.Equal(t, "(cat AND NOT blurry)", query.String())
+			return nil
+		})
+	mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Media]{}, nil)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	query := url.Values{}
+	query.Add("query", "cat and not blurry")
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?"+query.Encode(), nil)
+
+	// act
+	MediaController.GetMedia(context)
+
+	// Assert
+	assert.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusOK, writer.Code)
+}
+
+func Test_MediaController_Get_RejectsInvalidTagQuery(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	MediaController := MediaController{
+		MediaService: mock_services.NewMockIMediaService(ctrl),
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	query := url.Values{}
+	query.Add("query", "cat AND (outdoor")
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?"+query.Encode(), nil)
+
+	// act
+	MediaController.GetMedia(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+		assert.Contains(t, context.Errors.Last().Err.Error(), "syntax error at position 17")
+	}
+}
+
+func Test_MediaController_Get_FiltersByKind(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		kind          string
+		expectedError bool
+	}{
+		"image":    {kind: "image"},
+		"document": {kind: "document"},
+		"unknown":  {kind: "spreadsheet", expectedError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mediaService := mock_services.NewMockIMediaService(ctrl)
+			if !testCase.expectedError {
+				mediaService.EXPECT().FilterByKindOption(domain.MediaKind(testCase.kind)).Return(nil)
+				mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Media]{}, nil)
+			}
+
+			MediaController := MediaController{
+				MediaService: mediaService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?kind="+testCase.kind, nil)
+
+			// act
+			MediaController.GetMedia(context)
+
+			// Assert
+			if testCase.expectedError {
+				if assert.NotEmpty(t, context.Errors) {
+					assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+				}
+			} else {
+				assert.Empty(t, context.Errors)
+			}
+		})
+	}
+}
+
+func Test_MediaController_Get_FiltersByMetadata(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query         url.Values
+		expect        func(mediaService *mock_services.MockIMediaService)
+		expectedError string
+	}{
+		"taken after": {
+			query: url.Values{"takenAfter": {"2024-05-01T14:00:00+02:00"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().FilterByTakenAfterOption(gomock.Cond(func(after time.Time) bool {
+					return after.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+				})).Return(nil)
+			},
+		},
+		"taken before and camera": {
+			query: url.Values{"takenBefore": {"2024-05-01T12:00:00Z"}, "camera": {"canon"}},
+			expect: func(mediaService *mock_services.MockIMediaService) {
+				mediaService.EXPECT().FilterByTakenBeforeOption(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)).Return(nil)
+				mediaService.EXPECT().FilterByCameraOption("canon").Return(nil)
+			},
+		},
+		"invalid taken after": {
+			query:         url.Values{"takenAfter": {"2024-05-01"}},
+			expectedError: "takenAfter",
+		},
+		"invalid taken before": {
+			query:         url.Values{"takenBefore": {"yesterday"}},
+			expectedError: "takenBefore",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mediaService := mock_services.NewMockIMediaService(ctrl)
+			if testCase.expect != nil {
+				testCase.expect(mediaService)
+				mediaService.EXPECT().GetPage(gomock.Any(), gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Media]{}, nil)
+			}
+
+			MediaController := MediaController{
+				MediaService: mediaService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?"+testCase.query.Encode(), nil)
+
+			// act
+			MediaController.GetMedia(context)
+
+			// Assert
+			if testCase.expectedError != "" {
+				if assert.NotEmpty(t, context.Errors) {
+					assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+					assert.Contains(t, context.Errors.Last().Err.Error(), testCase.expectedError)
+				}
+			} else {
+				assert.Empty(t, context.Errors)
+			}
+		})
+	}
+}
+
 func Test_MediaController_GetWithID_WritesCorrectOutput(t *testing.T) {
 	t.Parallel()
 
@@ -202,6 +563,58 @@ func Test_MediaController_GetWithID_WritesCorrectOutput(t *testing.T) {
 
 }
 
+func Test_MediaController_GetWithID_DerivesSignedFileURLsFromStorageKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	expectedMedia := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name:       "expectedMedia",
+		StorageKey: "abc.jpg",
+		Thumbnails: []*domain.Thumbnail{
+			{Size: 256, StorageKey: "thumbnails/abc-256.jpg"},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetWithID(gomock.Any(), expectedMedia.ID, gomock.Any()).Return(&expectedMedia, nil)
+
+	signer, err := urlsign.New([]string{strings.Repeat("k", urlsign.MinKeyLength)}, time.Hour)
+	require.NoError(t, err)
+	MediaController := MediaController{
+		MediaService: mediaService,
+		FileURLs:     &FileURLs{BaseURL: "https://media.example.com/", Signer: signer},
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, "http://localhost:8080/media/"+expectedMedia.ID.String(), nil)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: expectedMedia.ID.String()})
+
+	// act
+	MediaController.GetMediaWithId(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		expectedURLs := map[string]string{
+			result.FileUrl:           "https://media.example.com/files/abc.jpg",
+			result.Thumbnails[0].Url: "https://media.example.com/files/thumbnails/abc-256.jpg",
+			result.ContentUrl:        "https://media.example.com/media/" + expectedMedia.ID.String() + "/content",
+		}
+		for signedURL, expectedURL := range expectedURLs {
+			parsed, err := url.Parse(signedURL)
+			require.NoError(t, err)
+			assert.Equal(t, expectedURL, strings.TrimSuffix(signedURL, "?"+parsed.RawQuery))
+			assert.NoError(t, signer.Verify(parsed.Path, parsed.Query()))
+		}
+	}
+}
+
 func Test_MediaController_Create_FailsIfNoFileIsProvided(t *testing.T) {
 	t.Parallel()
 
@@ -215,6 +628,7 @@ func Test_MediaController_Create_FailsIfNoFileIsProvided(t *testing.T) {
 	MediaController := MediaController{
 		TagService:   tagService,
 		MediaService: mediaService,
+		Kinds:        testKinds,
 	}
 
 	body := new(bytes.Buffer)
@@ -256,6 +670,7 @@ func Test_MediaController_Create_FailsIfUploadedFileIsNotAnImage(t *testing.T) {
 	MediaController := MediaController{
 		TagService:   tagService,
 		MediaService: mediaService,
+		Kinds:        testKinds,
 	}
 
 	body := new(bytes.Buffer)
@@ -290,7 +705,11 @@ func Test_MediaController_Create_FailsIfUploadedFileIsNotAnImage(t *testing.T) {
 
 	// the error handler middleware is not initialized here so the appropriate error response is not initialized
 	// checking that the error is in the stack instead
-	assert.Contains(t, context.Errors.Last().Err.Error(), "invalid file type for uploaded file,expected image")
+	var fileTypeError *apierrors.InvalidFileTypeError
+	if assert.ErrorAs(t, context.Errors.Last().Err, &fileTypeError) {
+		assert.Equal(t, "text/plain", fileTypeError.ClaimedType())
+		assert.Equal(t, "text/plain", fileTypeError.DetectedType())
+	}
 }
 
 func Test_MediaController_Create_FailsIfTagDoesNotExist(t *testing.T) {
@@ -302,24 +721,27 @@ func Test_MediaController_Create_FailsIfTagDoesNotExist(t *testing.T) {
 
 	tagService := mock_services.NewMockITagService(ctrl)
 	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
 
 	MediaController := MediaController{
-		TagService:   tagService,
-		MediaService: mediaService,
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
 	}
 
 	body := new(bytes.Buffer)
 	fileHeader := make(textproto.MIMEHeader)
 	multipartWriter := multipart.NewWriter(body)
 
-	// we are relying on the contentType header to determine if it's an image so we can just pass any file for testing
 	fileHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", "test.png"))
 	fileHeader.Set("Content-Type", "image/png")
 	fileWriter, err := multipartWriter.CreatePart(fileHeader)
 	if err != nil {
 		t.Error(err)
 	}
-	file, err := os.Open("test-resources/test.txt")
+	file, err := os.Open("test-resources/test.png")
 	if err != nil {
 		t.Error(err)
 	}
@@ -348,6 +770,7 @@ func Test_MediaController_Create_FailsIfTagDoesNotExist(t *testing.T) {
 	// the error handler middleware is not initialized here so the appropriate error response is not initialized
 	// checking that the error is in the stack instead
 	assert.Contains(t, context.Errors.Last().Err.Error(), "Tags with following IDs could not be found:")
+	assert.Empty(t, fileStore.Keys(), "the streamed file should be removed")
 }
 
 func Test_MediaController_Create_WritesCorrectOutput(t *testing.T) {
@@ -360,24 +783,27 @@ func Test_MediaController_Create_WritesCorrectOutput(t *testing.T) {
 	tagService := mock_services.NewMockITagService(ctrl)
 	mediaService := mock_services.NewMockIMediaService(ctrl)
 
+	fileStore := storage.NewMemoryFileStore()
+
 	MediaController := MediaController{
-		TagService:   tagService,
-		MediaService: mediaService,
-		IsTest:       false,
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
 	}
 
 	body := new(bytes.Buffer)
 	fileHeader := make(textproto.MIMEHeader)
 	multipartWriter := multipart.NewWriter(body)
 
-	// we are relying on the contentType header to determine if it's an image so we can just pass any file for testing
 	fileHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", "test.png"))
 	fileHeader.Set("Content-Type", "image/png")
 	fileWriter, err := multipartWriter.CreatePart(fileHeader)
 	if err != nil {
 		t.Error(err)
 	}
-	file, err := os.Open("test-resources/test.txt")
+	file, err := os.Open("test-resources/test.png")
 	if err != nil {
 		t.Error(err)
 	}
@@ -406,7 +832,13 @@ func Test_MediaController_Create_WritesCorrectOutput(t *testing.T) {
 	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
 		Return([]*domain.Tag{&expectedTag}, nil)
 
-	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	var createdMedia *domain.Media
+	expectNoDuplicates(mediaService)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
 	// act
 	MediaController.CreateMedia(context)
 
@@ -415,6 +847,1557 @@ func Test_MediaController_Create_WritesCorrectOutput(t *testing.T) {
 	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
 		assert.Equal(t, expectedName, result.Name)
 		assert.Equal(t, expectedTag.Name, result.Tags[0])
+		assert.Equal(t, "image/png", result.ContentType)
+		assert.Equal(t, "image", result.Kind)
+
+		expectedContent, err := os.ReadFile("test-resources/test.png")
+		if err != nil {
+			t.Error(err)
+		}
+		keys := fileStore.Keys()
+		if assert.Len(t, keys, 1) {
+			assert.Equal(t, createdMedia.StorageKey, keys[0])
+			assert.Equal(t, ".png", filepath.Ext(keys[0]), "the stored file should have the extension of the detected type")
+			expectedHash := sha256.Sum256(expectedContent)
+			assert.Equal(t, hex.EncodeToString(expectedHash[:])+".png", keys[0], "the stored file should be named after its content")
+			assert.Equal(t, hex.EncodeToString(expectedHash[:]), result.ContentHash)
+			assert.Nil(t, result.DuplicateOf)
+			assert.Len(t, result.PerceptualHash, 16, "a perceptual hash should be computed for images")
+			assert.Equal(t, expectedContent, fileStore.Content(keys[0]))
+			assert.True(t, strings.HasSuffix(result.FileUrl, "/files/"+keys[0]))
+		}
 	}
 
 }
+
+func Test_MediaController_Create_FailsIfFileCannotBeStored(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := mock_storage.NewMockFileStore(ctrl)
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	expectedError := errors.New("storage unavailable")
+	var temporaryKey string
+	fileStore.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, key string, _ io.Reader, _ int64) error {
+			temporaryKey = key
+			return expectedError
+		})
+	// a partially written file is removed
+	fileStore.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, key string) error {
+			assert.Equal(t, temporaryKey, key)
+			return nil
+		})
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	assert.ErrorIs(t, context.Errors.Last().Err, expectedError)
+}
+
+func Test_MediaController_Create_RemovesStoredFileIfMediaCannotBeCreated(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	expectedError := errors.New("database unavailable")
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	expectNoDuplicates(mediaService)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(expectedError)
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	assert.ErrorIs(t, context.Errors.Last().Err, expectedError)
+	assert.Empty(t, fileStore.Keys())
+}
+
+// testKinds only allows images like the PNG in test-resources
+var testKinds = map[domain.MediaKind]UploadRules{
+	domain.MediaKindImage: {
+		FileTypes: filetype.Allowlist{
+			MIMETypes:  []string{"image/*"},
+			Extensions: []string{".png", ".jpg"},
+		},
+	},
+}
+
+func Test_MediaController_Create_FailsIfUploadedFileIsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		MaxFileSize:       1,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {uuid.NewString()},
+	})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	assert.IsType(t, &apierrors.FileTooLargeError{}, context.Errors.Last().Err)
+	assert.Empty(t, fileStore.Keys())
+}
+
+func Test_MediaController_Create_RejectsRequestsAnnouncingATooLargeBody(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	MediaController := MediaController{
+		Kinds:          testKinds,
+		MaxRequestSize: 1024,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+	})
+	context.Request.ContentLength = 1025
+	// the body must not be read at all
+	context.Request.Body = io.NopCloser(iotest.ErrReader(errors.New("body was read")))
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	var tooLargeError *apierrors.RequestTooLargeError
+	if assert.ErrorAs(t, context.Errors.Last().Err, &tooLargeError) {
+		assert.Contains(t, tooLargeError.Error(), "maximum size is 1024 bytes")
+	}
+}
+
+func Test_MediaController_Create_StopsReadingBodiesOfUnknownSizeAtTheLimit(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	fileStore := storage.NewMemoryFileStore()
+	MediaController := MediaController{
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+		MaxRequestSize:    1024,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequestFor(t, "test-resources/test-exif.jpg", "photo.jpg", "image/jpeg", url.Values{
+		"name": {"expectedMedia"},
+	})
+	// chunked requests don't announce their size
+	context.Request.ContentLength = -1
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	assert.IsType(t, &apierrors.RequestTooLargeError{}, context.Errors.Last().Err)
+	assert.Empty(t, fileStore.Keys(), "the partially streamed file should be removed")
+}
+
+// notifyingFileStore closes received once the first bytes of a file reach the store
+type notifyingFileStore struct {
+	storage.FileStore
+	received chan struct{}
+	once     sync.Once
+}
+
+func (store *notifyingFileStore) Put(ctx gocontext.Context, key string, content io.Reader, size int64) error {
+	return store.FileStore.Put(ctx, key, &notifyingReader{reader: content, store: store}, size)
+}
+
+type notifyingReader struct {
+	reader io.Reader
+	store  *notifyingFileStore
+}
+
+func (reader *notifyingReader) Read(buffer []byte) (int, error) {
+	read, err := reader.reader.Read(buffer)
+	if read > 0 {
+		reader.store.once.Do(func() { close(reader.store.received) })
+	}
+	return read, err
+}
+
+// the default maximum upload size is only safe because files are never held in memory as a whole
+func Test_MediaController_Create_StreamsFilesBeforeTheBodyIsComplete(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := &notifyingFileStore{FileStore: storage.NewMemoryFileStore(), received: make(chan struct{})}
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds: map[domain.MediaKind]UploadRules{
+			domain.MediaKindDocument: {
+				FileTypes: filetype.Allowlist{MIMETypes: []string{"text/plain"}, Extensions: []string{".txt"}},
+			},
+		},
+	}
+
+	body, bodyWriter := io.Pipe()
+	multipartWriter := multipart.NewWriter(bodyWriter)
+	tag := domain.Tag{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "notes"}
+	streamed := make(chan bool, 1)
+	go func() {
+		chunk := bytes.Repeat([]byte("a"), 1<<20)
+		fileWriter, _ := multipartWriter.CreateFormFile("file", "notes.txt")
+		fileWriter.Write(chunk)
+		// the rest of the file is only sent once the start of it has been stored
+		select {
+		case <-fileStore.received:
+			streamed <- true
+		case <-time.After(5 * time.Second):
+			streamed <- false
+		}
+		fileWriter.Write(chunk)
+		multipartWriter.WriteField("name", "notes")
+		multipartWriter.WriteField("tags", tag.ID.String())
+		multipartWriter.Close()
+		bodyWriter.Close()
+	}()
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPost, "https://example.com", body)
+	context.Request.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{tag.ID}, gomock.Any()).Return([]*domain.Tag{&tag}, nil)
+	expectNoDuplicates(mediaService)
+	var createdMedia *domain.Media
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	assert.True(t, <-streamed, "the file should be stored while it is uploaded")
+	if assert.True(t, utils.RetrieveResponse(t, &restgen.Media{}, http.StatusCreated, writer.Result())) {
+		stored, err := fileStore.Stat(gocontext.Background(), createdMedia.StorageKey)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2<<20), stored.Size)
+	}
+}
+
+func Test_MediaController_Create_AcceptsFieldsBeforeTheFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	content, err := os.ReadFile("test-resources/test.png")
+	require.NoError(t, err)
+
+	body := new(bytes.Buffer)
+	multipartWriter := multipart.NewWriter(body)
+	multipartWriter.WriteField("name", "expectedMedia")
+	multipartWriter.WriteField("tags", expectedTag.ID.String())
+	fileWriter, err := multipartWriter.CreateFormFile("file", "test.png")
+	require.NoError(t, err)
+	fileWriter.Write(content)
+	multipartWriter.Close()
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request, err = http.NewRequest(http.MethodPost, "https://example.com", body)
+	require.NoError(t, err)
+	context.Request.Header.Add("Content-Type", multipartWriter.FormDataContentType())
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	expectNoDuplicates(mediaService)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	sum := sha256.Sum256(content)
+	storedKey := hex.EncodeToString(sum[:]) + ".png"
+	assert.Equal(t, content, fileStore.Content(storedKey))
+	for _, key := range fileStore.Keys() {
+		assert.NotContains(t, key, temporaryUploadPrefix, "the temporary file should be moved")
+	}
+}
+
+func Test_MediaController_Update_WritesCorrectOutput(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	existingMedia := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "before",
+		Tags: []*domain.Tag{{Name: "existingTag"}},
+	}
+	expectedName := "after"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetOwnedWithID(gomock.Any(), existingMedia.ID, gomock.Any()).Return(&existingMedia, nil)
+	mediaService.EXPECT().Update(gomock.Any(), &existingMedia).Return(nil)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodPatch, "https://example.com", strings.NewReader(`{"name":"after"}`))
+	if err != nil {
+		t.Error(err)
+	}
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: existingMedia.ID.String()})
+
+	// act
+	MediaController.UpdateMedia(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Equal(t, expectedName, result.Name)
+		assert.Equal(t, []string{"existingTag"}, result.Tags)
+	}
+}
+
+func Test_MediaController_Replace_WritesCorrectOutput(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	existingMedia := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "before",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetOwnedWithID(gomock.Any(), existingMedia.ID, gomock.Any()).Return(&existingMedia, nil)
+	mediaService.EXPECT().Update(gomock.Any(), &existingMedia).Return(nil)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPut, "https://example.com", strings.NewReader(`{"name":"after"}`))
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: existingMedia.ID.String()})
+
+	// act
+	MediaController.ReplaceMedia(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Equal(t, "after", result.Name)
+	}
+}
+
+func Test_MediaController_Replace_FailsWithoutName(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPut, "https://example.com", strings.NewReader(`{}`))
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: uuid.NewString()})
+
+	// act
+	MediaController.ReplaceMedia(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.RequiredValueMissingError{}, context.Errors.Last().Err)
+	}
+}
+
+func Test_MediaController_Delete_RemovesStoredFile(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	existingMedia := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name:       "media",
+		StorageKey: "stored.png",
+		Thumbnails: []*domain.Thumbnail{{StorageKey: "thumbnails/stored-256.jpg"}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().DeleteWithFiles(gomock.Any(), existingMedia.ID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, _ uuid.UUID, remove services.RemoveFiles, _ ...services.Option[domain.Media]) error {
+			// the files are no longer referred to by any media
+			remove(storageKeys(&existingMedia)...)
+			return nil
+		})
+
+	fileStore := storage.NewMemoryFileStore()
+	for _, key := range []string{"stored.png", "thumbnails/stored-256.jpg"} {
+		if err := fileStore.Put(gocontext.Background(), key, strings.NewReader("content"), 7); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := fileStore.Put(gocontext.Background(), "other.png", strings.NewReader("content"), 7); err != nil {
+		t.Error(err)
+	}
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+		FileStore:    fileStore,
+		Kinds:        testKinds,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodDelete, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: existingMedia.ID.String()})
+
+	// act
+	MediaController.DeleteMedia(context)
+	context.Writer.WriteHeaderNow()
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	assert.Equal(t, []string{"other.png"}, fileStore.Keys())
+}
+
+func Test_MediaController_Delete_KeepsFileIfMediaCannotBeDeleted(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	existingMedia := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name:       "media",
+		StorageKey: "stored.png",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expectedError := errors.New("database unavailable")
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().DeleteWithFiles(gomock.Any(), existingMedia.ID, gomock.Any(), gomock.Any()).Return(expectedError)
+
+	fileStore := mock_storage.NewMockFileStore(ctrl)
+	fileStore.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+		FileStore:    fileStore,
+		Kinds:        testKinds,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodDelete, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: existingMedia.ID.String()})
+
+	// act
+	MediaController.DeleteMedia(context)
+
+	// Assert
+	assert.ErrorIs(t, context.Errors.Last().Err, expectedError)
+}
+
+func Test_MediaController_Delete_ReportsMediaOfOthersAsForbidden(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	id := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().DeleteWithFiles(gomock.Any(), id, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFoundError(id))
+	mediaService.EXPECT().GetOwnedWithID(gomock.Any(), id).Return(nil, apierrors.NewForbiddenError("not the owner"))
+
+	fileStore := mock_storage.NewMockFileStore(ctrl)
+	fileStore.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+		FileStore:    fileStore,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodDelete, "https://example.com", nil)
+	principal := &auth.Principal{Subject: "apikey:other", Role: auth.RoleUploader}
+	context.Request = context.Request.WithContext(auth.WithPrincipal(context.Request.Context(), principal))
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: id.String()})
+
+	// act
+	MediaController.DeleteMedia(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.ForbiddenError{}, context.Errors.Last().Err)
+	}
+}
+
+func Test_MediaController_AddTags_FailsIfTagDoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+
+	MediaController := MediaController{
+		TagService:   tagService,
+		MediaService: mediaService,
+	}
+
+	missingTagID := uuid.New()
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{missingTagID}, gomock.Any()).
+		Return(nil, apierrors.NewRecordsNotFoundWithIDs([]uuid.UUID{missingTagID}))
+	mediaService.EXPECT().AddTags(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodPost, "https://example.com", strings.NewReader(fmt.Sprintf(`{"tags":["%s"]}`, missingTagID)))
+	if err != nil {
+		t.Error(err)
+	}
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: uuid.NewString()})
+
+	// act
+	MediaController.AddMediaTags(context)
+
+	// Assert
+	assert.IsType(t, &apierrors.InvalidTagsError{}, context.Errors.Last().Err)
+	assert.ErrorContains(t, context.Errors.Last().Err, missingTagID.String())
+}
+
+func Test_MediaController_ReplaceTags_FailsWithoutTags(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().ReplaceTags(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	MediaController := MediaController{
+		TagService:   mock_services.NewMockITagService(ctrl),
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPut, "https://example.com", strings.NewReader(`{"tags":[]}`))
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: uuid.NewString()})
+
+	// act
+	MediaController.ReplaceMediaTags(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.RequiredValueMissingError{}, context.Errors.Last().Err)
+	}
+}
+
+func Test_MediaController_ReplaceTags_WritesCorrectOutput(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	newTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "newTag",
+	}
+	media := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "media",
+		Tags: []*domain.Tag{{Name: "oldTag"}},
+	}
+	replacedMedia := media
+	replacedMedia.Tags = []*domain.Tag{&newTag}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+
+	MediaController := MediaController{
+		TagService:   tagService,
+		MediaService: mediaService,
+	}
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{newTag.ID}, gomock.Any()).Return([]*domain.Tag{&newTag}, nil)
+	gomock.InOrder(
+		mediaService.EXPECT().GetOwnedWithID(gomock.Any(), media.ID, gomock.Any()).Return(&media, nil),
+		mediaService.EXPECT().ReplaceTags(gomock.Any(), &media, []*domain.Tag{&newTag}).Return(nil),
+		mediaService.EXPECT().GetWithID(gomock.Any(), media.ID, gomock.Any()).Return(&replacedMedia, nil),
+	)
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodPut, "https://example.com", strings.NewReader(fmt.Sprintf(`{"tags":["%s"]}`, newTag.ID)))
+	if err != nil {
+		t.Error(err)
+	}
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: media.ID.String()})
+
+	// act
+	MediaController.ReplaceMediaTags(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Equal(t, []string{newTag.Name}, result.Tags)
+	}
+}
+
+func Test_MediaController_RemoveTag_WritesNoContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tagID := uuid.New()
+	media := domain.Media{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "media",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetOwnedWithID(gomock.Any(), media.ID, gomock.Any()).Return(&media, nil)
+	mediaService.EXPECT().RemoveTag(gomock.Any(), &media, tagID).Return(nil)
+
+	MediaController := MediaController{
+		MediaService: mediaService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodDelete, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	context.Params = append(context.Params,
+		gin.Param{Key: "id", Value: media.ID.String()},
+		gin.Param{Key: "tagId", Value: tagID.String()},
+	)
+
+	// act
+	MediaController.RemoveMediaTag(context)
+	context.Writer.WriteHeaderNow()
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	assert.Empty(t, context.Errors)
+}
+
+func Test_MediaController_Create_FailsIfUploadedFileIsTooLargeForItsKind(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		MaxFileSize:       1 << 20,
+		Kinds: map[domain.MediaKind]UploadRules{
+			domain.MediaKindImage: {
+				MaxFileSize: 1,
+				FileTypes:   testKinds[domain.MediaKindImage].FileTypes,
+			},
+		},
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {uuid.NewString()},
+	})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	var tooLargeError *apierrors.FileTooLargeError
+	if assert.ErrorAs(t, context.Errors.Last().Err, &tooLargeError) {
+		assert.Contains(t, tooLargeError.Error(), "maximum size is 1 bytes")
+	}
+	assert.Empty(t, fileStore.Keys())
+}
+
+func Test_MediaController_Create_StoresDocumentsWithTheirKind(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds: map[domain.MediaKind]UploadRules{
+			domain.MediaKindImage: testKinds[domain.MediaKindImage],
+			domain.MediaKindDocument: {
+				FileTypes: filetype.Allowlist{
+					MIMETypes:  []string{"text/plain", "application/pdf"},
+					Extensions: []string{".txt", ".pdf"},
+				},
+			},
+		},
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequestFor(t, "test-resources/test.txt", "notes.txt", "text/plain", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	var createdMedia *domain.Media
+	expectNoDuplicates(mediaService)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
+		assert.Equal(t, domain.MediaKindDocument, createdMedia.Kind)
+		assert.Equal(t, "document", result.Kind)
+		assert.Equal(t, "text/plain", result.ContentType)
+		assert.Equal(t, ".txt", filepath.Ext(createdMedia.StorageKey))
+	}
+}
+
+func Test_MediaController_Create_StoresThumbnails(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+		Thumbnails:        thumbnail.Generator{Sizes: []int{2, 64}, Quality: 80},
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	var createdMedia *domain.Media
+	expectNoDuplicates(mediaService)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
+		name := strings.TrimSuffix(createdMedia.StorageKey, ".png")
+		assert.ElementsMatch(t, []string{
+			createdMedia.StorageKey,
+			"thumbnails/" + name + "-2" + filepath.Ext(createdMedia.Thumbnails[0].StorageKey),
+			"thumbnails/" + name + "-64" + filepath.Ext(createdMedia.Thumbnails[1].StorageKey),
+		}, fileStore.Keys())
+
+		if assert.Len(t, result.Thumbnails, 2) {
+			assert.Equal(t, int32(2), result.Thumbnails[0].Size)
+			assert.Equal(t, int32(2), result.Thumbnails[0].Width)
+			assert.Equal(t, int32(64), result.Thumbnails[1].Size)
+			assert.Equal(t, int32(4), result.Thumbnails[1].Width, "the 4x4 test image should not be scaled up")
+			assert.True(t, strings.HasSuffix(result.Thumbnails[0].Url, "/files/"+createdMedia.Thumbnails[0].StorageKey))
+		}
+
+		if assert.NotNil(t, result.Metadata) {
+			assert.Equal(t, int32(4), *result.Metadata.Width)
+			assert.Equal(t, int32(4), *result.Metadata.Height)
+			assert.Nil(t, result.Metadata.TakenAt)
+		}
+	}
+}
+
+func Test_MediaController_Create_SkipsThumbnailsOfUndecodableImages(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+		// the test image has 16 pixels
+		Thumbnails: thumbnail.Generator{Sizes: []int{2}, Quality: 80, MaxSourcePixels: 15},
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	expectNoDuplicates(mediaService)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
+		assert.Empty(t, result.Thumbnails)
+		assert.Len(t, fileStore.Keys(), 1)
+	}
+}
+
+func Test_MediaController_Create_StripsMetadataFromStoredImages(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		privacy          metadata.Policy
+		recordStripped   bool
+		strip            []string
+		expectStripped   bool
+		expectedRecorded bool
+	}{
+		"stored as uploaded without a policy": {},
+		"stripped by the configured policy": {
+			privacy:          metadata.Policy{Location: true},
+			recordStripped:   true,
+			expectStripped:   true,
+			expectedRecorded: true,
+		},
+		"stripped on request": {
+			strip:            []string{"location"},
+			recordStripped:   true,
+			expectStripped:   true,
+			expectedRecorded: true,
+		},
+		"stripped without recording": {
+			privacy:        metadata.Policy{Location: true},
+			expectStripped: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tagService := mock_services.NewMockITagService(ctrl)
+			mediaService := mock_services.NewMockIMediaService(ctrl)
+			fileStore := storage.NewMemoryFileStore()
+
+			MediaController := MediaController{
+				TagService:        tagService,
+				MediaService:      mediaService,
+				FileStore:         fileStore,
+				StoredFileService: newStoredFileService(t),
+				Kinds:             testKinds,
+				Privacy:           testCase.privacy,
+				RecordStripped:    testCase.recordStripped,
+			}
+
+			expectedTag := domain.Tag{
+				BaseObject: domain.BaseObject{
+					ID: uuid.New(),
+				},
+				Name: "expectedTag",
+			}
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = newUploadRequestFor(t, "test-resources/test-exif.jpg", "photo.jpg", "image/jpeg", url.Values{
+				"name":  {"expectedMedia"},
+				"tags":  {expectedTag.ID.String()},
+				"strip": testCase.strip,
+			})
+
+			tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+				Return([]*domain.Tag{&expectedTag}, nil)
+			var createdMedia *domain.Media
+			expectNoDuplicates(mediaService)
+			mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+					createdMedia = media[0]
+					return nil
+				})
+
+			// act
+			MediaController.CreateMedia(context)
+
+			// Assert
+			var result restgen.Media
+			if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
+				stored, err := metadata.Extract(bytes.NewReader(fileStore.Content(createdMedia.StorageKey)), "image/jpeg")
+				if assert.NoError(t, err) {
+					assert.Equal(t, testCase.expectStripped, stored.Latitude == nil)
+					assert.Equal(t, "Canon", *stored.CameraMake, "only the location should be stripped")
+				}
+
+				assert.Equal(t, testCase.expectStripped, result.Metadata.Latitude == nil, "stripped fields should not be exposed")
+				assert.Equal(t, "Canon", *result.Metadata.CameraMake)
+				assert.Equal(t, testCase.expectStripped, createdMedia.Metadata.LocationStripped)
+				assert.Equal(t, testCase.expectedRecorded || !testCase.expectStripped, createdMedia.Metadata.Latitude != nil)
+			}
+		})
+	}
+}
+
+func Test_MediaController_Create_FailsIfStrippedBlockIsUnknown(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	fileStore := storage.NewMemoryFileStore()
+	MediaController := MediaController{
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t),
+		Kinds:             testKinds,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequestFor(t, "test-resources/test-exif.jpg", "photo.jpg", "image/jpeg", url.Values{
+		"name":  {"expectedMedia"},
+		"strip": {"faces"},
+	})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+		assert.Contains(t, context.Errors.Last().Err.Error(), "strip")
+	}
+	assert.Empty(t, fileStore.Keys(), "the streamed file should be removed")
+}
+
+// existingUpload returns media created from the test image along with a file store holding its files
+func existingUpload(t *testing.T) (*domain.Media, *storage.MemoryFileStore) {
+	t.Helper()
+
+	content, err := os.ReadFile("test-resources/test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(content)
+	media := &domain.Media{
+		BaseObject:  domain.BaseObject{ID: uuid.New()},
+		Name:        "existing",
+		StorageKey:  hex.EncodeToString(hash[:]) + ".png",
+		ContentHash: hex.EncodeToString(hash[:]),
+		Thumbnails: []*domain.Thumbnail{{
+			BaseObject: domain.BaseObject{ID: uuid.New()},
+			Size:       256,
+			Width:      4,
+			Height:     4,
+			StorageKey: "thumbnails/" + hex.EncodeToString(hash[:]) + "-256.png",
+		}},
+	}
+
+	fileStore := storage.NewMemoryFileStore()
+	for _, key := range storageKeys(media) {
+		if err := fileStore.Put(gocontext.Background(), key, strings.NewReader("content"), 7); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return media, fileStore
+}
+
+func Test_MediaController_Create_LinksDuplicates(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	existing, fileStore := existingUpload(t)
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t, storageKeys(existing)...),
+		Kinds:             testKinds,
+		Thumbnails:        thumbnail.Generator{Sizes: []int{2}, Quality: 80},
+		Duplicates:        LinkDuplicates,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	mediaService.EXPECT().FilterByContentHashOption(existing.ContentHash).Return(nil)
+	mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]*domain.Media{existing}, nil)
+	var createdMedia *domain.Media
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	var result restgen.Media
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
+		if assert.NotNil(t, result.DuplicateOf) {
+			assert.Equal(t, existing.ID.String(), *result.DuplicateOf)
+		}
+		assert.Equal(t, existing.StorageKey, createdMedia.StorageKey)
+		assert.ElementsMatch(t, storageKeys(existing), fileStore.Keys(), "no files should be added")
+		assert.Equal(t, []byte("content"), fileStore.Content(existing.StorageKey), "the shared file should not be rewritten")
+
+		if assert.Len(t, createdMedia.Thumbnails, 1) {
+			assert.Equal(t, existing.Thumbnails[0].StorageKey, createdMedia.Thumbnails[0].StorageKey)
+			assert.Equal(t, uuid.Nil, createdMedia.Thumbnails[0].ID, "the thumbnails of the existing media should be copied")
+		}
+	}
+}
+
+func Test_MediaController_Create_RejectsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	existing, fileStore := existingUpload(t)
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t, storageKeys(existing)...),
+		Kinds:             testKinds,
+		Duplicates:        RejectDuplicates,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	mediaService.EXPECT().FilterByContentHashOption(existing.ContentHash).Return(nil)
+	mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]*domain.Media{existing}, nil)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		var duplicateError *apierrors.DuplicateMediaError
+		if assert.ErrorAs(t, context.Errors.Last().Err, &duplicateError) {
+			assert.Equal(t, existing.ID, duplicateError.ExistingID)
+		}
+	}
+	assert.ElementsMatch(t, storageKeys(existing), fileStore.Keys())
+}
+
+func Test_MediaController_Create_KeepsSharedFilesIfMediaCannotBeCreated(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	existing, fileStore := existingUpload(t)
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		FileStore:         fileStore,
+		StoredFileService: newStoredFileService(t, storageKeys(existing)...),
+		Kinds:             testKinds,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	expectedError := errors.New("database unavailable")
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	mediaService.EXPECT().FilterByContentHashOption(existing.ContentHash).Return(nil)
+	mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]*domain.Media{existing}, nil)
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(expectedError)
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	assert.ErrorIs(t, context.Errors.Last().Err, expectedError)
+	assert.ElementsMatch(t, storageKeys(existing), fileStore.Keys(), "the files of the existing media should be kept")
+}
+
+func Test_MediaController_Create_RegeneratesRemovedSharedThumbnails(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	existing, fileStore := existingUpload(t)
+	// the existing media was deleted along with its files right after it was found
+	for _, key := range storageKeys(existing) {
+		require.NoError(t, fileStore.Delete(gocontext.Background(), key))
+	}
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+
+	MediaController := MediaController{
+		TagService:        tagService,
+		MediaService:      mediaService,
+		StoredFileService: newStoredFileService(t),
+		FileStore:         fileStore,
+		Kinds:             testKinds,
+		Thumbnails:        thumbnail.Generator{Sizes: []int{2}, Quality: 80},
+		Duplicates:        LinkDuplicates,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newUploadRequest(t, "test.png", "image/png", url.Values{
+		"name": {"expectedMedia"},
+		"tags": {expectedTag.ID.String()},
+	})
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	mediaService.EXPECT().FilterByContentHashOption(existing.ContentHash).Return(nil)
+	mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]*domain.Media{existing}, nil)
+	var createdMedia *domain.Media
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
+
+	// act
+	MediaController.CreateMedia(context)
+
+	// Assert
+	if assert.True(t, utils.RetrieveResponse(t, &restgen.Media{}, http.StatusCreated, writer.Result())) {
+		if assert.Len(t, createdMedia.Thumbnails, 1) {
+			assert.Equal(t, 2, createdMedia.Thumbnails[0].Size)
+		}
+		assert.ElementsMatch(t, storageKeys(createdMedia), fileStore.Keys(), "the files should be stored again")
+	}
+}
+
+func Test_MediaController_GetSimilar_ListsSimilarMedia(t *testing.T) {
+	t.Parallel()
+
+	hash := int64(-0x0123_4567_89AB_CDEF)
+	media := &domain.Media{
+		BaseObject:     domain.BaseObject{ID: uuid.New()},
+		Name:           "media",
+		PerceptualHash: &hash,
+	}
+	similar := &domain.Media{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "similar"}
+	other := &domain.Media{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "other"}
+
+	testCases := map[string]struct {
+		media               *domain.Media
+		maxDistance         string
+		limit               string
+		expectedMaxDistance int
+		expectedLimit       int
+		expectedItems       []string
+		expectedError       bool
+	}{
+		"default distance": {media: media, expectedMaxDistance: 8, expectedLimit: 26, expectedItems: []string{"similar", "other"}},
+		"given distance":   {media: media, maxDistance: "0", expectedMaxDistance: 0, expectedLimit: 26, expectedItems: []string{"similar", "other"}},
+		"given limit":      {media: media, limit: "1", expectedMaxDistance: 8, expectedLimit: 2, expectedItems: []string{"similar"}},
+		"without a hash":   {media: &domain.Media{BaseObject: media.BaseObject}, expectedItems: []string{}},
+		"negative":         {media: media, maxDistance: "-1", expectedError: true},
+		"too large":        {media: media, maxDistance: "17", expectedError: true},
+		"not a number":     {media: media, maxDistance: "close", expectedError: true},
+		"no limit":         {media: media, limit: "0", expectedError: true},
+		"limit too large":  {media: media, limit: "101", expectedError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mediaService := mock_services.NewMockIMediaService(ctrl)
+			if !testCase.expectedError {
+				mediaService.EXPECT().GetWithID(gomock.Any(), media.ID, gomock.Any()).Return(testCase.media, nil)
+			}
+			if testCase.media.PerceptualHash != nil && !testCase.expectedError {
+				mediaService.EXPECT().GetSimilar(gomock.Any(), uint64(hash), testCase.expectedMaxDistance, testCase.expectedLimit).Return([]*domain.SimilarMedia{
+					{Media: media, Distance: 0},
+					{Media: similar, Distance: 3},
+					{Media: other, Distance: 5},
+				}, nil)
+			}
+
+			MediaController := MediaController{
+				MediaService: mediaService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodGet, "https://example.com?maxDistance="+testCase.maxDistance+"&limit="+testCase.limit, nil)
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: media.ID.String()})
+
+			// act
+			MediaController.GetSimilarMedia(context)
+
+			// Assert
+			if testCase.expectedError {
+				if assert.NotEmpty(t, context.Errors) {
+					assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+				}
+				return
+			}
+
+			var result restgen.SimilarMediaList
+			if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+				names := []string{}
+				for _, item := range result.Items {
+					names = append(names, item.Media.Name)
+				}
+				assert.Equal(t, testCase.expectedItems, names, "the media itself should not be listed")
+				if len(result.Items) > 0 {
+					assert.Equal(t, int32(3), result.Items[0].Distance)
+				}
+			}
+		})
+	}
+}
+
+// newUploadRequest builds a multipart media upload containing test-resources/test.png as the file
+func newUploadRequest(t *testing.T, filename string, contentType string, fields url.Values) *http.Request {
+	return newUploadRequestFor(t, "test-resources/test.png", filename, contentType, fields)
+}
+
+// newUploadRequestFor builds a multipart media upload containing the test resource as the file
+func newUploadRequestFor(t *testing.T, resource string, filename string, contentType string, fields url.Values) *http.Request {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	multipartWriter := multipart.NewWriter(body)
+
+	fileHeader := make(textproto.MIMEHeader)
+	fileHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, "file", filename))
+	fileHeader.Set("Content-Type", contentType)
+	fileWriter, err := multipartWriter.CreatePart(fileHeader)
+	if err != nil {
+		t.Error(err)
+	}
+	content, err := os.ReadFile(resource)
+	if err != nil {
+		t.Error(err)
+	}
+	fileWriter.Write(content)
+
+	for key, values := range fields {
+		for _, value := range values {
+			multipartWriter.WriteField(key, value)
+		}
+	}
+	multipartWriter.Close()
+
+	request, err := http.NewRequest(http.MethodPost, "https://example.com", body)
+	if err != nil {
+		t.Error(err)
+	}
+	request.Header.Add("Content-Type", multipartWriter.FormDataContentType())
+
+	return request
+}
+
+// expectNoDuplicates makes the media service find no media with the same content as an upload
+func expectNoDuplicates(mediaService *mock_services.MockIMediaService) {
+	mediaService.EXPECT().FilterByContentHashOption(gomock.Any()).Return(nil)
+	mediaService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil)
+}
+
+// newStoredFileService counts the references to stored files in memory, the keys are referred to once already
+func newStoredFileService(t *testing.T, keys ...string) services.IStoredFileService {
+	t.Helper()
+
+	service := services.NewStoredFileService(utils.NewInMemoryDatabase(t))
+	require.NoError(t, service.Acquire(gocontext.Background(), keys...))
+	return service
+}
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/filetype"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxFieldSize is the maximum size in bytes of a single form field of an upload other than the file
+const maxFieldSize = 64 << 10
+
+// temporaryUploadPrefix is the prefix of the keys files are streamed to before their content hash is known
+const temporaryUploadPrefix = "uploads/"
+
+// mediaUpload is an upload whose file has been streamed to a temporary key of the file store
+type mediaUpload struct {
+	// ID is the ID the media is created with, a new one is generated when it is uuid.Nil
+	ID    uuid.UUID
+	Name  string
+	Tags  []string
+	Strip []string
+	// TemporaryKey holds the uploaded file until it is stored under the key derived from its content, empty if the
+	// upload has no file
+	TemporaryKey string
+	Size         int64
+	// Hash is the hex encoded SHA-256 of the uploaded file
+	Hash     string
+	Detected *filetype.Type
+	Kind     domain.MediaKind
+	// Owner is the subject of the principal the media is created for
+	Owner string
+}
+
+// readUpload parses the multipart form of an upload, streaming the file to the file store while it is read so it
+// is never buffered. The size, type and hash of the file are checked on the fly. The temporary file is removed
+// when reading fails, otherwise the caller is responsible for it
+func (controller *MediaController) readUpload(c *gin.Context) (_ *mediaUpload, err error) {
+	ctx := c.Request.Context()
+
+	if controller.MaxRequestSize > 0 {
+		if c.Request.ContentLength > controller.MaxRequestSize {
+			return nil, apierrors.NewRequestTooLargeError(controller.MaxRequestSize)
+		}
+		// chunked requests don't announce their size, they fail once too much has been read
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, controller.MaxRequestSize)
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if errors.Is(err, http.ErrNotMultipart) {
+		// a request without a multipart form can't contain a file
+		return nil, apierrors.NewRequiredValueMissingError("file")
+	}
+	if err != nil {
+		return nil, apierrors.NewInvalidParameterError("Content-Type", err.Error())
+	}
+
+	upload := &mediaUpload{Owner: auth.SubjectFrom(c.Request.Context())}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if upload.TemporaryKey != "" {
+			controller.removeFiles(ctx, upload.TemporaryKey)
+		}
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			err = apierrors.NewRequestTooLargeError(maxBytesError.Limit)
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return upload, nil
+		}
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, apierrors.NewInvalidParameterError("body", err.Error())
+		}
+
+		err = controller.readPart(ctx, part, upload)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readPart reads a single field of the form into the upload, unknown fields are skipped
+func (controller *MediaController) readPart(ctx context.Context, part *multipart.Part, upload *mediaUpload) error {
+	if part.FormName() == "file" {
+		if upload.TemporaryKey != "" {
+			return apierrors.NewInvalidParameterError("file", "only a single file can be uploaded")
+		}
+		return controller.streamFile(ctx, part, part.Header.Get("Content-Type"), part.FileName(), upload)
+	}
+
+	var target *[]string
+	switch part.FormName() {
+	case "name":
+		value, err := readField(part)
+		upload.Name = value
+		return err
+	case "tags":
+		target = &upload.Tags
+	case "strip":
+		target = &upload.Strip
+	default:
+		return nil
+	}
+
+	value, err := readField(part)
+	if err != nil {
+		return err
+	}
+	*target = append(*target, value)
+	return nil
+}
+
+// readField reads the value of a form field other than the file
+func readField(part *multipart.Part) (string, error) {
+	var value strings.Builder
+	read, err := io.Copy(&value, io.LimitReader(part, maxFieldSize+1))
+	if err != nil {
+		return "", err
+	}
+	if read > maxFieldSize {
+		return "", apierrors.NewInvalidParameterError(part.FormName(), fmt.Sprintf("value exceeds %d bytes", maxFieldSize))
+	}
+
+	return value.String(), nil
+}
+
+// streamFile detects the type of the file from its first bytes and streams it to a temporary key, hashing it
+// along the way. Uploads of types that are not allowed are rejected before anything is stored. contentType and
+// filename are the type and name the client claims the file has
+func (controller *MediaController) streamFile(
+	ctx context.Context,
+	file io.Reader,
+	contentType string,
+	filename string,
+	upload *mediaUpload,
+) error {
+	content := &sizeLimitedReader{reader: file, maxSize: controller.MaxFileSize}
+
+	// the Content-Type of the part is chosen by the client, the content itself decides the type
+	detected, detectedContent, err := filetype.Detect(content)
+	if err != nil {
+		return content.failure(err)
+	}
+	claimed := filetype.Claimed(contentType, filename)
+	kind, rules, err := controller.rulesFor(detected)
+	if err != nil {
+		return apierrors.NewInvalidFileTypeError(claimed, detected.MIMEType, err.Error())
+	}
+	if err := rules.FileTypes.Check(detected, claimed, filename); err != nil {
+		return apierrors.NewInvalidFileTypeError(claimed, detected.MIMEType, err.Error())
+	}
+	if rules.MaxFileSize > 0 && (content.maxSize == 0 || rules.MaxFileSize < content.maxSize) {
+		content.maxSize = rules.MaxFileSize
+	}
+
+	hash := sha256.New()
+	upload.TemporaryKey = temporaryUploadPrefix + uuid.NewString()
+	if err := controller.FileStore.Put(ctx, upload.TemporaryKey, io.TeeReader(detectedContent, hash), -1); err != nil {
+		return content.failure(err)
+	}
+
+	upload.Size = content.read
+	upload.Hash = hex.EncodeToString(hash.Sum(nil))
+	upload.Detected = detected
+	upload.Kind = kind
+	return nil
+}
+
+// sizeLimitedReader fails with a FileTooLargeError once more than maxSize bytes are read, 0 means no limit.
+// The first error of the underlying reader is kept since file stores don't necessarily return it unchanged
+type sizeLimitedReader struct {
+	reader  io.Reader
+	maxSize int64
+	read    int64
+	err     error
+}
+
+func (content *sizeLimitedReader) Read(buffer []byte) (int, error) {
+	read, err := content.reader.Read(buffer)
+	content.read += int64(read)
+	if content.maxSize > 0 && content.read > content.maxSize {
+		err = apierrors.NewFileTooLargeError(content.maxSize)
+	}
+	if err != nil && !errors.Is(err, io.EOF) && content.err == nil {
+		content.err = err
+	}
+	return read, err
+}
+
+// failure returns the error that made reading the upload fail, falling back to the error of the consumer
+func (content *sizeLimitedReader) failure(err error) error {
+	if content.err != nil {
+		return content.err
+	}
+	return err
+}
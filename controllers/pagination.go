@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/services"
+)
+
+// paginationInput holds the query parameters shared by all paginated list endpoints
+type paginationInput struct {
+	Limit  string `form:"limit"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+	Order  string `form:"order"`
+}
+
+// toPagination validates the query parameters against the fields the endpoint can be sorted on
+func (input paginationInput) toPagination(sortFields ...services.SortField) (services.Pagination, error) {
+	pagination := services.Pagination{
+		Limit: services.DefaultPageSize,
+		Sort:  services.SortByCreatedAt,
+	}
+
+	if input.Limit != "" {
+		limit, err := strconv.Atoi(input.Limit)
+		if err != nil || limit < 1 || limit > services.MaxPageSize {
+			return pagination, apierrors.NewInvalidParameterError("limit", fmt.Sprintf("expected a number between 1 and %d", services.MaxPageSize))
+		}
+		pagination.Limit = limit
+	}
+
+	if input.Sort != "" {
+		found := false
+		for _, field := range sortFields {
+			if field.Name == input.Sort {
+				pagination.Sort = field
+				found = true
+			}
+		}
+		if !found {
+			return pagination, apierrors.NewInvalidParameterError("sort", fmt.Sprintf("cannot sort on %s", input.Sort))
+		}
+	}
+
+	switch input.Order {
+	case "", "asc":
+	case "desc":
+		pagination.Descending = true
+	default:
+		return pagination, apierrors.NewInvalidParameterError("order", "expected asc or desc")
+	}
+
+	if input.Cursor != "" {
+		cursor, err := services.DecodeCursor(input.Cursor, pagination.Sort, pagination.Descending)
+		if err != nil {
+			return pagination, apierrors.NewInvalidParameterError("cursor", "the cursor is invalid or does not match the sort and order")
+		}
+		pagination.Cursor = cursor
+	}
+
+	return pagination, nil
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/rendercache"
+	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/thumbnail"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RenderController struct {
+	MediaService services.IMediaService
+	FileStore    storage.FileStore
+	// Renderer decodes and encodes the images, its quality applies to renditions as well
+	Renderer thumbnail.Generator
+	// Cache keeps rendered images so they are only rendered once, nil disables caching
+	Cache *rendercache.DiskCache
+	// MaxDimension is the largest accepted width and height
+	MaxDimension int
+}
+
+// RenderMedia resizes, crops and converts a stored image according to the w, h, fit and format query parameters
+func (controller *RenderController) RenderMedia(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+
+	options, err := controller.renderOptions(c)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("invalid render parameters")
+		return
+	}
+
+	media, err := controller.MediaService.GetWithID(ctx, id)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed retrieving media to render")
+		return
+	}
+
+	key, content, err := controller.render(ctx, media, options)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed rendering media")
+		return
+	}
+
+	// the same parameters always result in the same rendition of a stored file
+	c.Header("ETag", fmt.Sprintf(`"%s"`, key))
+	http.ServeContent(c.Writer, c.Request, "", media.UpdatedAt, bytes.NewReader(content))
+}
+
+// render returns the cache key and the content of the rendition, rendering it if it isn't cached yet
+func (controller *RenderController) render(ctx context.Context, media *domain.Media, options thumbnail.RenderOptions) (string, []byte, error) {
+	logger := utils.NewLogger(ctx).WithField("key", media.StorageKey)
+
+	if !controller.Renderer.Decodes(media.ContentType) {
+		return "", nil, apierrors.NewNotRenderableError(media.ID, fmt.Sprintf("images of type %s can't be rendered", media.ContentType))
+	}
+
+	key := rendercache.Key(media.StorageKey, options.Width, options.Height, options.Fit, options.Format, controller.Renderer.Quality)
+	if controller.Cache != nil {
+		content, found, err := controller.Cache.Get(key)
+		if err != nil {
+			logger.WithError(err).Warn("failed reading rendered image from the cache")
+		} else if found {
+			return key, content, nil
+		}
+	}
+
+	file, err := controller.FileStore.Get(ctx, media.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			err = apierrors.NewFileNotFoundError(media.StorageKey)
+		}
+		return "", nil, err
+	}
+	defer file.Close()
+
+	rendered, err := controller.Renderer.Render(file, media.ContentType, options)
+	if errors.Is(err, thumbnail.ErrTooManyPixels) {
+		return "", nil, apierrors.NewNotRenderableError(media.ID, err.Error())
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if controller.Cache != nil {
+		// a rendition that can't be cached is still returned
+		if err := controller.Cache.Put(key, rendered.Content); err != nil {
+			logger.WithError(err).Warn("failed caching rendered image")
+		}
+	}
+
+	return key, rendered.Content, nil
+}
+
+// renderOptions validates the query parameters of a render request
+func (controller *RenderController) renderOptions(c *gin.Context) (thumbnail.RenderOptions, error) {
+	options := thumbnail.RenderOptions{Fit: thumbnail.FitContain}
+
+	var err error
+	if options.Width, err = controller.dimension(c, "w"); err != nil {
+		return options, err
+	}
+	if options.Height, err = controller.dimension(c, "h"); err != nil {
+		return options, err
+	}
+
+	switch fit := thumbnail.Fit(c.Query("fit")); fit {
+	case "":
+	case thumbnail.FitContain, thumbnail.FitCover, thumbnail.FitFill:
+		options.Fit = fit
+	default:
+		return options, apierrors.NewInvalidParameterError("fit", "expected contain, cover or fill")
+	}
+
+	switch format := thumbnail.Format(c.Query("format")); format {
+	case thumbnail.FormatAuto, thumbnail.FormatJPEG, thumbnail.FormatPNG:
+		options.Format = format
+	default:
+		return options, apierrors.NewInvalidParameterError("format", "expected jpeg or png")
+	}
+
+	switch {
+	case options.Width == 0 && options.Height == 0:
+		return options, apierrors.NewInvalidParameterError("w", "at least one of w and h is required")
+	case options.Width == 0 || options.Height == 0:
+		// the missing dimension follows from the aspect ratio but is still bounded, which only works when containing
+		options.Width = cmp.Or(options.Width, controller.MaxDimension)
+		options.Height = cmp.Or(options.Height, controller.MaxDimension)
+		options.Fit = thumbnail.FitContain
+	}
+
+	return options, nil
+}
+
+// dimension parses an optional width or height, 0 is returned when the parameter is absent
+func (controller *RenderController) dimension(c *gin.Context, parameter string) (int, error) {
+	value := c.Query(parameter)
+	if value == "" {
+		return 0, nil
+	}
+
+	dimension, err := strconv.Atoi(value)
+	if err != nil || dimension < 1 || dimension > controller.MaxDimension {
+		return 0, apierrors.NewInvalidParameterError(parameter, fmt.Sprintf("expected a number between 1 and %d", controller.MaxDimension))
+	}
+
+	return dimension, nil
+}
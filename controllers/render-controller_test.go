@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"bytes"
+	gocontext "context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	mock_services "github.com/TheSandyDave/Media-Tags/generated/mock/services"
+	"github.com/TheSandyDave/Media-Tags/rendercache"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/thumbnail"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newRenderRequest creates a context rendering the media with the query
+func newRenderRequest(t *testing.T, id uuid.UUID, query string) (*gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com/media/"+id.String()+"/render?"+query, nil)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: id.String()})
+
+	return context, writer
+}
+
+func Test_RenderController_Render_RejectsInvalidParameters(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"no dimensions":        "fit=cover",
+		"zero width":           "w=0",
+		"too large height":     "h=101",
+		"width is not numeric": "w=wide",
+		"unknown fit":          "w=10&fit=stretch",
+		"unknown format":       "w=10&format=gif",
+	}
+
+	for name, query := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			RenderController := RenderController{
+				MediaService: mock_services.NewMockIMediaService(ctrl),
+				MaxDimension: 100,
+			}
+			context, _ := newRenderRequest(t, uuid.New(), query)
+
+			// act
+			RenderController.RenderMedia(context)
+
+			// Assert
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, &apierrors.InvalidParameterError{}, context.Errors.Last().Err)
+			}
+		})
+	}
+}
+
+func Test_RenderController_Render_RendersAndCachesTheImage(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	media := domain.Media{
+		BaseObject:  domain.BaseObject{ID: uuid.New()},
+		StorageKey:  "stored.png",
+		ContentType: "image/png",
+	}
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetWithID(gomock.Any(), media.ID, gomock.Any()).Return(&media, nil).Times(2)
+
+	fileStore := storage.NewMemoryFileStore()
+	content, err := os.ReadFile("test-resources/test.png")
+	require.NoError(t, err)
+	require.NoError(t, fileStore.Put(gocontext.Background(), media.StorageKey, bytes.NewReader(content), int64(len(content))))
+
+	cache, err := rendercache.New(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+
+	RenderController := RenderController{
+		MediaService: mediaService,
+		FileStore:    fileStore,
+		Renderer:     thumbnail.Generator{Quality: 80},
+		Cache:        cache,
+		MaxDimension: 100,
+	}
+
+	// act
+	context, writer := newRenderRequest(t, media.ID, "w=8&h=2&fit=fill&format=png")
+	RenderController.RenderMedia(context)
+	// the second request can only succeed when it is served from the cache
+	require.NoError(t, fileStore.Delete(gocontext.Background(), media.StorageKey))
+	cachedContext, cachedWriter := newRenderRequest(t, media.ID, "w=8&h=2&fit=fill&format=png")
+	RenderController.RenderMedia(cachedContext)
+
+	// Assert
+	assert.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "image/png", writer.Header().Get("Content-Type"))
+	assert.NotEmpty(t, writer.Header().Get("ETag"))
+	rendered, err := png.DecodeConfig(bytes.NewReader(writer.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 8, rendered.Width)
+	assert.Equal(t, 2, rendered.Height)
+
+	assert.Empty(t, cachedContext.Errors)
+	assert.Equal(t, http.StatusOK, cachedWriter.Code)
+	assert.Equal(t, writer.Body.Bytes(), cachedWriter.Body.Bytes())
+	assert.Equal(t, writer.Header().Get("ETag"), cachedWriter.Header().Get("ETag"))
+}
+
+func Test_RenderController_Render_FailsForMediaThatIsNotAnImage(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	media := domain.Media{
+		BaseObject:  domain.BaseObject{ID: uuid.New()},
+		StorageKey:  "stored.mp4",
+		ContentType: "video/mp4",
+	}
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	mediaService.EXPECT().GetWithID(gomock.Any(), media.ID, gomock.Any()).Return(&media, nil)
+
+	RenderController := RenderController{
+		MediaService: mediaService,
+		FileStore:    storage.NewMemoryFileStore(),
+		Renderer:     thumbnail.Generator{Quality: 80},
+		MaxDimension: 100,
+	}
+	context, _ := newRenderRequest(t, media.ID, "w=10")
+
+	// act
+	RenderController.RenderMedia(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.NotRenderableError{}, context.Errors.Last().Err)
+	}
+}
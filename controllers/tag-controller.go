@@ -2,12 +2,16 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"net/http"
 
 	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
 	"github.com/TheSandyDave/Media-Tags/conversion"
 	"github.com/TheSandyDave/Media-Tags/domain"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
 	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -17,14 +21,28 @@ type TagController struct {
 }
 
 func (controller *TagController) GetTags(c *gin.Context) {
-	list(c, func(ctx context.Context, _ any) ([]*restgen.Tag, error) {
+	type inputFilters struct {
+		paginationInput
+		Name string `form:"name"`
+	}
 
-		tags, err := controller.TagService.Get(ctx)
+	list(c, func(ctx context.Context, input inputFilters) (*restgen.TagPage, error) {
+		pagination, err := input.toPagination(services.SortByCreatedAt, services.SortByName)
 		if err != nil {
 			return nil, err
 		}
 
-		return conversion.EncodeSlice(tags, conversion.EncodeTag), nil
+		var opts = []services.Option[domain.Tag]{}
+		if input.Name != "" {
+			opts = append(opts, controller.TagService.FilterByNameOption(input.Name))
+		}
+
+		page, err := controller.TagService.GetPage(ctx, pagination, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeTagPage(page), nil
 	})
 }
 
@@ -39,6 +57,23 @@ func (controller *TagController) GetTagWithId(c *gin.Context) {
 	})
 }
 
+func (controller *TagController) GetTagSubtree(c *gin.Context) {
+	logger := utils.NewLogger(c.Request.Context())
+
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := controller.TagService.GetSubtree(c.Request.Context(), id)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed getting subtree")
+		return
+	}
+
+	c.JSON(http.StatusOK, conversion.EncodeSlice(tags, conversion.EncodeTag))
+}
+
 func (controller *TagController) CreateTag(c *gin.Context) {
 	create(c, func(ctx context.Context, input restgen.CreateTag) (*restgen.Tag, error) {
 
@@ -47,7 +82,18 @@ func (controller *TagController) CreateTag(c *gin.Context) {
 		}
 
 		tag := &domain.Tag{
-			Name: input.Name,
+			Name:  input.Name,
+			Owned: domain.Owned{OwnerID: auth.SubjectFrom(ctx)},
+		}
+		for _, alias := range input.Aliases {
+			tag.Aliases = append(tag.Aliases, &domain.TagAlias{Name: alias})
+		}
+		if input.ParentId != nil {
+			parentID, err := uuid.Parse(*input.ParentId)
+			if err != nil {
+				return nil, apierrors.NewInvalidUUIDError(*input.ParentId)
+			}
+			tag.ParentID = &parentID
 		}
 
 		if err := controller.TagService.Create(ctx, tag); err != nil {
@@ -57,3 +103,127 @@ func (controller *TagController) CreateTag(c *gin.Context) {
 		return conversion.EncodeTag(tag), nil
 	})
 }
+
+func (controller *TagController) UpdateTag(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.UpdateTag) (*restgen.Tag, error) {
+		tag, err := controller.TagService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if input.Name != "" {
+			tag.Name = input.Name
+		}
+		if input.ParentId != nil {
+			tag.ParentID = nil
+			if *input.ParentId != "" {
+				parentID, err := uuid.Parse(*input.ParentId)
+				if err != nil {
+					return nil, apierrors.NewInvalidUUIDError(*input.ParentId)
+				}
+				tag.ParentID = &parentID
+			}
+		}
+
+		if err := controller.TagService.Update(ctx, tag); err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeTag(tag), nil
+	})
+}
+
+// ReplaceTag replaces the name and parent of the tag, a tag replaced without a parent becomes a root tag
+func (controller *TagController) ReplaceTag(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.ReplaceTag) (*restgen.Tag, error) {
+		if input.Name == "" {
+			return nil, apierrors.NewRequiredValueMissingError("name")
+		}
+
+		tag, err := controller.TagService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		tag.Name = input.Name
+		tag.ParentID = nil
+		if input.ParentId != "" {
+			parentID, err := uuid.Parse(input.ParentId)
+			if err != nil {
+				return nil, apierrors.NewInvalidUUIDError(input.ParentId)
+			}
+			tag.ParentID = &parentID
+		}
+
+		if err := controller.TagService.Update(ctx, tag); err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeTag(tag), nil
+	})
+}
+
+func (controller *TagController) ReplaceTagAliases(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.TagAliases) (*restgen.Tag, error) {
+		tag, err := controller.TagService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := controller.TagService.ReplaceAliases(ctx, tag, input.Aliases); err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeTag(tag), nil
+	})
+}
+
+func (controller *TagController) MergeTag(c *gin.Context) {
+	update(c, func(ctx context.Context, id uuid.UUID, input restgen.MergeTag) (*restgen.Tag, error) {
+		if input.TargetId == "" {
+			return nil, apierrors.NewRequiredValueMissingError("targetId")
+		}
+		targetID, err := uuid.Parse(input.TargetId)
+		if err != nil {
+			return nil, apierrors.NewInvalidUUIDError(input.TargetId)
+		}
+
+		// the source is deleted and the target receives its aliases and media, both have to be the caller's
+		source, err := controller.TagService.GetOwnedWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		target, err := controller.TagService.GetOwnedWithID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := controller.TagService.Merge(ctx, source, target); err != nil {
+			return nil, err
+		}
+
+		// reload the target to include the aliases it received
+		target, err = controller.TagService.GetWithID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+
+		return conversion.EncodeTag(target), nil
+	})
+}
+
+// DeleteTag deletes the tag when the principal owns it, the ownership is checked by the delete itself so it can't
+// change in between
+func (controller *TagController) DeleteTag(c *gin.Context) {
+	deleteWithID(c, func(ctx context.Context, id uuid.UUID) error {
+		err := controller.TagService.Delete(ctx, id, services.OwnedByOption[domain.Tag](ctx))
+		var notFound *apierrors.RecordNotFoundError
+		if errors.As(err, &notFound) {
+			// tags of others are reported as forbidden rather than as missing
+			if _, ownedErr := controller.TagService.GetOwnedWithID(ctx, id); ownedErr != nil {
+				return ownedErr
+			}
+		}
+		return err
+	})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"bytes"
+	gocontext "context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"slices"
 	"testing"
 
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
 	"github.com/TheSandyDave/Media-Tags/domain"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
 	mock_services "github.com/TheSandyDave/Media-Tags/generated/mock/services"
@@ -68,7 +71,7 @@ func Test_TagController_Get_WritesCorrectOutput(t *testing.T) {
 
 			tagService := mock_services.NewMockITagService(ctrl)
 
-			tagService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(testData.models, nil)
+			tagService.EXPECT().GetPage(gomock.Any(), gomock.Any()).Return(&domain.Page[domain.Tag]{Items: testData.models}, nil)
 
 			TagController := TagController{
 				TagService: tagService,
@@ -86,11 +89,11 @@ func Test_TagController_Get_WritesCorrectOutput(t *testing.T) {
 			TagController.GetTags(context)
 
 			// Assert
-			var result []restgen.Tag
+			var result restgen.TagPage
 
 			if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
-				assert.Equal(t, len(testData.output), len(result))
-				equal := slices.EqualFunc(testData.output, result, func(a, b restgen.Tag) bool {
+				assert.Equal(t, len(testData.output), len(result.Items))
+				equal := slices.EqualFunc(testData.output, result.Items, func(a, b restgen.Tag) bool {
 					return a.Name == b.Name
 				})
 				assert.True(t, equal)
@@ -187,3 +190,505 @@ func Test_TagController_Create_WritesCorrectOutput(t *testing.T) {
 	}
 
 }
+
+func Test_TagController_Create_RecordsThePrincipalAsOwner(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var created *domain.Tag
+	tagService := mock_services.NewMockITagService(ctrl)
+	tagService.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ gocontext.Context, tags ...*domain.Tag) error {
+		created = tags[0]
+		return nil
+	})
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString(`{"name":"owned"}`))
+	context.Request.Header.Set("Content-Type", "application/json")
+	principal := &auth.Principal{Subject: "user-1", Role: auth.RoleUploader}
+	context.Request = context.Request.WithContext(auth.WithPrincipal(context.Request.Context(), principal))
+
+	// act
+	TagController.CreateTag(context)
+
+	// Assert
+	var result restgen.Tag
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusCreated, writer.Result())) {
+		assert.Equal(t, "user-1", result.Owner)
+	}
+	if assert.NotNil(t, created) {
+		assert.Equal(t, "user-1", created.OwnerID)
+	}
+}
+
+func Test_TagController_Update_WritesCorrectOutput(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	existingTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "before",
+	}
+	expectedName := "after"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+
+	tagService.EXPECT().GetOwnedWithID(gomock.Any(), existingTag.ID, gomock.Any()).Return(&existingTag, nil)
+	tagService.EXPECT().Update(gomock.Any(), &existingTag).Return(nil)
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	body, err := json.Marshal(&restgen.UpdateTag{Name: expectedName})
+	if err != nil {
+		t.Error(err)
+	}
+
+	context.Request, err = http.NewRequest(http.MethodPatch, "https://example.com", bytes.NewBuffer(body))
+	if err != nil {
+		t.Error(err)
+	}
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: existingTag.ID.String()})
+
+	// act
+	TagController.UpdateTag(context)
+
+	// Assert
+	var result restgen.Tag
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Equal(t, expectedName, result.Name)
+		assert.Equal(t, existingTag.ID.String(), result.Id)
+	}
+}
+
+func Test_TagController_Update_ChangesParent(t *testing.T) {
+	t.Parallel()
+
+	parentID := uuid.New()
+	newParentID := uuid.New()
+	empty := ""
+	newParent := newParentID.String()
+	invalid := "not-a-uuid"
+
+	tests := map[string]struct {
+		parentID *string
+		expected *uuid.UUID
+	}{
+		"absent keeps parent":   {parentID: nil, expected: &parentID},
+		"empty makes root":      {parentID: &empty, expected: nil},
+		"uuid sets new parent":  {parentID: &newParent, expected: &newParentID},
+		"invalid uuid rejected": {parentID: &invalid},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			existingTag := domain.Tag{
+				BaseObject: domain.BaseObject{
+					ID: uuid.New(),
+				},
+				Name:     "tabby",
+				ParentID: &parentID,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tagService := mock_services.NewMockITagService(ctrl)
+			tagService.EXPECT().GetOwnedWithID(gomock.Any(), existingTag.ID, gomock.Any()).Return(&existingTag, nil)
+			if testData.parentID == &invalid {
+				tagService.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+			} else {
+				tagService.EXPECT().Update(gomock.Any(), &existingTag).Return(nil)
+			}
+
+			TagController := TagController{
+				TagService: tagService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			body, err := json.Marshal(&restgen.UpdateTag{ParentId: testData.parentID})
+			if err != nil {
+				t.Error(err)
+			}
+			context.Request = httptest.NewRequest(http.MethodPatch, "https://example.com", bytes.NewBuffer(body))
+			context.Request.Header.Set("Content-Type", "application/json")
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: existingTag.ID.String()})
+
+			// act
+			TagController.UpdateTag(context)
+
+			// Assert
+			if testData.parentID == &invalid {
+				if assert.NotEmpty(t, context.Errors) {
+					assert.IsType(t, &apierrors.InvalidUUIDError{}, context.Errors.Last().Err)
+				}
+				return
+			}
+			assert.Equal(t, testData.expected, existingTag.ParentID)
+			var result restgen.Tag
+			if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+				if testData.expected == nil {
+					assert.Nil(t, result.ParentId)
+				} else if assert.NotNil(t, result.ParentId) {
+					assert.Equal(t, testData.expected.String(), *result.ParentId)
+				}
+			}
+		})
+	}
+}
+
+func Test_TagController_Replace_ReplacesNameAndParent(t *testing.T) {
+	t.Parallel()
+
+	parentID := uuid.New()
+	newParentID := uuid.New()
+
+	tests := map[string]struct {
+		input    restgen.ReplaceTag
+		expected *uuid.UUID
+	}{
+		"absent parent makes root": {input: restgen.ReplaceTag{Name: "after"}, expected: nil},
+		"uuid sets new parent":     {input: restgen.ReplaceTag{Name: "after", ParentId: newParentID.String()}, expected: &newParentID},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			existingTag := domain.Tag{
+				BaseObject: domain.BaseObject{
+					ID: uuid.New(),
+				},
+				Name:     "before",
+				ParentID: &parentID,
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tagService := mock_services.NewMockITagService(ctrl)
+			tagService.EXPECT().GetOwnedWithID(gomock.Any(), existingTag.ID, gomock.Any()).Return(&existingTag, nil)
+			tagService.EXPECT().Update(gomock.Any(), &existingTag).Return(nil)
+
+			TagController := TagController{
+				TagService: tagService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			body, err := json.Marshal(&testData.input)
+			if err != nil {
+				t.Error(err)
+			}
+			context.Request = httptest.NewRequest(http.MethodPut, "https://example.com", bytes.NewBuffer(body))
+			context.Request.Header.Set("Content-Type", "application/json")
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: existingTag.ID.String()})
+
+			// act
+			TagController.ReplaceTag(context)
+
+			// Assert
+			assert.Equal(t, testData.expected, existingTag.ParentID)
+			var result restgen.Tag
+			if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+				assert.Equal(t, "after", result.Name)
+			}
+		})
+	}
+}
+
+func Test_TagController_Replace_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input    restgen.ReplaceTag
+		expected error
+	}{
+		"missing name":        {input: restgen.ReplaceTag{}, expected: &apierrors.RequiredValueMissingError{}},
+		"invalid parent uuid": {input: restgen.ReplaceTag{Name: "tabby", ParentId: "not-a-uuid"}, expected: &apierrors.InvalidUUIDError{}},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			existingTag := domain.Tag{
+				BaseObject: domain.BaseObject{
+					ID: uuid.New(),
+				},
+				Name: "before",
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tagService := mock_services.NewMockITagService(ctrl)
+			tagService.EXPECT().GetOwnedWithID(gomock.Any(), existingTag.ID, gomock.Any()).Return(&existingTag, nil).AnyTimes()
+			tagService.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+			TagController := TagController{
+				TagService: tagService,
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			body, err := json.Marshal(&testData.input)
+			if err != nil {
+				t.Error(err)
+			}
+			context.Request = httptest.NewRequest(http.MethodPut, "https://example.com", bytes.NewBuffer(body))
+			context.Request.Header.Set("Content-Type", "application/json")
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: existingTag.ID.String()})
+
+			// act
+			TagController.ReplaceTag(context)
+
+			// Assert
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, testData.expected, context.Errors.Last().Err)
+			}
+		})
+	}
+}
+
+func Test_TagController_GetSubtree_WritesCorrectOutput(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	root := &domain.Tag{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "animal"}
+	child := &domain.Tag{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "cat", ParentID: &root.ID}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	tagService.EXPECT().GetSubtree(gomock.Any(), root.ID).Return([]*domain.Tag{root, child}, nil)
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: root.ID.String()})
+
+	// act
+	TagController.GetTagSubtree(context)
+
+	// Assert
+	var result []restgen.Tag
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) && assert.Len(t, result, 2) {
+		assert.Equal(t, "animal", result[0].Name)
+		assert.Nil(t, result[0].ParentId)
+		assert.Equal(t, "cat", result[1].Name)
+		assert.Equal(t, root.ID.String(), *result[1].ParentId)
+	}
+}
+
+func Test_TagController_ReplaceAliases_WritesCorrectOutput(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	existingTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "New York City",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	tagService.EXPECT().GetOwnedWithID(gomock.Any(), existingTag.ID, gomock.Any()).Return(&existingTag, nil)
+	tagService.EXPECT().ReplaceAliases(gomock.Any(), &existingTag, []string{"nyc", "big apple"}).
+		DoAndReturn(func(_ gocontext.Context, tag *domain.Tag, aliases []string) error {
+			tag.Aliases = []*domain.TagAlias{{Name: "nyc"}, {Name: "big apple"}}
+			return nil
+		})
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	body, err := json.Marshal(&restgen.TagAliases{Aliases: []string{"nyc", "big apple"}})
+	if err != nil {
+		t.Error(err)
+	}
+	context.Request = httptest.NewRequest(http.MethodPut, "https://example.com", bytes.NewBuffer(body))
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: existingTag.ID.String()})
+
+	// act
+	TagController.ReplaceTagAliases(context)
+
+	// Assert
+	var result restgen.Tag
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Equal(t, []string{"big apple", "nyc"}, result.Aliases)
+	}
+}
+
+func Test_TagController_Merge_WritesTarget(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	source := &domain.Tag{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "nyc"}
+	target := &domain.Tag{BaseObject: domain.BaseObject{ID: uuid.New()}, Name: "New York City"}
+	merged := &domain.Tag{BaseObject: target.BaseObject, Name: target.Name, Aliases: []*domain.TagAlias{{Name: "nyc"}}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	gomock.InOrder(
+		tagService.EXPECT().GetOwnedWithID(gomock.Any(), source.ID).Return(source, nil),
+		tagService.EXPECT().GetOwnedWithID(gomock.Any(), target.ID).Return(target, nil),
+		tagService.EXPECT().Merge(gomock.Any(), source, target).Return(nil),
+		tagService.EXPECT().GetWithID(gomock.Any(), target.ID).Return(merged, nil),
+	)
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	body, err := json.Marshal(&restgen.MergeTag{TargetId: target.ID.String()})
+	if err != nil {
+		t.Error(err)
+	}
+	context.Request = httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBuffer(body))
+	context.Request.Header.Set("Content-Type", "application/json")
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: source.ID.String()})
+
+	// act
+	TagController.MergeTag(context)
+
+	// Assert
+	var result restgen.Tag
+	if assert.True(t, utils.RetrieveResponse(t, &result, http.StatusOK, writer.Result())) {
+		assert.Equal(t, target.ID.String(), result.Id)
+		assert.Equal(t, []string{"nyc"}, result.Aliases)
+	}
+}
+
+func Test_TagController_Delete_WritesNoContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	id := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	tagService.EXPECT().Delete(gomock.Any(), id, gomock.Any()).Return(nil)
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodDelete, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: id.String()})
+
+	// act
+	TagController.DeleteTag(context)
+	context.Writer.WriteHeaderNow()
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	assert.Empty(t, context.Errors)
+}
+
+func Test_TagController_Delete_ReportsTagsOfOthersAsForbidden(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	id := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	tagService.EXPECT().Delete(gomock.Any(), id, gomock.Any()).Return(apierrors.NewNotFoundError(id))
+	tagService.EXPECT().GetOwnedWithID(gomock.Any(), id).Return(nil, apierrors.NewForbiddenError("not the owner"))
+
+	TagController := TagController{
+		TagService: tagService,
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodDelete, "https://example.com", nil)
+	principal := &auth.Principal{Subject: "apikey:other", Role: auth.RoleUploader}
+	context.Request = context.Request.WithContext(auth.WithPrincipal(context.Request.Context(), principal))
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: id.String()})
+
+	// act
+	TagController.DeleteTag(context)
+
+	// Assert
+	if assert.NotEmpty(t, context.Errors) {
+		assert.IsType(t, &apierrors.ForbiddenError{}, context.Errors.Last().Err)
+	}
+}
+
+func Test_TagController_Delete_FailsOnInvalidID(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	TagController := TagController{
+		TagService: mock_services.NewMockITagService(ctrl),
+	}
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	var err error
+	context.Request, err = http.NewRequest(http.MethodDelete, "https://example.com", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: "not-a-uuid"})
+
+	// act
+	TagController.DeleteTag(context)
+
+	// Assert
+	assert.IsType(t, &apierrors.InvalidUUIDError{}, context.Errors.Last().Err)
+}
@@ -0,0 +1,524 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/conversion"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/metadata"
+	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// tusVersion is the only version of the tus protocol that is supported
+	tusVersion = "1.0.0"
+	// tusExtensions are the supported extensions of the tus protocol
+	tusExtensions = "creation,expiration,termination"
+	// chunkContentType is the content type of every chunk sent to a resumable upload
+	chunkContentType = "application/offset+octet-stream"
+	// resumableUploadPrefix is the prefix of the keys of the received chunks, which are stored per upload
+	resumableUploadPrefix = "resumable/"
+)
+
+// UploadController implements resumable uploads following the tus protocol, the media is created like an upload to
+// the MediaController once the complete file has been received
+type UploadController struct {
+	UploadService services.IUploadService
+	FileStore     storage.FileStore
+	// Media creates the media of completed uploads and provides the upload rules
+	Media *MediaController
+	// Expiration is how long an upload can be resumed after its last chunk was received
+	Expiration time.Duration
+
+	// writing holds the IDs of the uploads a chunk is being received for, a chunk is only accepted at a time
+	writing sync.Map
+}
+
+// GetUploadOptions describes the supported version, extensions and maximum size of the tus protocol
+func (controller *UploadController) GetUploadOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	if controller.Media.MaxFileSize > 0 {
+		c.Header("Tus-Max-Size", strconv.FormatInt(controller.Media.MaxFileSize, 10))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateUpload starts a resumable upload, the fields of the media are sent in the Upload-Metadata header
+func (controller *UploadController) CreateUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	upload, err := controller.newUpload(c)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("invalid upload")
+		return
+	}
+
+	if err := controller.UploadService.Create(ctx, upload); err != nil {
+		logger.WithError(c.Error(err)).Error("failed creating upload")
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/uploads/%s", upload.ID))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// newUpload validates the headers of a creation request, everything except the file is checked up front so an
+// upload doesn't fail after the client sent all of it
+func (controller *UploadController) newUpload(c *gin.Context) (*domain.Upload, error) {
+	if err := checkTusVersion(c); err != nil {
+		return nil, err
+	}
+
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		return nil, apierrors.NewInvalidParameterError("Upload-Defer-Length", "the length of an upload has to be known")
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, apierrors.NewInvalidParameterError("Upload-Length", "expected a non-negative number of bytes")
+	}
+	if maxSize := controller.Media.MaxFileSize; maxSize > 0 && length > maxSize {
+		return nil, apierrors.NewFileTooLargeError(maxSize)
+	}
+
+	fields, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &domain.Upload{
+		Owned:     domain.Owned{OwnerID: auth.SubjectFrom(c.Request.Context())},
+		Length:    length,
+		Name:      fields["name"],
+		Tags:      splitList(fields["tags"]),
+		Strip:     splitList(fields["strip"]),
+		Filename:  fields["filename"],
+		FileType:  fields["filetype"],
+		ExpiresAt: time.Now().UTC().Add(controller.Expiration),
+	}
+
+	if len(upload.Tags) == 0 {
+		return nil, apierrors.NewRequiredValueMissingError("tags")
+	}
+	if _, err := controller.Media.getTags(c.Request.Context(), upload.Tags); err != nil {
+		return nil, err
+	}
+	if upload.Name == "" {
+		return nil, apierrors.NewRequiredValueMissingError("name")
+	}
+	if _, err := metadata.ParsePolicy(upload.Strip); err != nil {
+		return nil, apierrors.NewInvalidParameterError("strip", err.Error())
+	}
+
+	return upload, nil
+}
+
+// GetUploadOffset reports how much of the upload has been received so the client can resume it
+func (controller *UploadController) GetUploadOffset(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	upload, err := controller.getUpload(c)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed retrieving upload")
+		return
+	}
+
+	// the offset changes with every chunk
+	c.Header("Cache-Control", "no-store")
+	writeUploadHeaders(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// AppendUpload stores a chunk of the upload, the media is created once the last chunk has been received. What was
+// received of a chunk is kept when the connection breaks, so the client can resume from there
+func (controller *UploadController) AppendUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	upload, err := controller.append(c)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed appending to upload")
+		return
+	}
+
+	writeUploadHeaders(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+func (controller *UploadController) append(c *gin.Context) (*domain.Upload, error) {
+	ctx := c.Request.Context()
+
+	id, err := uploadID(c)
+	if err != nil {
+		return nil, err
+	}
+	if contentType := c.GetHeader("Content-Type"); contentType != chunkContentType {
+		return nil, apierrors.NewUnsupportedContentTypeError(contentType, chunkContentType)
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return nil, apierrors.NewInvalidParameterError("Upload-Offset", "expected a non-negative number of bytes")
+	}
+
+	if _, busy := controller.writing.LoadOrStore(id, struct{}{}); busy {
+		return nil, apierrors.NewUploadConflictError("another chunk of the upload is being received")
+	}
+	defer controller.writing.Delete(id)
+
+	upload, err := controller.findUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != upload.Offset {
+		return nil, apierrors.NewUploadConflictError(fmt.Sprintf("the upload continues at offset %d", upload.Offset))
+	}
+	if upload.MediaID != nil {
+		return upload, nil
+	}
+
+	remaining := upload.Length - upload.Offset
+	if c.Request.ContentLength > remaining {
+		return nil, apierrors.NewRequestTooLargeError(remaining)
+	}
+	// a client that disconnects cancels the request, what was received until then still has to be kept and counted
+	persist := context.WithoutCancel(ctx)
+	received, err := controller.storeChunk(persist, upload, http.MaxBytesReader(c.Writer, c.Request.Body, remaining))
+	if err != nil {
+		return nil, err
+	}
+
+	upload.Offset += received
+	upload.ExpiresAt = time.Now().UTC().Add(controller.Expiration)
+	if err := controller.UploadService.Update(persist, upload); err != nil {
+		return nil, err
+	}
+
+	if upload.Offset < upload.Length {
+		return upload, nil
+	}
+
+	// a completion that fails is retried by sending an empty chunk at the final offset
+	mediaID, err := controller.complete(ctx, controller.Media.FileURLs.For(c.Request), upload)
+	if err != nil {
+		return nil, err
+	}
+
+	upload.MediaID = &mediaID
+	if err := controller.UploadService.Update(ctx, upload); err != nil {
+		return nil, err
+	}
+	controller.removeChunks(ctx, upload.ID)
+
+	return upload, nil
+}
+
+// storeChunk stores the received part of a chunk under its offset, returning its size. A broken connection is not
+// an error, whatever was received until then is kept
+func (controller *UploadController) storeChunk(ctx context.Context, upload *domain.Upload, body io.Reader) (int64, error) {
+	content := &chunkReader{reader: body}
+	key := chunkKey(upload.ID, upload.Offset)
+	// the size stays unknown even with a Content-Length, an interrupted chunk is shorter than announced. Stores stream
+	// content of unknown size, S3 buffers it a part of 16 MiB at a time
+	if err := controller.FileStore.Put(ctx, key, content, -1); err != nil {
+		controller.Media.removeFiles(ctx, key)
+		return 0, err
+	}
+
+	var maxBytesError *http.MaxBytesError
+	if errors.As(content.err, &maxBytesError) {
+		controller.Media.removeFiles(ctx, key)
+		return 0, apierrors.NewRequestTooLargeError(maxBytesError.Limit)
+	}
+	if content.err != nil {
+		utils.NewLogger(ctx).WithField("received", content.read).WithError(content.err).Warn("chunk was interrupted")
+	}
+	if content.read == 0 {
+		controller.Media.removeFiles(ctx, key)
+	}
+
+	return content.read, nil
+}
+
+// complete streams the chunks of the upload to a single file and creates the media from it, returning the ID of the
+// media. The media is created under the ID of the upload, so a completion retried after the media was created but
+// not recorded on the upload finds it instead of creating it again. urls build the URLs of the created media
+func (controller *UploadController) complete(ctx context.Context, urls conversion.MediaURLs, upload *domain.Upload) (uuid.UUID, error) {
+	_, err := controller.Media.MediaService.GetWithID(ctx, upload.ID)
+	var notFound *apierrors.RecordNotFoundError
+	if err == nil {
+		return upload.ID, nil
+	}
+	if !errors.As(err, &notFound) {
+		return uuid.Nil, err
+	}
+
+	chunks, err := controller.FileStore.List(ctx, chunkPrefix(upload.ID))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	keys := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		keys[i] = chunk.Key
+	}
+
+	content := &chunksReader{ctx: ctx, fileStore: controller.FileStore, keys: keys}
+	defer content.Close()
+
+	file := &mediaUpload{
+		ID:    upload.ID,
+		Name:  upload.Name,
+		Tags:  upload.Tags,
+		Strip: upload.Strip,
+		Owner: upload.OwnerID,
+	}
+	err = controller.Media.streamFile(ctx, content, upload.FileType, upload.Filename, file)
+	if file.TemporaryKey != "" {
+		defer controller.Media.removeFiles(ctx, file.TemporaryKey)
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if _, err := controller.Media.createMedia(ctx, urls, file); err != nil {
+		return uuid.Nil, err
+	}
+	return upload.ID, nil
+}
+
+// DeleteUpload terminates an upload and removes the chunks received so far
+func (controller *UploadController) DeleteUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := utils.NewLogger(ctx)
+
+	upload, err := controller.getUpload(c)
+	if err != nil {
+		logger.WithError(c.Error(err)).Error("failed retrieving upload")
+		return
+	}
+
+	if err := controller.UploadService.Delete(ctx, upload.ID); err != nil {
+		logger.WithError(c.Error(err)).Error("failed deleting upload")
+		return
+	}
+	controller.removeChunks(ctx, upload.ID)
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveExpired deletes the uploads that expired along with their chunks
+func (controller *UploadController) RemoveExpired(ctx context.Context) error {
+	expired, err := controller.UploadService.Get(ctx, controller.UploadService.FilterExpiredOption(time.Now().UTC()))
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range expired {
+		if err := controller.UploadService.Delete(ctx, upload.ID); err != nil {
+			return err
+		}
+		controller.removeChunks(ctx, upload.ID)
+	}
+
+	if len(expired) > 0 {
+		utils.NewLogger(ctx).WithField("count", len(expired)).Info("removed expired uploads")
+	}
+	return nil
+}
+
+// RemoveExpiredEvery removes expired uploads at every interval until the context is done
+func (controller *UploadController) RemoveExpiredEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := controller.RemoveExpired(ctx); err != nil {
+				utils.NewLogger(ctx).WithError(err).Error("failed removing expired uploads")
+			}
+		}
+	}
+}
+
+// getUpload checks the protocol version and retrieves the upload of the request
+func (controller *UploadController) getUpload(c *gin.Context) (*domain.Upload, error) {
+	id, err := uploadID(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.findUpload(c.Request.Context(), id)
+}
+
+// uploadID checks the protocol version and parses the ID of the upload of the request
+func uploadID(c *gin.Context) (uuid.UUID, error) {
+	if err := checkTusVersion(c); err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, apierrors.NewInvalidUUIDError(c.Param("id"))
+	}
+	return id, nil
+}
+
+// findUpload retrieves an upload of the principal of the context, expired uploads can't be used anymore even if they haven't been removed yet
+func (controller *UploadController) findUpload(ctx context.Context, id uuid.UUID) (*domain.Upload, error) {
+	upload, err := controller.UploadService.GetOwnedWithID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.MediaID == nil && time.Now().After(upload.ExpiresAt) {
+		return nil, apierrors.NewUploadExpiredError(upload.ID)
+	}
+
+	return upload, nil
+}
+
+// removeChunks deletes the stored chunks of an upload, failures are only logged
+func (controller *UploadController) removeChunks(ctx context.Context, id uuid.UUID) {
+	chunks, err := controller.FileStore.List(ctx, chunkPrefix(id))
+	if err != nil {
+		utils.NewLogger(ctx).WithField("upload", id).WithError(err).Error("failed listing chunks")
+		return
+	}
+	for _, chunk := range chunks {
+		controller.Media.removeFiles(ctx, chunk.Key)
+	}
+}
+
+// checkTusVersion verifies the client uses the supported version of the protocol, every response states the version
+func checkTusVersion(c *gin.Context) error {
+	c.Header("Tus-Resumable", tusVersion)
+
+	if version := c.GetHeader("Tus-Resumable"); version != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		return apierrors.NewUnsupportedTusVersionError(version)
+	}
+	return nil
+}
+
+func writeUploadHeaders(c *gin.Context, upload *domain.Upload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.MediaID != nil {
+		c.Header("Media-Id", upload.MediaID.String())
+	} else {
+		c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseUploadMetadata decodes the Upload-Metadata header, comma separated pairs of a key and a base64 encoded value
+func parseUploadMetadata(header string) (map[string]string, error) {
+	fields := map[string]string{}
+	for _, pair := range splitList(header) {
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, apierrors.NewInvalidParameterError("Upload-Metadata", fmt.Sprintf("value of %s is not base64 encoded", key))
+		}
+		fields[key] = string(value)
+	}
+	return fields, nil
+}
+
+// splitList splits a comma separated list, leaving out empty elements
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func chunkPrefix(id uuid.UUID) string {
+	return fmt.Sprintf("%s%s/", resumableUploadPrefix, id)
+}
+
+// chunkKey names chunks after their offset, padded so listing them returns them in order
+func chunkKey(id uuid.UUID, offset int64) string {
+	return fmt.Sprintf("%s%020d", chunkPrefix(id), offset)
+}
+
+// chunkReader ends the content of a chunk at the first error, keeping the error and the number of bytes read
+type chunkReader struct {
+	reader io.Reader
+	read   int64
+	err    error
+}
+
+func (content *chunkReader) Read(buffer []byte) (int, error) {
+	read, err := content.reader.Read(buffer)
+	content.read += int64(read)
+	if err != nil && !errors.Is(err, io.EOF) {
+		content.err = err
+		return read, io.EOF
+	}
+	return read, err
+}
+
+// chunksReader reads the stored chunks of an upload one after the other, opening a single chunk at a time
+type chunksReader struct {
+	ctx       context.Context
+	fileStore storage.FileStore
+	keys      []string
+	current   io.ReadCloser
+}
+
+func (content *chunksReader) Read(buffer []byte) (int, error) {
+	for {
+		if content.current == nil {
+			if len(content.keys) == 0 {
+				return 0, io.EOF
+			}
+			file, err := content.fileStore.Get(content.ctx, content.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			content.current, content.keys = file, content.keys[1:]
+		}
+
+		read, err := content.current.Read(buffer)
+		if errors.Is(err, io.EOF) {
+			content.current.Close()
+			content.current = nil
+			if read == 0 {
+				continue
+			}
+			err = nil
+		}
+		return read, err
+	}
+}
+
+func (content *chunksReader) Close() error {
+	if content.current == nil {
+		return nil
+	}
+	return content.current.Close()
+}
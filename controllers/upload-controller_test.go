@@ -0,0 +1,569 @@
+package controllers
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	mock_services "github.com/TheSandyDave/Media-Tags/generated/mock/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// uploadMetadata encodes the fields of an upload like the Upload-Metadata header
+func uploadMetadata(fields map[string]string) string {
+	pairs := []string{}
+	for key, value := range fields {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// newChunkRequest builds a PATCH request sending the body as a chunk of the upload at the offset
+func newChunkRequest(t *testing.T, id uuid.UUID, offset int64, body io.Reader) *http.Request {
+	t.Helper()
+
+	request, err := http.NewRequest(http.MethodPatch, "https://example.com/uploads/"+id.String(), body)
+	require.NoError(t, err)
+	request.Header.Set("Tus-Resumable", tusVersion)
+	request.Header.Set("Content-Type", chunkContentType)
+	request.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+	return request
+}
+
+func Test_UploadController_Create_StoresUploadWithItsMetadata(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+
+	UploadController := UploadController{
+		UploadService: uploadService,
+		Media:         &MediaController{TagService: tagService, MaxFileSize: 1 << 20},
+		Expiration:    time.Hour,
+	}
+
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodPost, "https://example.com/uploads", nil)
+	context.Request.Header.Set("Tus-Resumable", tusVersion)
+	context.Request.Header.Set("Upload-Length", "1000")
+	context.Request.Header.Set("Upload-Metadata", uploadMetadata(map[string]string{
+		"name":     "expectedMedia",
+		"tags":     expectedTag.ID.String(),
+		"strip":    "location",
+		"filename": "video.mp4",
+		"filetype": "video/mp4",
+	}))
+
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	var createdUpload *domain.Upload
+	uploadService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, uploads ...*domain.Upload) error {
+			createdUpload = uploads[0]
+			createdUpload.ID = uuid.New()
+			return nil
+		})
+
+	// act
+	UploadController.CreateUpload(context)
+	context.Writer.WriteHeaderNow()
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	assert.Equal(t, "/uploads/"+createdUpload.ID.String(), writer.Header().Get("Location"))
+	assert.Equal(t, tusVersion, writer.Header().Get("Tus-Resumable"))
+	assert.NotEmpty(t, writer.Header().Get("Upload-Expires"))
+
+	assert.Equal(t, int64(1000), createdUpload.Length)
+	assert.Equal(t, "expectedMedia", createdUpload.Name)
+	assert.Equal(t, []string{expectedTag.ID.String()}, createdUpload.Tags)
+	assert.Equal(t, []string{"location"}, createdUpload.Strip)
+	assert.Equal(t, "video.mp4", createdUpload.Filename)
+	assert.Equal(t, "video/mp4", createdUpload.FileType)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), createdUpload.ExpiresAt, time.Minute)
+}
+
+func Test_UploadController_Create_FailsOnInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		headers       map[string]string
+		expectedError error
+	}{
+		"unsupported version": {
+			headers:       map[string]string{"Tus-Resumable": "0.2.2", "Upload-Length": "10"},
+			expectedError: &apierrors.UnsupportedTusVersionError{},
+		},
+		"missing length": {
+			headers:       map[string]string{"Tus-Resumable": tusVersion},
+			expectedError: &apierrors.InvalidParameterError{},
+		},
+		"deferred length": {
+			headers:       map[string]string{"Tus-Resumable": tusVersion, "Upload-Defer-Length": "1"},
+			expectedError: &apierrors.InvalidParameterError{},
+		},
+		"too large": {
+			headers:       map[string]string{"Tus-Resumable": tusVersion, "Upload-Length": "1025"},
+			expectedError: &apierrors.FileTooLargeError{},
+		},
+		"invalid metadata": {
+			headers:       map[string]string{"Tus-Resumable": tusVersion, "Upload-Length": "10", "Upload-Metadata": "name !!!"},
+			expectedError: &apierrors.InvalidParameterError{},
+		},
+		"missing tags": {
+			headers: map[string]string{
+				"Tus-Resumable":   tusVersion,
+				"Upload-Length":   "10",
+				"Upload-Metadata": uploadMetadata(map[string]string{"name": "expectedMedia"}),
+			},
+			expectedError: &apierrors.RequiredValueMissingError{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			UploadController := UploadController{
+				Media: &MediaController{MaxFileSize: 1024},
+			}
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = httptest.NewRequest(http.MethodPost, "https://example.com/uploads", nil)
+			for key, value := range test.headers {
+				context.Request.Header.Set(key, value)
+			}
+
+			// act
+			UploadController.CreateUpload(context)
+
+			// Assert
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, test.expectedError, context.Errors.Last().Err)
+			}
+		})
+	}
+}
+
+func Test_UploadController_Append_CreatesMediaOnceComplete(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tagService := mock_services.NewMockITagService(ctrl)
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	UploadController := UploadController{
+		UploadService: uploadService,
+		FileStore:     fileStore,
+		Media: &MediaController{
+			TagService:        tagService,
+			MediaService:      mediaService,
+			FileStore:         fileStore,
+			StoredFileService: newStoredFileService(t),
+			Kinds:             testKinds,
+		},
+		Expiration: time.Hour,
+	}
+
+	content, err := os.ReadFile("test-resources/test.png")
+	require.NoError(t, err)
+	expectedTag := domain.Tag{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "expectedTag",
+	}
+	upload := &domain.Upload{
+		BaseObject: domain.BaseObject{ID: uuid.New()},
+		Length:     int64(len(content)),
+		Name:       "expectedMedia",
+		Tags:       []string{expectedTag.ID.String()},
+		Filename:   "test.png",
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	uploadService.EXPECT().GetOwnedWithID(gomock.Any(), upload.ID).Return(upload, nil).Times(2)
+	uploadService.EXPECT().Update(gomock.Any(), upload).Return(nil).Times(3)
+	tagService.EXPECT().GetWithIDs(gomock.Any(), []uuid.UUID{expectedTag.ID}, gomock.Any()).
+		Return([]*domain.Tag{&expectedTag}, nil)
+	mediaService.EXPECT().GetWithID(gomock.Any(), upload.ID).Return(nil, apierrors.NewNotFoundError(upload.ID))
+	expectNoDuplicates(mediaService)
+	var createdMedia *domain.Media
+	mediaService.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ gocontext.Context, media ...*domain.Media) error {
+			createdMedia = media[0]
+			return nil
+		})
+
+	for _, chunk := range []struct{ offset, end int }{{0, 50}, {50, len(content)}} {
+		writer := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(writer)
+		context.Request = newChunkRequest(t, upload.ID, int64(chunk.offset), bytes.NewReader(content[chunk.offset:chunk.end]))
+		context.Params = append(context.Params, gin.Param{Key: "id", Value: upload.ID.String()})
+
+		// act
+		UploadController.AppendUpload(context)
+		context.Writer.WriteHeaderNow()
+
+		// Assert
+		require.Empty(t, context.Errors)
+		assert.Equal(t, http.StatusNoContent, writer.Code)
+		assert.Equal(t, strconv.Itoa(chunk.end), writer.Header().Get("Upload-Offset"))
+	}
+
+	require.NotNil(t, createdMedia)
+	assert.Equal(t, "expectedMedia", createdMedia.Name)
+	assert.Equal(t, domain.MediaKindImage, createdMedia.Kind)
+	assert.Equal(t, content, fileStore.Content(createdMedia.StorageKey))
+	assert.Equal(t, upload.ID, createdMedia.ID)
+	if assert.NotNil(t, upload.MediaID) {
+		assert.Equal(t, createdMedia.ID, *upload.MediaID)
+	}
+	for _, key := range fileStore.Keys() {
+		assert.False(t, strings.HasPrefix(key, resumableUploadPrefix), "the chunks should be removed")
+		assert.False(t, strings.HasPrefix(key, temporaryUploadPrefix), "the assembled file should be moved")
+	}
+}
+
+func Test_UploadController_Append_RetriedCompletionFindsTheCreatedMedia(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mediaService := mock_services.NewMockIMediaService(ctrl)
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	UploadController := UploadController{
+		UploadService: uploadService,
+		FileStore:     fileStore,
+		Media: &MediaController{
+			MediaService:      mediaService,
+			FileStore:         fileStore,
+			StoredFileService: newStoredFileService(t),
+		},
+		Expiration: time.Hour,
+	}
+
+	// the media was created before recording it on the upload failed
+	upload := &domain.Upload{
+		BaseObject: domain.BaseObject{ID: uuid.New()},
+		Length:     100,
+		Offset:     100,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	require.NoError(t, fileStore.Put(gocontext.Background(), chunkKey(upload.ID, 0), bytes.NewReader(make([]byte, 100)), 100))
+	uploadService.EXPECT().GetOwnedWithID(gomock.Any(), upload.ID).Return(upload, nil)
+	uploadService.EXPECT().Update(gomock.Any(), upload).Return(nil).Times(2)
+	mediaService.EXPECT().GetWithID(gomock.Any(), upload.ID).Return(&domain.Media{BaseObject: upload.BaseObject}, nil)
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = newChunkRequest(t, upload.ID, 100, http.NoBody)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: upload.ID.String()})
+
+	// act
+	UploadController.AppendUpload(context)
+	context.Writer.WriteHeaderNow()
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	if assert.NotNil(t, upload.MediaID) {
+		assert.Equal(t, upload.ID, *upload.MediaID)
+	}
+	assert.Empty(t, fileStore.Keys())
+}
+
+func Test_UploadController_Append_KeepsWhatArrivedOfAnInterruptedChunk(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	UploadController := UploadController{
+		UploadService: uploadService,
+		FileStore:     fileStore,
+		Media:         &MediaController{FileStore: fileStore, StoredFileService: newStoredFileService(t)},
+		Expiration:    time.Hour,
+	}
+
+	upload := &domain.Upload{
+		BaseObject: domain.BaseObject{ID: uuid.New()},
+		Length:     1000,
+		Offset:     200,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	uploadService.EXPECT().GetOwnedWithID(gomock.Any(), upload.ID).Return(upload, nil)
+	uploadService.EXPECT().Update(gomock.Any(), upload).Return(nil)
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	// the connection breaks after 300 bytes
+	body := io.MultiReader(bytes.NewReader(make([]byte, 300)), iotest.ErrReader(errors.New("connection reset")))
+	context.Request = newChunkRequest(t, upload.ID, 200, body)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: upload.ID.String()})
+
+	// act
+	UploadController.AppendUpload(context)
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, int64(500), upload.Offset)
+	assert.Equal(t, "500", writer.Header().Get("Upload-Offset"))
+	assert.Equal(t, []string{chunkKey(upload.ID, 200)}, fileStore.Keys())
+}
+
+// cancellingReader cancels the request it is the body of once it is read, like a client disconnecting
+type cancellingReader struct {
+	cancel gocontext.CancelFunc
+}
+
+func (reader cancellingReader) Read([]byte) (int, error) {
+	reader.cancel()
+	return 0, gocontext.Canceled
+}
+
+// cancellableFileStore fails like a remote store when the context is cancelled while the content is stored
+type cancellableFileStore struct {
+	*storage.MemoryFileStore
+}
+
+func (store cancellableFileStore) Put(ctx gocontext.Context, key string, content io.Reader, size int64) error {
+	if err := store.MemoryFileStore.Put(ctx, key, content, size); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func Test_UploadController_Append_KeepsWhatArrivedBeforeTheClientDisconnected(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	UploadController := UploadController{
+		UploadService: uploadService,
+		FileStore:     cancellableFileStore{fileStore},
+		Media:         &MediaController{FileStore: fileStore, StoredFileService: newStoredFileService(t)},
+		Expiration:    time.Hour,
+	}
+
+	upload := &domain.Upload{
+		BaseObject: domain.BaseObject{ID: uuid.New()},
+		Length:     1000,
+		Offset:     200,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	uploadService.EXPECT().GetOwnedWithID(gomock.Any(), upload.ID).Return(upload, nil)
+	uploadService.EXPECT().Update(gomock.Any(), upload).DoAndReturn(func(ctx gocontext.Context, _ *domain.Upload) error {
+		return ctx.Err()
+	})
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	requestContext, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	// the client disconnects after 300 bytes
+	body := io.MultiReader(bytes.NewReader(make([]byte, 300)), cancellingReader{cancel: cancel})
+	context.Request = newChunkRequest(t, upload.ID, 200, body).WithContext(requestContext)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: upload.ID.String()})
+
+	// act
+	UploadController.AppendUpload(context)
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, int64(500), upload.Offset)
+	assert.Equal(t, []string{chunkKey(upload.ID, 200)}, fileStore.Keys())
+}
+
+func Test_UploadController_Append_FailsOnInvalidChunks(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		upload        domain.Upload
+		offset        int64
+		body          string
+		contentType   string
+		expectedError error
+	}{
+		"offset mismatch": {
+			upload:        domain.Upload{Length: 10, Offset: 5, ExpiresAt: time.Now().Add(time.Hour)},
+			offset:        0,
+			body:          "data",
+			expectedError: &apierrors.UploadConflictError{},
+		},
+		"expired": {
+			upload:        domain.Upload{Length: 10, ExpiresAt: time.Now().Add(-time.Minute)},
+			body:          "data",
+			expectedError: &apierrors.UploadExpiredError{},
+		},
+		"exceeds length": {
+			upload:        domain.Upload{Length: 2, ExpiresAt: time.Now().Add(time.Hour)},
+			body:          "data",
+			expectedError: &apierrors.RequestTooLargeError{},
+		},
+		"wrong content type": {
+			upload:        domain.Upload{Length: 10, ExpiresAt: time.Now().Add(time.Hour)},
+			body:          "data",
+			contentType:   "application/octet-stream",
+			expectedError: &apierrors.UnsupportedContentTypeError{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uploadService := mock_services.NewMockIUploadService(ctrl)
+			fileStore := storage.NewMemoryFileStore()
+
+			UploadController := UploadController{
+				UploadService: uploadService,
+				FileStore:     fileStore,
+				Media:         &MediaController{FileStore: fileStore, StoredFileService: newStoredFileService(t)},
+			}
+
+			upload := test.upload
+			upload.ID = uuid.New()
+			uploadService.EXPECT().GetOwnedWithID(gomock.Any(), upload.ID).Return(&upload, nil).AnyTimes()
+
+			writer := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(writer)
+			context.Request = newChunkRequest(t, upload.ID, test.offset, strings.NewReader(test.body))
+			if test.contentType != "" {
+				context.Request.Header.Set("Content-Type", test.contentType)
+			}
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: upload.ID.String()})
+
+			// act
+			UploadController.AppendUpload(context)
+
+			// Assert
+			if assert.NotEmpty(t, context.Errors) {
+				assert.IsType(t, test.expectedError, context.Errors.Last().Err)
+			}
+			assert.Empty(t, fileStore.Keys())
+		})
+	}
+}
+
+func Test_UploadController_GetOffset_WritesProgress(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+	UploadController := UploadController{
+		UploadService: uploadService,
+	}
+
+	upload := &domain.Upload{
+		BaseObject: domain.BaseObject{ID: uuid.New()},
+		Length:     1000,
+		Offset:     400,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	uploadService.EXPECT().GetOwnedWithID(gomock.Any(), upload.ID).Return(upload, nil)
+
+	writer := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(writer)
+	context.Request = httptest.NewRequest(http.MethodHead, "https://example.com/uploads/"+upload.ID.String(), nil)
+	context.Request.Header.Set("Tus-Resumable", tusVersion)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: upload.ID.String()})
+
+	// act
+	UploadController.GetUploadOffset(context)
+	context.Writer.WriteHeaderNow()
+
+	// Assert
+	require.Empty(t, context.Errors)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "400", writer.Header().Get("Upload-Offset"))
+	assert.Equal(t, "1000", writer.Header().Get("Upload-Length"))
+	assert.Equal(t, "no-store", writer.Header().Get("Cache-Control"))
+	assert.Equal(t, upload.ExpiresAt.UTC().Format(http.TimeFormat), writer.Header().Get("Upload-Expires"))
+}
+
+func Test_UploadController_RemoveExpired_DeletesUploadsAndTheirChunks(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploadService := mock_services.NewMockIUploadService(ctrl)
+	fileStore := storage.NewMemoryFileStore()
+
+	UploadController := UploadController{
+		UploadService: uploadService,
+		FileStore:     fileStore,
+		Media:         &MediaController{FileStore: fileStore, StoredFileService: newStoredFileService(t)},
+	}
+
+	expired := &domain.Upload{BaseObject: domain.BaseObject{ID: uuid.New()}}
+	active := &domain.Upload{BaseObject: domain.BaseObject{ID: uuid.New()}}
+	ctx := gocontext.Background()
+	require.NoError(t, fileStore.Put(ctx, chunkKey(expired.ID, 0), strings.NewReader("expired"), 7))
+	require.NoError(t, fileStore.Put(ctx, chunkKey(active.ID, 0), strings.NewReader("active"), 6))
+
+	uploadService.EXPECT().FilterExpiredOption(gomock.Any()).Return(nil)
+	uploadService.EXPECT().Get(gomock.Any(), gomock.Any()).Return([]*domain.Upload{expired}, nil)
+	uploadService.EXPECT().Delete(gomock.Any(), expired.ID).Return(nil)
+
+	// act
+	err := UploadController.RemoveExpired(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{chunkKey(active.ID, 0)}, fileStore.Keys())
+}
@@ -9,3 +9,14 @@ func EncodeSlice[Source, Target any, F func(*Source) *Target](source []*Source,
 
 	return slice
 }
+
+// EncodeValues converts the source like EncodeSlice, but returns the values instead of pointers
+func EncodeValues[Source, Target any, F func(*Source) *Target](source []*Source, convert F) []Target {
+	slice := make([]Target, len(source))
+
+	for i, item := range source {
+		slice[i] = *convert(item)
+	}
+
+	return slice
+}
@@ -1,19 +1,115 @@
 package conversion
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
+
 	"github.com/TheSandyDave/Media-Tags/domain"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
+	"github.com/google/uuid"
 )
 
-func EncodeMedia(source *domain.Media) *restgen.Media {
+// MediaURLs build the URLs the files of media are downloaded from
+type MediaURLs struct {
+	// File returns the URL the stored file with the key is downloaded from
+	File func(key string) string
+	// Content returns the URL the file of the media with the ID is downloaded from under the name of the media
+	Content func(id uuid.UUID) string
+}
+
+func EncodeMedia(source *domain.Media, urls MediaURLs) *restgen.Media {
 	tags := make([]string, len(source.Tags))
 	for i, tag := range source.Tags {
 		tags[i] = tag.Name
 	}
+	thumbnails := EncodeValues(sortedThumbnails(source.Thumbnails), func(thumbnail *domain.Thumbnail) *restgen.Thumbnail {
+		return EncodeThumbnail(thumbnail, urls)
+	})
 	return &restgen.Media{
-		Id:      source.ID.String(),
-		Name:    source.Name,
-		Tags:    tags,
-		FileUrl: source.FileUrl,
+		Id:             source.ID.String(),
+		Name:           source.Name,
+		Owner:          source.OwnerID,
+		Tags:           tags,
+		FileUrl:        urls.File(source.StorageKey),
+		ContentUrl:     urls.Content(source.ID),
+		ContentType:    source.ContentType,
+		Kind:           string(source.Kind),
+		Thumbnails:     thumbnails,
+		Metadata:       EncodeMediaMetadata(source.Metadata),
+		ContentHash:    source.ContentHash,
+		PerceptualHash: encodePerceptualHash(source.PerceptualHash),
+	}
+}
+
+func encodePerceptualHash(hash *int64) string {
+	if hash == nil {
+		return ""
+	}
+	return fmt.Sprintf("%016x", uint64(*hash))
+}
+
+func EncodeSimilarMedia(source *domain.SimilarMedia, urls MediaURLs) *restgen.SimilarMedia {
+	return &restgen.SimilarMedia{
+		Distance: int32(source.Distance),
+		Media:    *EncodeMedia(source.Media, urls),
+	}
+}
+
+func EncodeMediaMetadata(source *domain.MediaMetadata) *restgen.MediaMetadata {
+	if source == nil {
+		return nil
+	}
+
+	encoded := &restgen.MediaMetadata{
+		Width:       encodeInt(source.Width),
+		Height:      encodeInt(source.Height),
+		Orientation: encodeInt(source.Orientation),
+	}
+	if !source.ExifStripped {
+		encoded.CameraMake = source.CameraMake
+		encoded.CameraModel = source.CameraModel
+		encoded.TakenAt = source.TakenAt
+	}
+	if !source.ExifStripped && !source.LocationStripped {
+		encoded.Latitude = source.Latitude
+		encoded.Longitude = source.Longitude
+	}
+
+	return encoded
+}
+
+func encodeInt(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
+
+func EncodeThumbnail(source *domain.Thumbnail, urls MediaURLs) *restgen.Thumbnail {
+	return &restgen.Thumbnail{
+		Size:        int32(source.Size),
+		Width:       int32(source.Width),
+		Height:      int32(source.Height),
+		ContentType: source.ContentType,
+		Url:         urls.File(source.StorageKey),
+	}
+}
+
+// sortedThumbnails orders the thumbnails from small to large
+func sortedThumbnails(thumbnails []*domain.Thumbnail) []*domain.Thumbnail {
+	return slices.SortedFunc(slices.Values(thumbnails), func(a, b *domain.Thumbnail) int {
+		return cmp.Compare(a.Size, b.Size)
+	})
+}
+
+func EncodeMediaPage(source *domain.Page[domain.Media], urls MediaURLs) *restgen.MediaPage {
+	return &restgen.MediaPage{
+		Items: EncodeValues(source.Items, func(media *domain.Media) *restgen.Media {
+			return EncodeMedia(media, urls)
+		}),
+		NextCursor: source.NextCursor,
+		Total:      source.Total,
 	}
 }
@@ -1,13 +1,38 @@
 package conversion
 
 import (
+	"slices"
+
 	"github.com/TheSandyDave/Media-Tags/domain"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
 )
 
 func EncodeTag(source *domain.Tag) *restgen.Tag {
+	var parentID *string
+	if source.ParentID != nil {
+		id := source.ParentID.String()
+		parentID = &id
+	}
+
+	var aliases []string
+	for _, alias := range source.Aliases {
+		aliases = append(aliases, alias.Name)
+	}
+	slices.Sort(aliases)
+
 	return &restgen.Tag{
-		Id:   source.ID.String(),
-		Name: source.Name,
+		Id:       source.ID.String(),
+		Name:     source.Name,
+		Owner:    source.OwnerID,
+		ParentId: parentID,
+		Aliases:  aliases,
+	}
+}
+
+func EncodeTagPage(source *domain.Page[domain.Tag]) *restgen.TagPage {
+	return &restgen.TagPage{
+		Items:      EncodeValues(source.Items, EncodeTag),
+		NextCursor: source.NextCursor,
+		Total:      source.Total,
 	}
 }
@@ -0,0 +1,10 @@
+package domain
+
+// APIKey is a static credential of a client of the API, only the hash of the key is stored
+type APIKey struct {
+	BaseObject
+	Name string `gorm:"uniqueIndex"`
+	Hash string `gorm:"uniqueIndex"`
+	// Role is the role of requests made with the key, one of viewer, uploader or admin
+	Role string
+}
@@ -30,6 +30,11 @@ func (baseObject *BaseObject) BeforeCreate(_ *gorm.DB) error {
 	if baseObject.ID == uuid.Nil {
 		baseObject.ID = uuid.New()
 	}
+	// SQLite stores times as text including the offset, which only sorts and compares correctly in a single offset
+	if baseObject.CreatedAt.IsZero() {
+		baseObject.CreatedAt = time.Now()
+	}
+	baseObject.CreatedAt = baseObject.CreatedAt.UTC()
 	return nil
 }
 
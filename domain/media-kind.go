@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+)
+
+// MediaKind is the broad category of a media file, each kind has its own upload restrictions
+type MediaKind string
+
+const (
+	MediaKindImage    MediaKind = "image"
+	MediaKindVideo    MediaKind = "video"
+	MediaKindAudio    MediaKind = "audio"
+	MediaKindDocument MediaKind = "document"
+)
+
+// MediaKinds lists every kind
+var MediaKinds = []MediaKind{MediaKindImage, MediaKindVideo, MediaKindAudio, MediaKindDocument}
+
+// documentTypes are the application types treated as documents, on top of every text type
+var documentTypes = []string{
+	"application/pdf",
+	"application/rtf",
+	"application/msword",
+	"application/vnd.ms-excel",
+	"application/vnd.ms-powerpoint",
+	"application/epub+zip",
+}
+
+// documentTypePrefixes cover the families of office document types
+var documentTypePrefixes = []string{
+	"application/vnd.openxmlformats-officedocument.",
+	"application/vnd.oasis.opendocument.",
+}
+
+// ParseMediaKind returns the kind with the given name
+func ParseMediaKind(name string) (MediaKind, bool) {
+	kind := MediaKind(name)
+	return kind, slices.Contains(MediaKinds, kind)
+}
+
+// MediaKindOf returns the kind of files with the MIME type, type/* wildcards are supported as well.
+// The second return value is false for types that don't belong to any kind
+func MediaKindOf(mimeType string) (MediaKind, bool) {
+	mediaType, _, _ := strings.Cut(mimeType, "/")
+	switch mediaType {
+	case "image":
+		return MediaKindImage, true
+	case "video":
+		return MediaKindVideo, true
+	case "audio":
+		return MediaKindAudio, true
+	case "text":
+		return MediaKindDocument, true
+	}
+
+	if slices.Contains(documentTypes, mimeType) || slices.ContainsFunc(documentTypePrefixes, func(prefix string) bool {
+		return strings.HasPrefix(mimeType, prefix)
+	}) {
+		return MediaKindDocument, true
+	}
+
+	return "", false
+}
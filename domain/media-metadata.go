@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MediaMetadata is extracted from the uploaded file, fields that the file does not contain are nil
+type MediaMetadata struct {
+	BaseObject
+	MediaID uuid.UUID `gorm:"uniqueIndex"`
+	// Width and Height of the stored pixels, an Orientation of 5 to 8 means the image is displayed rotated
+	Width       *int
+	Height      *int
+	Orientation *int
+	CameraMake  *string
+	CameraModel *string
+	TakenAt     *time.Time `gorm:"index"`
+	Latitude    *float64
+	Longitude   *float64
+	// LocationStripped and ExifStripped mark fields that were removed from the stored file, they are kept for
+	// filtering but not exposed
+	LocationStripped bool
+	ExifStripped     bool
+}
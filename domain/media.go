@@ -2,7 +2,28 @@ package domain
 
 type Media struct {
 	BaseObject
-	Name    string
-	Tags    []*Tag `gorm:"many2many:media_tags;constaint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	FileUrl string
+	Owned
+	Name string
+	Tags []*Tag `gorm:"many2many:media_tags;constaint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// StorageKey is derived from the ContentHash so media with the same content share the stored file, the URL of
+	// the file is derived from it whenever the media is returned
+	StorageKey string
+	// ContentHash is the hex encoded SHA-256 of the stored file, empty for media uploaded before hashing
+	ContentHash string `gorm:"index"`
+	// ContentType is the MIME type detected from the content of the file
+	ContentType string
+	// Kind is derived from the content type, media uploaded before kinds existed were always images
+	Kind MediaKind `gorm:"index;default:image"`
+	// Thumbnails are only generated for images
+	Thumbnails []*Thumbnail
+	// Metadata is only extracted from images
+	Metadata *MediaMetadata
+	// PerceptualHash holds the bits of the difference hash of an image, signed since SQLite integers are
+	PerceptualHash *int64
+}
+
+// SimilarMedia is media whose perceptual hash is within Distance of the hash searched for
+type SimilarMedia struct {
+	Media    *Media
+	Distance int
 }
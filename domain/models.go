@@ -3,4 +3,11 @@ package domain
 var Models = []any{
 	Media{},
 	Tag{},
+	TagAlias{},
+	Thumbnail{},
+	MediaMetadata{},
+	PerceptualHashBand{},
+	Upload{},
+	APIKey{},
+	StoredFile{},
 }
@@ -0,0 +1,8 @@
+package domain
+
+// Owned records who created a record, only the owner and admins can change it
+type Owned struct {
+	// OwnerID is the subject of the principal that created the record, apikey:<id> or jwt:<iss>:<sub>. Records
+	// created without authentication have no owner. Media created from an upload is owned by the owner of the upload
+	OwnerID string `gorm:"index"`
+}
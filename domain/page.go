@@ -0,0 +1,9 @@
+package domain
+
+// Page is a single page of results, NextCursor is empty on the last page
+type Page[T any] struct {
+	Items      []*T
+	NextCursor string
+	// Total number of results across all pages, only counted for the first page
+	Total *int64
+}
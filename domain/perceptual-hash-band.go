@@ -0,0 +1,13 @@
+package domain
+
+import "github.com/google/uuid"
+
+// PerceptualHashBand is a 16 bit part of the perceptual hash of media, indexed so media with a similar hash are
+// found without comparing against every hash
+type PerceptualHashBand struct {
+	BaseObject
+	MediaID uuid.UUID `gorm:"index"`
+	// Band is the position of the part within the hash, from 0 for the lowest bits
+	Band  int `gorm:"index:idx_perceptual_hash_bands_band_value,priority:1"`
+	Value int `gorm:"index:idx_perceptual_hash_bands_band_value,priority:2"`
+}
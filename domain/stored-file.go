@@ -0,0 +1,10 @@
+package domain
+
+// StoredFile counts the references to a file of the file store. Media with the same content share their stored
+// files, a file is only removed once nothing refers to it anymore
+type StoredFile struct {
+	StorageKey string `gorm:"primaryKey"`
+	// ReferenceCount is the number of media and thumbnails referring to the file, along with the uploads that are
+	// still storing it
+	ReferenceCount int64
+}
@@ -1,6 +1,19 @@
 package domain
 
+import "github.com/google/uuid"
+
 type Tag struct {
 	BaseObject
+	Owned
 	Name string `gorm:"unique"`
+	// ParentID places the tag below another tag in the hierarchy, nil for root tags
+	ParentID *uuid.UUID `gorm:"index"`
+	Aliases  []*TagAlias
+}
+
+// TagAlias is an alternative name which resolves to its tag in lookups and filters
+type TagAlias struct {
+	BaseObject
+	Name  string    `gorm:"unique"`
+	TagID uuid.UUID `gorm:"index"`
 }
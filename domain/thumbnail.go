@@ -0,0 +1,15 @@
+package domain
+
+import "github.com/google/uuid"
+
+// Thumbnail is a downscaled preview of an image, stored next to the original file
+type Thumbnail struct {
+	BaseObject
+	MediaID uuid.UUID `gorm:"index"`
+	// Size is the configured size the thumbnail fits within, the actual dimensions keep the aspect ratio
+	Size        int
+	Width       int
+	Height      int
+	ContentType string
+	StorageKey  string
+}
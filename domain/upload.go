@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload is a resumable upload whose file is received in chunks, the media is created once all of it has arrived
+type Upload struct {
+	BaseObject
+	Owned
+	// Length is the size in bytes of the complete file
+	Length int64
+	// Offset is the number of bytes received so far
+	Offset int64
+	// Name, Tags and Strip are the fields of the media created from the upload
+	Name  string
+	Tags  []string `gorm:"serializer:json"`
+	Strip []string `gorm:"serializer:json"`
+	// Filename and FileType are the name and type the client claims the file has
+	Filename string
+	FileType string
+	// ExpiresAt is the time after which an unfinished upload can no longer be resumed and is removed
+	ExpiresAt time.Time `gorm:"index"`
+	// MediaID refers to the media created from the upload once it is complete
+	MediaID *uuid.UUID
+}
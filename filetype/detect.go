@@ -0,0 +1,122 @@
+// Package filetype detects the type of uploaded content from its magic bytes and checks it against an allowlist
+package filetype
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// SniffLength is the number of bytes at the start of the content inspected to detect its type
+const SniffLength = 3072
+
+// octetStream is sent by clients that don't know the type of the file, it is not treated as a claim
+const octetStream = "application/octet-stream"
+
+var (
+	ErrTypeNotAllowed      = errors.New("file type is not allowed")
+	ErrExtensionNotAllowed = errors.New("file extension is not allowed")
+	ErrTypeMismatch        = errors.New("claimed file type does not match the content")
+)
+
+// Type is a detected content type
+type Type struct {
+	// MIMEType without parameters, e.g. image/png
+	MIMEType string
+	// Extension is the canonical extension of the type including the dot, empty if the type has none
+	Extension string
+}
+
+// Detect reads the start of the content to detect its type, the returned reader yields the complete content
+// including the inspected bytes so the content can still be streamed elsewhere
+func Detect(content io.Reader) (*Type, io.Reader, error) {
+	header := make([]byte, SniffLength)
+	read, err := io.ReadFull(content, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	header = header[:read]
+
+	detected := mimetype.Detect(header)
+	mimeType, _, err := mime.ParseMediaType(detected.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Type{MIMEType: mimeType, Extension: detected.Extension()}, io.MultiReader(bytes.NewReader(header), content), nil
+}
+
+// Allowlist restricts the types of uploaded files
+type Allowlist struct {
+	// MIMETypes lists the allowed types, either exact like image/png or all subtypes like image/*
+	MIMETypes []string
+	// Extensions lists the allowed file name extensions including the dot, compared case insensitively
+	Extensions []string
+}
+
+// Claimed returns the type the client claims the file has, from the Content-Type of the part if it is specific
+// and from the extension of the file name otherwise, empty if there is no claim
+func Claimed(contentType string, filename string) string {
+	if mimeType, _, err := mime.ParseMediaType(contentType); err == nil && mimeType != octetStream {
+		return mimeType
+	}
+
+	if mimeType, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(filename))); err == nil {
+		return mimeType
+	}
+
+	return ""
+}
+
+// Check verifies the detected type and the extension of the file name are allowed, and that neither the claimed
+// type nor the extension contradicts the detected type
+func (allowlist Allowlist) Check(detected *Type, claimedType string, filename string) error {
+	if !allowlist.allowsType(detected.MIMEType) {
+		return ErrTypeNotAllowed
+	}
+
+	extension := strings.ToLower(filepath.Ext(filename))
+	if !slices.ContainsFunc(allowlist.Extensions, func(allowed string) bool {
+		return strings.ToLower(allowed) == extension
+	}) {
+		return ErrExtensionNotAllowed
+	}
+
+	if claimedType != "" && !matches(detected.MIMEType, claimedType) {
+		return ErrTypeMismatch
+	}
+	if extensionType := mime.TypeByExtension(extension); extensionType != "" && !matches(detected.MIMEType, extensionType) {
+		return ErrTypeMismatch
+	}
+
+	return nil
+}
+
+func (allowlist Allowlist) allowsType(mimeType string) bool {
+	return slices.ContainsFunc(allowlist.MIMETypes, func(allowed string) bool {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			return strings.HasPrefix(mimeType, prefix+"/")
+		}
+		return allowed == mimeType
+	})
+}
+
+// matches reports whether the claimed type is the detected type or one of its aliases
+func matches(detected string, claimed string) bool {
+	claimed, _, err := mime.ParseMediaType(claimed)
+	if err != nil {
+		return false
+	}
+
+	detectedType := mimetype.Lookup(detected)
+	if detectedType == nil {
+		return detected == claimed
+	}
+	return detectedType.Is(claimed)
+}
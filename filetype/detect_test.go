@@ -0,0 +1,118 @@
+package filetype
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Detect_DetectsTypeAndKeepsContent(t *testing.T) {
+	t.Parallel()
+
+	png, err := os.ReadFile("../controllers/test-resources/test.png")
+	require.NoError(t, err)
+	large := append(bytes.Clone(png), bytes.Repeat([]byte{0}, 2*SniffLength)...)
+
+	testCases := map[string]struct {
+		content  []byte
+		expected Type
+	}{
+		"png":               {content: png, expected: Type{MIMEType: "image/png", Extension: ".png"}},
+		"larger than sniff": {content: large, expected: Type{MIMEType: "image/png", Extension: ".png"}},
+		"text":              {content: []byte("just some text"), expected: Type{MIMEType: "text/plain", Extension: ".txt"}},
+		"empty":             {content: []byte{}, expected: Type{MIMEType: "text/plain", Extension: ".txt"}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			detected, content, err := Detect(bytes.NewReader(testCase.content))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, *detected)
+			replayed, err := io.ReadAll(content)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.content, replayed)
+		})
+	}
+}
+
+func Test_Claimed_PrefersSpecificContentType(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "image/png", Claimed("image/png", "photo.jpg"))
+	assert.Equal(t, "image/jpeg", Claimed("application/octet-stream", "photo.jpg"))
+	assert.Equal(t, "image/jpeg", Claimed("", "photo.JPG"))
+	assert.Equal(t, "", Claimed("", "photo"))
+}
+
+func Test_Allowlist_Check(t *testing.T) {
+	t.Parallel()
+
+	allowlist := Allowlist{
+		MIMETypes:  []string{"image/png", "video/*"},
+		Extensions: []string{".png", ".MP4", ".jpg"},
+	}
+	png := &Type{MIMEType: "image/png", Extension: ".png"}
+
+	testCases := map[string]struct {
+		detected *Type
+		claimed  string
+		filename string
+		expected error
+	}{
+		"allowed":                 {detected: png, claimed: "image/png", filename: "a.png"},
+		"no claim":                {detected: png, claimed: "", filename: "a.png"},
+		"wildcard type":           {detected: &Type{MIMEType: "video/mp4"}, claimed: "video/mp4", filename: "a.mp4"},
+		"type not allowed":        {detected: &Type{MIMEType: "text/plain"}, claimed: "image/png", filename: "a.png", expected: ErrTypeNotAllowed},
+		"extension not allowed":   {detected: png, claimed: "image/png", filename: "a.gif", expected: ErrExtensionNotAllowed},
+		"missing extension":       {detected: png, claimed: "image/png", filename: "a", expected: ErrExtensionNotAllowed},
+		"claimed type mismatch":   {detected: png, claimed: "image/jpeg", filename: "a.png", expected: ErrTypeMismatch},
+		"extension type mismatch": {detected: png, claimed: "", filename: "a.jpg", expected: ErrTypeMismatch},
+		"claimed with parameters": {detected: png, claimed: "image/png; name=a", filename: "a.png"},
+		"invalid claimed type":    {detected: png, claimed: "png", filename: "a.png", expected: ErrTypeMismatch},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := allowlist.Check(testCase.detected, testCase.claimed, testCase.filename)
+
+			// Assert
+			if testCase.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, testCase.expected)
+			}
+		})
+	}
+}
+
+func Test_Detect_ReturnsReadErrors(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := io.MultiReader(strings.NewReader("abc"), errorReader{})
+
+	// Act
+	_, _, err := Detect(content)
+
+	// Assert
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+}
+
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
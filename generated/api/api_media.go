@@ -16,6 +16,13 @@ import (
 type MediaAPI struct {
 }
 
+// Post /media/:id/tags
+// Attach tags to a media item
+func (api *MediaAPI) AddMediaTags(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
 // Post /media
 // Create new media
 func (api *MediaAPI) CreateMedia(c *gin.Context) {
@@ -23,6 +30,13 @@ func (api *MediaAPI) CreateMedia(c *gin.Context) {
 	c.JSON(200, gin.H{"status": "OK"})
 }
 
+// Delete /media/:id
+// Delete a media item and its stored file
+func (api *MediaAPI) DeleteMedia(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
 // Get /media
 // Get all media items
 func (api *MediaAPI) GetMedia(c *gin.Context) {
@@ -36,3 +50,59 @@ func (api *MediaAPI) GetMediaById(c *gin.Context) {
 	// Your handler implementation
 	c.JSON(200, gin.H{"status": "OK"})
 }
+
+// Get /media/:id/content
+// Download the file of a media item
+func (api *MediaAPI) GetMediaContent(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Get /media/:id/similar
+// Find images that look like an image
+func (api *MediaAPI) GetSimilarMedia(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Head /media/:id/content
+// Retrieve the headers of the file of a media item
+func (api *MediaAPI) HeadMediaContent(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Delete /media/:id/tags/:tagId
+// Detach a tag from a media item
+func (api *MediaAPI) RemoveMediaTag(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Get /media/:id/render
+// Render an image at another size or format
+func (api *MediaAPI) RenderMedia(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Put /media/:id
+// Replace the fields of a media item
+func (api *MediaAPI) ReplaceMedia(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Put /media/:id/tags
+// Replace all tags of a media item
+func (api *MediaAPI) ReplaceMediaTags(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Patch /media/:id
+// Update a media item
+func (api *MediaAPI) UpdateMedia(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
@@ -23,6 +23,13 @@ func (api *TagsAPI) CreateTag(c *gin.Context) {
 	c.JSON(200, gin.H{"status": "OK"})
 }
 
+// Delete /tags/:id
+// Delete a tag, detaching it from all media
+func (api *TagsAPI) DeleteTag(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
 // Get /tags/:id
 // Get a tag by ID
 func (api *TagsAPI) GetTagById(c *gin.Context) {
@@ -30,9 +37,44 @@ func (api *TagsAPI) GetTagById(c *gin.Context) {
 	c.JSON(200, gin.H{"status": "OK"})
 }
 
+// Get /tags/:id/subtree
+// Get a tag and all its descendants, ordered by depth and name
+func (api *TagsAPI) GetTagSubtree(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
 // Get /tags
 // Get all tags
 func (api *TagsAPI) GetTags(c *gin.Context) {
 	// Your handler implementation
 	c.JSON(200, gin.H{"status": "OK"})
 }
+
+// Post /tags/:id/merge
+// Merge a tag into another tag
+func (api *TagsAPI) MergeTag(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Put /tags/:id
+// Replace the name and parent of a tag
+func (api *TagsAPI) ReplaceTag(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Put /tags/:id/aliases
+// Replace all aliases of a tag
+func (api *TagsAPI) ReplaceTagAliases(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Patch /tags/:id
+// Update a tag
+func (api *TagsAPI) UpdateTag(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
@@ -0,0 +1,52 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type UploadsAPI struct {
+}
+
+// Patch /uploads/:id
+// Send a chunk of an upload
+func (api *UploadsAPI) AppendUpload(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Post /uploads
+// Start a resumable upload
+func (api *UploadsAPI) CreateUpload(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Delete /uploads/:id
+// Terminate an upload
+func (api *UploadsAPI) DeleteUpload(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Head /uploads/:id
+// Get the offset to resume an upload at
+func (api *UploadsAPI) GetUploadOffset(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
+
+// Options /uploads
+// Describe the supported tus protocol
+func (api *UploadsAPI) GetUploadOptions(c *gin.Context) {
+	// Your handler implementation
+	c.JSON(200, gin.H{"status": "OK"})
+}
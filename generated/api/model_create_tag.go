@@ -11,4 +11,10 @@ package restgen
 
 type CreateTag struct {
 	Name string `json:"name"`
+
+	// ID of the parent tag, absent for root tags
+	ParentId *string `json:"parentId,omitempty"`
+
+	// Alternative names resolving to the tag
+	Aliases []string `json:"aliases,omitempty"`
 }
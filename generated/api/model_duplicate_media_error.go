@@ -0,0 +1,17 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type DuplicateMediaError struct {
+	Error string `json:"error"`
+
+	// ID of the media with the same content
+	ExistingMedia string `json:"existingMedia"`
+}
@@ -14,7 +14,34 @@ type Media struct {
 
 	Name string `json:"name"`
 
+	// Subject of the API key or token that uploaded the media, apikey:<id> or jwt:<iss>:<sub>. Only the owner and admins can change it. Absent for media uploaded without authentication
+	Owner string `json:"owner,omitempty"`
+
 	Tags []string `json:"tags,omitempty"`
 
+	// Signed URL of the file starting with the configured public base URL, or the URL of the request when none is configured. It stops working once the expires timestamp has passed, fetch the media again for a fresh URL
 	FileUrl string `json:"fileUrl"`
+
+	// Signed URL the file is downloaded from under the name of the media, it expires like the fileUrl
+	ContentUrl string `json:"contentUrl"`
+
+	// MIME type detected from the content of the file
+	ContentType string `json:"contentType,omitempty"`
+
+	// The broad category of the media file, decided by the detected content type
+	Kind string `json:"kind"`
+
+	// Downscaled previews ordered by size, only generated for images
+	Thumbnails []Thumbnail `json:"thumbnails,omitempty"`
+
+	Metadata *MediaMetadata `json:"metadata,omitempty"`
+
+	// Hex encoded SHA-256 of the stored file, absent for media uploaded before hashing
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// Hex encoded 64 bit difference hash, only computed for images
+	PerceptualHash string `json:"perceptualHash,omitempty"`
+
+	// Only set in the response to an upload, the existing media with the same content
+	DuplicateOf *string `json:"duplicateOf,omitempty"`
 }
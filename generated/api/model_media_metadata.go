@@ -0,0 +1,35 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+import (
+	"time"
+)
+
+// MediaMetadata - Extracted from uploaded images, fields the file does not contain or that were stripped from the stored file are absent
+type MediaMetadata struct {
+	Width *int32 `json:"width,omitempty"`
+
+	Height *int32 `json:"height,omitempty"`
+
+	// EXIF orientation from 1 to 8, 5 to 8 mean the image is displayed rotated by 90 degrees
+	Orientation *int32 `json:"orientation,omitempty"`
+
+	CameraMake *string `json:"cameraMake,omitempty"`
+
+	CameraModel *string `json:"cameraModel,omitempty"`
+
+	// Capture time, a time without an offset in the file is assumed to be in UTC
+	TakenAt *time.Time `json:"takenAt,omitempty"`
+
+	Latitude *float64 `json:"latitude,omitempty"`
+
+	Longitude *float64 `json:"longitude,omitempty"`
+}
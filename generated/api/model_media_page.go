@@ -0,0 +1,20 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type MediaPage struct {
+	Items []Media `json:"items"`
+
+	// Cursor of the next page, absent on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// Total number of media items across all pages, only returned for the first page
+	Total *int64 `json:"total,omitempty"`
+}
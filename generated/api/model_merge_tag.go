@@ -0,0 +1,15 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type MergeTag struct {
+	// ID of the tag to merge into
+	TargetId string `json:"targetId"`
+}
@@ -0,0 +1,17 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type ReplaceTag struct {
+	Name string `json:"name"`
+
+	// ID of the parent tag, absent for root tags
+	ParentId string `json:"parentId,omitempty"`
+}
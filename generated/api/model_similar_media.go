@@ -0,0 +1,17 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type SimilarMedia struct {
+	// Number of bits the perceptual hashes differ in
+	Distance int32 `json:"distance"`
+
+	Media Media `json:"media"`
+}
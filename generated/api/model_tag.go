@@ -13,4 +13,13 @@ type Tag struct {
 	Id string `json:"id"`
 
 	Name string `json:"name"`
+
+	// Subject of the API key or token that created the tag, apikey:<id> or jwt:<iss>:<sub>. Only the owner and admins can change it. Absent for tags created without authentication
+	Owner string `json:"owner,omitempty"`
+
+	// ID of the parent tag, absent for root tags
+	ParentId *string `json:"parentId,omitempty"`
+
+	// Alternative names resolving to the tag
+	Aliases []string `json:"aliases,omitempty"`
 }
@@ -0,0 +1,25 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type Thumbnail struct {
+	// The configured size in pixels the width and height of the thumbnail fit within
+	Size int32 `json:"size"`
+
+	Width int32 `json:"width"`
+
+	Height int32 `json:"height"`
+
+	// image/jpeg, or image/png for images with transparency
+	ContentType string `json:"contentType"`
+
+	// Signed URL of the thumbnail, it expires like the fileUrl of the media
+	Url string `json:"url"`
+}
@@ -0,0 +1,14 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type UpdateMedia struct {
+	Name string `json:"name,omitempty"`
+}
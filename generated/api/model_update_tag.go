@@ -0,0 +1,17 @@
+/*
+ * Tag and Media API
+ *
+ * API for managing tags and media items
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package restgen
+
+type UpdateTag struct {
+	Name string `json:"name,omitempty"`
+
+	// ID of the new parent tag, an empty string turns the tag into a root tag and leaving it out keeps the current parent
+	ParentId *string `json:"parentId,omitempty"`
+}
@@ -31,22 +31,71 @@ type Route struct {
 type Routes []Route
 
 type Handlers struct {
+	AddMediaTags func(c *gin.Context)
+
 	CreateMedia func(c *gin.Context)
 
+	DeleteMedia func(c *gin.Context)
+
 	GetMedia func(c *gin.Context)
 
 	GetMediaById func(c *gin.Context)
 
+	GetMediaContent func(c *gin.Context)
+
+	GetSimilarMedia func(c *gin.Context)
+
+	HeadMediaContent func(c *gin.Context)
+
+	RemoveMediaTag func(c *gin.Context)
+
+	RenderMedia func(c *gin.Context)
+
+	ReplaceMedia func(c *gin.Context)
+
+	ReplaceMediaTags func(c *gin.Context)
+
+	UpdateMedia func(c *gin.Context)
+
 	CreateTag func(c *gin.Context)
 
+	DeleteTag func(c *gin.Context)
+
 	GetTagById func(c *gin.Context)
 
+	GetTagSubtree func(c *gin.Context)
+
 	GetTags func(c *gin.Context)
+
+	MergeTag func(c *gin.Context)
+
+	ReplaceTag func(c *gin.Context)
+
+	ReplaceTagAliases func(c *gin.Context)
+
+	UpdateTag func(c *gin.Context)
+
+	AppendUpload func(c *gin.Context)
+
+	CreateUpload func(c *gin.Context)
+
+	DeleteUpload func(c *gin.Context)
+
+	GetUploadOffset func(c *gin.Context)
+
+	GetUploadOptions func(c *gin.Context)
 }
 
 func GetRoutes(handlers Handlers) Routes {
 	return Routes{
 
+		{
+			"AddMediaTags",
+			http.MethodPost,
+			"/media/:id/tags",
+			handlers.AddMediaTags,
+		},
+
 		{
 			"CreateMedia",
 			http.MethodPost,
@@ -54,6 +103,13 @@ func GetRoutes(handlers Handlers) Routes {
 			handlers.CreateMedia,
 		},
 
+		{
+			"DeleteMedia",
+			http.MethodDelete,
+			"/media/:id",
+			handlers.DeleteMedia,
+		},
+
 		{
 			"GetMedia",
 			http.MethodGet,
@@ -68,6 +124,62 @@ func GetRoutes(handlers Handlers) Routes {
 			handlers.GetMediaById,
 		},
 
+		{
+			"GetMediaContent",
+			http.MethodGet,
+			"/media/:id/content",
+			handlers.GetMediaContent,
+		},
+
+		{
+			"GetSimilarMedia",
+			http.MethodGet,
+			"/media/:id/similar",
+			handlers.GetSimilarMedia,
+		},
+
+		{
+			"HeadMediaContent",
+			http.MethodHead,
+			"/media/:id/content",
+			handlers.HeadMediaContent,
+		},
+
+		{
+			"RemoveMediaTag",
+			http.MethodDelete,
+			"/media/:id/tags/:tagId",
+			handlers.RemoveMediaTag,
+		},
+
+		{
+			"RenderMedia",
+			http.MethodGet,
+			"/media/:id/render",
+			handlers.RenderMedia,
+		},
+
+		{
+			"ReplaceMedia",
+			http.MethodPut,
+			"/media/:id",
+			handlers.ReplaceMedia,
+		},
+
+		{
+			"ReplaceMediaTags",
+			http.MethodPut,
+			"/media/:id/tags",
+			handlers.ReplaceMediaTags,
+		},
+
+		{
+			"UpdateMedia",
+			http.MethodPatch,
+			"/media/:id",
+			handlers.UpdateMedia,
+		},
+
 		{
 			"CreateTag",
 			http.MethodPost,
@@ -75,6 +187,13 @@ func GetRoutes(handlers Handlers) Routes {
 			handlers.CreateTag,
 		},
 
+		{
+			"DeleteTag",
+			http.MethodDelete,
+			"/tags/:id",
+			handlers.DeleteTag,
+		},
+
 		{
 			"GetTagById",
 			http.MethodGet,
@@ -82,12 +201,82 @@ func GetRoutes(handlers Handlers) Routes {
 			handlers.GetTagById,
 		},
 
+		{
+			"GetTagSubtree",
+			http.MethodGet,
+			"/tags/:id/subtree",
+			handlers.GetTagSubtree,
+		},
+
 		{
 			"GetTags",
 			http.MethodGet,
 			"/tags",
 			handlers.GetTags,
 		},
+
+		{
+			"MergeTag",
+			http.MethodPost,
+			"/tags/:id/merge",
+			handlers.MergeTag,
+		},
+
+		{
+			"ReplaceTag",
+			http.MethodPut,
+			"/tags/:id",
+			handlers.ReplaceTag,
+		},
+
+		{
+			"ReplaceTagAliases",
+			http.MethodPut,
+			"/tags/:id/aliases",
+			handlers.ReplaceTagAliases,
+		},
+
+		{
+			"UpdateTag",
+			http.MethodPatch,
+			"/tags/:id",
+			handlers.UpdateTag,
+		},
+
+		{
+			"AppendUpload",
+			http.MethodPatch,
+			"/uploads/:id",
+			handlers.AppendUpload,
+		},
+
+		{
+			"CreateUpload",
+			http.MethodPost,
+			"/uploads",
+			handlers.CreateUpload,
+		},
+
+		{
+			"DeleteUpload",
+			http.MethodDelete,
+			"/uploads/:id",
+			handlers.DeleteUpload,
+		},
+
+		{
+			"GetUploadOffset",
+			http.MethodHead,
+			"/uploads/:id",
+			handlers.GetUploadOffset,
+		},
+
+		{
+			"GetUploadOptions",
+			http.MethodOptions,
+			"/uploads",
+			handlers.GetUploadOptions,
+		},
 	}
 }
 
@@ -0,0 +1,464 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: api-key-service.go
+//
+// Generated by this command:
+//
+//	mockgen -source api-key-service.go -typed -destination ../generated/mock/services/mock_api-key-service.go IAPIKeyService
+//
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/TheSandyDave/Media-Tags/domain"
+	services "github.com/TheSandyDave/Media-Tags/services"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIAPIKeyService is a mock of IAPIKeyService interface.
+type MockIAPIKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAPIKeyServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockIAPIKeyServiceMockRecorder is the mock recorder for MockIAPIKeyService.
+type MockIAPIKeyServiceMockRecorder struct {
+	mock *MockIAPIKeyService
+}
+
+// NewMockIAPIKeyService creates a new mock instance.
+func NewMockIAPIKeyService(ctrl *gomock.Controller) *MockIAPIKeyService {
+	mock := &MockIAPIKeyService{ctrl: ctrl}
+	mock.recorder = &MockIAPIKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAPIKeyService) EXPECT() *MockIAPIKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIAPIKeyService) Create(ctx context.Context, item ...*domain.APIKey) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range item {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIAPIKeyServiceMockRecorder) Create(ctx any, item ...any) *MockIAPIKeyServiceCreateCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, item...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAPIKeyService)(nil).Create), varargs...)
+	return &MockIAPIKeyServiceCreateCall{Call: call}
+}
+
+// MockIAPIKeyServiceCreateCall wrap *gomock.Call
+type MockIAPIKeyServiceCreateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceCreateCall) Return(arg0 error) *MockIAPIKeyServiceCreateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceCreateCall) Do(f func(context.Context, ...*domain.APIKey) error) *MockIAPIKeyServiceCreateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceCreateCall) DoAndReturn(f func(context.Context, ...*domain.APIKey) error) *MockIAPIKeyServiceCreateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Delete mocks base method.
+func (m *MockIAPIKeyService) Delete(ctx context.Context, id uuid.UUID, options ...services.Option[domain.APIKey]) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIAPIKeyServiceMockRecorder) Delete(ctx, id any, options ...any) *MockIAPIKeyServiceDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIAPIKeyService)(nil).Delete), varargs...)
+	return &MockIAPIKeyServiceDeleteCall{Call: call}
+}
+
+// MockIAPIKeyServiceDeleteCall wrap *gomock.Call
+type MockIAPIKeyServiceDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceDeleteCall) Return(arg0 error) *MockIAPIKeyServiceDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceDeleteCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.APIKey]) error) *MockIAPIKeyServiceDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceDeleteCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.APIKey]) error) *MockIAPIKeyServiceDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByHashOption mocks base method.
+func (m *MockIAPIKeyService) FilterByHashOption(hash string) services.Option[domain.APIKey] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByHashOption", hash)
+	ret0, _ := ret[0].(services.Option[domain.APIKey])
+	return ret0
+}
+
+// FilterByHashOption indicates an expected call of FilterByHashOption.
+func (mr *MockIAPIKeyServiceMockRecorder) FilterByHashOption(hash any) *MockIAPIKeyServiceFilterByHashOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByHashOption", reflect.TypeOf((*MockIAPIKeyService)(nil).FilterByHashOption), hash)
+	return &MockIAPIKeyServiceFilterByHashOptionCall{Call: call}
+}
+
+// MockIAPIKeyServiceFilterByHashOptionCall wrap *gomock.Call
+type MockIAPIKeyServiceFilterByHashOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceFilterByHashOptionCall) Return(arg0 services.Option[domain.APIKey]) *MockIAPIKeyServiceFilterByHashOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceFilterByHashOptionCall) Do(f func(string) services.Option[domain.APIKey]) *MockIAPIKeyServiceFilterByHashOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceFilterByHashOptionCall) DoAndReturn(f func(string) services.Option[domain.APIKey]) *MockIAPIKeyServiceFilterByHashOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByNameOption mocks base method.
+func (m *MockIAPIKeyService) FilterByNameOption(name string) services.Option[domain.APIKey] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByNameOption", name)
+	ret0, _ := ret[0].(services.Option[domain.APIKey])
+	return ret0
+}
+
+// FilterByNameOption indicates an expected call of FilterByNameOption.
+func (mr *MockIAPIKeyServiceMockRecorder) FilterByNameOption(name any) *MockIAPIKeyServiceFilterByNameOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByNameOption", reflect.TypeOf((*MockIAPIKeyService)(nil).FilterByNameOption), name)
+	return &MockIAPIKeyServiceFilterByNameOptionCall{Call: call}
+}
+
+// MockIAPIKeyServiceFilterByNameOptionCall wrap *gomock.Call
+type MockIAPIKeyServiceFilterByNameOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceFilterByNameOptionCall) Return(arg0 services.Option[domain.APIKey]) *MockIAPIKeyServiceFilterByNameOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceFilterByNameOptionCall) Do(f func(string) services.Option[domain.APIKey]) *MockIAPIKeyServiceFilterByNameOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceFilterByNameOptionCall) DoAndReturn(f func(string) services.Option[domain.APIKey]) *MockIAPIKeyServiceFilterByNameOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Get mocks base method.
+func (m *MockIAPIKeyService) Get(ctx context.Context, options ...services.Option[domain.APIKey]) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIAPIKeyServiceMockRecorder) Get(ctx any, options ...any) *MockIAPIKeyServiceGetCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIAPIKeyService)(nil).Get), varargs...)
+	return &MockIAPIKeyServiceGetCall{Call: call}
+}
+
+// MockIAPIKeyServiceGetCall wrap *gomock.Call
+type MockIAPIKeyServiceGetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceGetCall) Return(arg0 []*domain.APIKey, arg1 error) *MockIAPIKeyServiceGetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceGetCall) Do(f func(context.Context, ...services.Option[domain.APIKey]) ([]*domain.APIKey, error)) *MockIAPIKeyServiceGetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceGetCall) DoAndReturn(f func(context.Context, ...services.Option[domain.APIKey]) ([]*domain.APIKey, error)) *MockIAPIKeyServiceGetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetOwnedWithID mocks base method.
+func (m *MockIAPIKeyService) GetOwnedWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.APIKey]) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOwnedWithID", varargs...)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnedWithID indicates an expected call of GetOwnedWithID.
+func (mr *MockIAPIKeyServiceMockRecorder) GetOwnedWithID(ctx, id any, options ...any) *MockIAPIKeyServiceGetOwnedWithIDCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnedWithID", reflect.TypeOf((*MockIAPIKeyService)(nil).GetOwnedWithID), varargs...)
+	return &MockIAPIKeyServiceGetOwnedWithIDCall{Call: call}
+}
+
+// MockIAPIKeyServiceGetOwnedWithIDCall wrap *gomock.Call
+type MockIAPIKeyServiceGetOwnedWithIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceGetOwnedWithIDCall) Return(arg0 *domain.APIKey, arg1 error) *MockIAPIKeyServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceGetOwnedWithIDCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.APIKey]) (*domain.APIKey, error)) *MockIAPIKeyServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceGetOwnedWithIDCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.APIKey]) (*domain.APIKey, error)) *MockIAPIKeyServiceGetOwnedWithIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetPage mocks base method.
+func (m *MockIAPIKeyService) GetPage(ctx context.Context, pagination services.Pagination, options ...services.Option[domain.APIKey]) (*domain.Page[domain.APIKey], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetPage", varargs...)
+	ret0, _ := ret[0].(*domain.Page[domain.APIKey])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage.
+func (mr *MockIAPIKeyServiceMockRecorder) GetPage(ctx, pagination any, options ...any) *MockIAPIKeyServiceGetPageCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockIAPIKeyService)(nil).GetPage), varargs...)
+	return &MockIAPIKeyServiceGetPageCall{Call: call}
+}
+
+// MockIAPIKeyServiceGetPageCall wrap *gomock.Call
+type MockIAPIKeyServiceGetPageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceGetPageCall) Return(arg0 *domain.Page[domain.APIKey], arg1 error) *MockIAPIKeyServiceGetPageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceGetPageCall) Do(f func(context.Context, services.Pagination, ...services.Option[domain.APIKey]) (*domain.Page[domain.APIKey], error)) *MockIAPIKeyServiceGetPageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceGetPageCall) DoAndReturn(f func(context.Context, services.Pagination, ...services.Option[domain.APIKey]) (*domain.Page[domain.APIKey], error)) *MockIAPIKeyServiceGetPageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWithID mocks base method.
+func (m *MockIAPIKeyService) GetWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.APIKey]) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetWithID", varargs...)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithID indicates an expected call of GetWithID.
+func (mr *MockIAPIKeyServiceMockRecorder) GetWithID(ctx, id any, options ...any) *MockIAPIKeyServiceGetWithIDCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithID", reflect.TypeOf((*MockIAPIKeyService)(nil).GetWithID), varargs...)
+	return &MockIAPIKeyServiceGetWithIDCall{Call: call}
+}
+
+// MockIAPIKeyServiceGetWithIDCall wrap *gomock.Call
+type MockIAPIKeyServiceGetWithIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceGetWithIDCall) Return(arg0 *domain.APIKey, arg1 error) *MockIAPIKeyServiceGetWithIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceGetWithIDCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.APIKey]) (*domain.APIKey, error)) *MockIAPIKeyServiceGetWithIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceGetWithIDCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.APIKey]) (*domain.APIKey, error)) *MockIAPIKeyServiceGetWithIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWithIDs mocks base method.
+func (m *MockIAPIKeyService) GetWithIDs(ctx context.Context, ids []uuid.UUID, options ...services.Option[domain.APIKey]) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, ids}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetWithIDs", varargs...)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithIDs indicates an expected call of GetWithIDs.
+func (mr *MockIAPIKeyServiceMockRecorder) GetWithIDs(ctx, ids any, options ...any) *MockIAPIKeyServiceGetWithIDsCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, ids}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithIDs", reflect.TypeOf((*MockIAPIKeyService)(nil).GetWithIDs), varargs...)
+	return &MockIAPIKeyServiceGetWithIDsCall{Call: call}
+}
+
+// MockIAPIKeyServiceGetWithIDsCall wrap *gomock.Call
+type MockIAPIKeyServiceGetWithIDsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceGetWithIDsCall) Return(arg0 []*domain.APIKey, arg1 error) *MockIAPIKeyServiceGetWithIDsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceGetWithIDsCall) Do(f func(context.Context, []uuid.UUID, ...services.Option[domain.APIKey]) ([]*domain.APIKey, error)) *MockIAPIKeyServiceGetWithIDsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceGetWithIDsCall) DoAndReturn(f func(context.Context, []uuid.UUID, ...services.Option[domain.APIKey]) ([]*domain.APIKey, error)) *MockIAPIKeyServiceGetWithIDsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Update mocks base method.
+func (m *MockIAPIKeyService) Update(ctx context.Context, item *domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIAPIKeyServiceMockRecorder) Update(ctx, item any) *MockIAPIKeyServiceUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIAPIKeyService)(nil).Update), ctx, item)
+	return &MockIAPIKeyServiceUpdateCall{Call: call}
+}
+
+// MockIAPIKeyServiceUpdateCall wrap *gomock.Call
+type MockIAPIKeyServiceUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIAPIKeyServiceUpdateCall) Return(arg0 error) *MockIAPIKeyServiceUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIAPIKeyServiceUpdateCall) Do(f func(context.Context, *domain.APIKey) error) *MockIAPIKeyServiceUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIAPIKeyServiceUpdateCall) DoAndReturn(f func(context.Context, *domain.APIKey) error) *MockIAPIKeyServiceUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
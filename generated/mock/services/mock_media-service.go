@@ -12,9 +12,11 @@ package mock_services
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/TheSandyDave/Media-Tags/domain"
 	services "github.com/TheSandyDave/Media-Tags/services"
+	tagquery "github.com/TheSandyDave/Media-Tags/tagquery"
 	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -43,6 +45,44 @@ func (m *MockIMediaService) EXPECT() *MockIMediaServiceMockRecorder {
 	return m.recorder
 }
 
+// AddTags mocks base method.
+func (m *MockIMediaService) AddTags(ctx context.Context, media *domain.Media, tags []*domain.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTags", ctx, media, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTags indicates an expected call of AddTags.
+func (mr *MockIMediaServiceMockRecorder) AddTags(ctx, media, tags any) *MockIMediaServiceAddTagsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTags", reflect.TypeOf((*MockIMediaService)(nil).AddTags), ctx, media, tags)
+	return &MockIMediaServiceAddTagsCall{Call: call}
+}
+
+// MockIMediaServiceAddTagsCall wrap *gomock.Call
+type MockIMediaServiceAddTagsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceAddTagsCall) Return(arg0 error) *MockIMediaServiceAddTagsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceAddTagsCall) Do(f func(context.Context, *domain.Media, []*domain.Tag) error) *MockIMediaServiceAddTagsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceAddTagsCall) DoAndReturn(f func(context.Context, *domain.Media, []*domain.Tag) error) *MockIMediaServiceAddTagsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Create mocks base method.
 func (m *MockIMediaService) Create(ctx context.Context, item ...*domain.Media) error {
 	m.ctrl.T.Helper()
@@ -87,17 +127,22 @@ func (c *MockIMediaServiceCreateCall) DoAndReturn(f func(context.Context, ...*do
 }
 
 // Delete mocks base method.
-func (m *MockIMediaService) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockIMediaService) Delete(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Media]) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockIMediaServiceMockRecorder) Delete(ctx, id any) *MockIMediaServiceDeleteCall {
+func (mr *MockIMediaServiceMockRecorder) Delete(ctx, id any, options ...any) *MockIMediaServiceDeleteCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIMediaService)(nil).Delete), ctx, id)
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIMediaService)(nil).Delete), varargs...)
 	return &MockIMediaServiceDeleteCall{Call: call}
 }
 
@@ -113,13 +158,284 @@ func (c *MockIMediaServiceDeleteCall) Return(arg0 error) *MockIMediaServiceDelet
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockIMediaServiceDeleteCall) Do(f func(context.Context, uuid.UUID) error) *MockIMediaServiceDeleteCall {
+func (c *MockIMediaServiceDeleteCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Media]) error) *MockIMediaServiceDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceDeleteCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Media]) error) *MockIMediaServiceDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteWithFiles mocks base method.
+func (m *MockIMediaService) DeleteWithFiles(ctx context.Context, id uuid.UUID, remove services.RemoveFiles, options ...services.Option[domain.Media]) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id, remove}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteWithFiles", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWithFiles indicates an expected call of DeleteWithFiles.
+func (mr *MockIMediaServiceMockRecorder) DeleteWithFiles(ctx, id, remove any, options ...any) *MockIMediaServiceDeleteWithFilesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id, remove}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithFiles", reflect.TypeOf((*MockIMediaService)(nil).DeleteWithFiles), varargs...)
+	return &MockIMediaServiceDeleteWithFilesCall{Call: call}
+}
+
+// MockIMediaServiceDeleteWithFilesCall wrap *gomock.Call
+type MockIMediaServiceDeleteWithFilesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceDeleteWithFilesCall) Return(arg0 error) *MockIMediaServiceDeleteWithFilesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceDeleteWithFilesCall) Do(f func(context.Context, uuid.UUID, services.RemoveFiles, ...services.Option[domain.Media]) error) *MockIMediaServiceDeleteWithFilesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceDeleteWithFilesCall) DoAndReturn(f func(context.Context, uuid.UUID, services.RemoveFiles, ...services.Option[domain.Media]) error) *MockIMediaServiceDeleteWithFilesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ExcludeTagsOption mocks base method.
+func (m *MockIMediaService) ExcludeTagsOption(tags []string, scope services.TagScope) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExcludeTagsOption", tags, scope)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// ExcludeTagsOption indicates an expected call of ExcludeTagsOption.
+func (mr *MockIMediaServiceMockRecorder) ExcludeTagsOption(tags, scope any) *MockIMediaServiceExcludeTagsOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExcludeTagsOption", reflect.TypeOf((*MockIMediaService)(nil).ExcludeTagsOption), tags, scope)
+	return &MockIMediaServiceExcludeTagsOptionCall{Call: call}
+}
+
+// MockIMediaServiceExcludeTagsOptionCall wrap *gomock.Call
+type MockIMediaServiceExcludeTagsOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceExcludeTagsOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceExcludeTagsOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceExcludeTagsOptionCall) Do(f func([]string, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceExcludeTagsOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceExcludeTagsOptionCall) DoAndReturn(f func([]string, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceExcludeTagsOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByAllTagsOption mocks base method.
+func (m *MockIMediaService) FilterByAllTagsOption(tags []string, scope services.TagScope) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByAllTagsOption", tags, scope)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByAllTagsOption indicates an expected call of FilterByAllTagsOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByAllTagsOption(tags, scope any) *MockIMediaServiceFilterByAllTagsOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByAllTagsOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByAllTagsOption), tags, scope)
+	return &MockIMediaServiceFilterByAllTagsOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByAllTagsOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByAllTagsOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByAllTagsOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByAllTagsOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByAllTagsOptionCall) Do(f func([]string, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceFilterByAllTagsOptionCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockIMediaServiceDeleteCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockIMediaServiceDeleteCall {
+func (c *MockIMediaServiceFilterByAllTagsOptionCall) DoAndReturn(f func([]string, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceFilterByAllTagsOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByAnyTagOption mocks base method.
+func (m *MockIMediaService) FilterByAnyTagOption(tags []string, scope services.TagScope) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByAnyTagOption", tags, scope)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByAnyTagOption indicates an expected call of FilterByAnyTagOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByAnyTagOption(tags, scope any) *MockIMediaServiceFilterByAnyTagOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByAnyTagOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByAnyTagOption), tags, scope)
+	return &MockIMediaServiceFilterByAnyTagOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByAnyTagOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByAnyTagOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByAnyTagOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByAnyTagOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByAnyTagOptionCall) Do(f func([]string, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceFilterByAnyTagOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByAnyTagOptionCall) DoAndReturn(f func([]string, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceFilterByAnyTagOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByCameraOption mocks base method.
+func (m *MockIMediaService) FilterByCameraOption(camera string) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByCameraOption", camera)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByCameraOption indicates an expected call of FilterByCameraOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByCameraOption(camera any) *MockIMediaServiceFilterByCameraOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByCameraOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByCameraOption), camera)
+	return &MockIMediaServiceFilterByCameraOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByCameraOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByCameraOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByCameraOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByCameraOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByCameraOptionCall) Do(f func(string) services.Option[domain.Media]) *MockIMediaServiceFilterByCameraOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByCameraOptionCall) DoAndReturn(f func(string) services.Option[domain.Media]) *MockIMediaServiceFilterByCameraOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByContentHashOption mocks base method.
+func (m *MockIMediaService) FilterByContentHashOption(hash string) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByContentHashOption", hash)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByContentHashOption indicates an expected call of FilterByContentHashOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByContentHashOption(hash any) *MockIMediaServiceFilterByContentHashOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByContentHashOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByContentHashOption), hash)
+	return &MockIMediaServiceFilterByContentHashOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByContentHashOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByContentHashOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByContentHashOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByContentHashOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByContentHashOptionCall) Do(f func(string) services.Option[domain.Media]) *MockIMediaServiceFilterByContentHashOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByContentHashOptionCall) DoAndReturn(f func(string) services.Option[domain.Media]) *MockIMediaServiceFilterByContentHashOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByKindOption mocks base method.
+func (m *MockIMediaService) FilterByKindOption(kind domain.MediaKind) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByKindOption", kind)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByKindOption indicates an expected call of FilterByKindOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByKindOption(kind any) *MockIMediaServiceFilterByKindOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByKindOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByKindOption), kind)
+	return &MockIMediaServiceFilterByKindOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByKindOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByKindOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByKindOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByKindOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByKindOptionCall) Do(f func(domain.MediaKind) services.Option[domain.Media]) *MockIMediaServiceFilterByKindOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByKindOptionCall) DoAndReturn(f func(domain.MediaKind) services.Option[domain.Media]) *MockIMediaServiceFilterByKindOptionCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -162,6 +478,120 @@ func (c *MockIMediaServiceFilterByTagOptionCall) DoAndReturn(f func(string) serv
 	return c
 }
 
+// FilterByTagQueryOption mocks base method.
+func (m *MockIMediaService) FilterByTagQueryOption(query tagquery.Node, scope services.TagScope) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByTagQueryOption", query, scope)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByTagQueryOption indicates an expected call of FilterByTagQueryOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByTagQueryOption(query, scope any) *MockIMediaServiceFilterByTagQueryOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByTagQueryOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByTagQueryOption), query, scope)
+	return &MockIMediaServiceFilterByTagQueryOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByTagQueryOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByTagQueryOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByTagQueryOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByTagQueryOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByTagQueryOptionCall) Do(f func(tagquery.Node, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceFilterByTagQueryOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByTagQueryOptionCall) DoAndReturn(f func(tagquery.Node, services.TagScope) services.Option[domain.Media]) *MockIMediaServiceFilterByTagQueryOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByTakenAfterOption mocks base method.
+func (m *MockIMediaService) FilterByTakenAfterOption(after time.Time) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByTakenAfterOption", after)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByTakenAfterOption indicates an expected call of FilterByTakenAfterOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByTakenAfterOption(after any) *MockIMediaServiceFilterByTakenAfterOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByTakenAfterOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByTakenAfterOption), after)
+	return &MockIMediaServiceFilterByTakenAfterOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByTakenAfterOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByTakenAfterOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByTakenAfterOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByTakenAfterOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByTakenAfterOptionCall) Do(f func(time.Time) services.Option[domain.Media]) *MockIMediaServiceFilterByTakenAfterOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByTakenAfterOptionCall) DoAndReturn(f func(time.Time) services.Option[domain.Media]) *MockIMediaServiceFilterByTakenAfterOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByTakenBeforeOption mocks base method.
+func (m *MockIMediaService) FilterByTakenBeforeOption(before time.Time) services.Option[domain.Media] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByTakenBeforeOption", before)
+	ret0, _ := ret[0].(services.Option[domain.Media])
+	return ret0
+}
+
+// FilterByTakenBeforeOption indicates an expected call of FilterByTakenBeforeOption.
+func (mr *MockIMediaServiceMockRecorder) FilterByTakenBeforeOption(before any) *MockIMediaServiceFilterByTakenBeforeOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByTakenBeforeOption", reflect.TypeOf((*MockIMediaService)(nil).FilterByTakenBeforeOption), before)
+	return &MockIMediaServiceFilterByTakenBeforeOptionCall{Call: call}
+}
+
+// MockIMediaServiceFilterByTakenBeforeOptionCall wrap *gomock.Call
+type MockIMediaServiceFilterByTakenBeforeOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceFilterByTakenBeforeOptionCall) Return(arg0 services.Option[domain.Media]) *MockIMediaServiceFilterByTakenBeforeOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceFilterByTakenBeforeOptionCall) Do(f func(time.Time) services.Option[domain.Media]) *MockIMediaServiceFilterByTakenBeforeOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceFilterByTakenBeforeOptionCall) DoAndReturn(f func(time.Time) services.Option[domain.Media]) *MockIMediaServiceFilterByTakenBeforeOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Get mocks base method.
 func (m *MockIMediaService) Get(ctx context.Context, options ...services.Option[domain.Media]) ([]*domain.Media, error) {
 	m.ctrl.T.Helper()
@@ -206,6 +636,138 @@ func (c *MockIMediaServiceGetCall) DoAndReturn(f func(context.Context, ...servic
 	return c
 }
 
+// GetOwnedWithID mocks base method.
+func (m *MockIMediaService) GetOwnedWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Media]) (*domain.Media, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOwnedWithID", varargs...)
+	ret0, _ := ret[0].(*domain.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnedWithID indicates an expected call of GetOwnedWithID.
+func (mr *MockIMediaServiceMockRecorder) GetOwnedWithID(ctx, id any, options ...any) *MockIMediaServiceGetOwnedWithIDCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnedWithID", reflect.TypeOf((*MockIMediaService)(nil).GetOwnedWithID), varargs...)
+	return &MockIMediaServiceGetOwnedWithIDCall{Call: call}
+}
+
+// MockIMediaServiceGetOwnedWithIDCall wrap *gomock.Call
+type MockIMediaServiceGetOwnedWithIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceGetOwnedWithIDCall) Return(arg0 *domain.Media, arg1 error) *MockIMediaServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceGetOwnedWithIDCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Media]) (*domain.Media, error)) *MockIMediaServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceGetOwnedWithIDCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Media]) (*domain.Media, error)) *MockIMediaServiceGetOwnedWithIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetPage mocks base method.
+func (m *MockIMediaService) GetPage(ctx context.Context, pagination services.Pagination, options ...services.Option[domain.Media]) (*domain.Page[domain.Media], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetPage", varargs...)
+	ret0, _ := ret[0].(*domain.Page[domain.Media])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage.
+func (mr *MockIMediaServiceMockRecorder) GetPage(ctx, pagination any, options ...any) *MockIMediaServiceGetPageCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockIMediaService)(nil).GetPage), varargs...)
+	return &MockIMediaServiceGetPageCall{Call: call}
+}
+
+// MockIMediaServiceGetPageCall wrap *gomock.Call
+type MockIMediaServiceGetPageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceGetPageCall) Return(arg0 *domain.Page[domain.Media], arg1 error) *MockIMediaServiceGetPageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceGetPageCall) Do(f func(context.Context, services.Pagination, ...services.Option[domain.Media]) (*domain.Page[domain.Media], error)) *MockIMediaServiceGetPageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceGetPageCall) DoAndReturn(f func(context.Context, services.Pagination, ...services.Option[domain.Media]) (*domain.Page[domain.Media], error)) *MockIMediaServiceGetPageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetSimilar mocks base method.
+func (m *MockIMediaService) GetSimilar(ctx context.Context, hash uint64, maxDistance, limit int, options ...services.Option[domain.Media]) ([]*domain.SimilarMedia, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, hash, maxDistance, limit}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetSimilar", varargs...)
+	ret0, _ := ret[0].([]*domain.SimilarMedia)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSimilar indicates an expected call of GetSimilar.
+func (mr *MockIMediaServiceMockRecorder) GetSimilar(ctx, hash, maxDistance, limit any, options ...any) *MockIMediaServiceGetSimilarCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, hash, maxDistance, limit}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilar", reflect.TypeOf((*MockIMediaService)(nil).GetSimilar), varargs...)
+	return &MockIMediaServiceGetSimilarCall{Call: call}
+}
+
+// MockIMediaServiceGetSimilarCall wrap *gomock.Call
+type MockIMediaServiceGetSimilarCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceGetSimilarCall) Return(arg0 []*domain.SimilarMedia, arg1 error) *MockIMediaServiceGetSimilarCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceGetSimilarCall) Do(f func(context.Context, uint64, int, int, ...services.Option[domain.Media]) ([]*domain.SimilarMedia, error)) *MockIMediaServiceGetSimilarCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceGetSimilarCall) DoAndReturn(f func(context.Context, uint64, int, int, ...services.Option[domain.Media]) ([]*domain.SimilarMedia, error)) *MockIMediaServiceGetSimilarCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetWithID mocks base method.
 func (m *MockIMediaService) GetWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Media]) (*domain.Media, error) {
 	m.ctrl.T.Helper()
@@ -293,3 +855,117 @@ func (c *MockIMediaServiceGetWithIDsCall) DoAndReturn(f func(context.Context, []
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// RemoveTag mocks base method.
+func (m *MockIMediaService) RemoveTag(ctx context.Context, media *domain.Media, tagID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTag", ctx, media, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTag indicates an expected call of RemoveTag.
+func (mr *MockIMediaServiceMockRecorder) RemoveTag(ctx, media, tagID any) *MockIMediaServiceRemoveTagCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockIMediaService)(nil).RemoveTag), ctx, media, tagID)
+	return &MockIMediaServiceRemoveTagCall{Call: call}
+}
+
+// MockIMediaServiceRemoveTagCall wrap *gomock.Call
+type MockIMediaServiceRemoveTagCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceRemoveTagCall) Return(arg0 error) *MockIMediaServiceRemoveTagCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceRemoveTagCall) Do(f func(context.Context, *domain.Media, uuid.UUID) error) *MockIMediaServiceRemoveTagCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceRemoveTagCall) DoAndReturn(f func(context.Context, *domain.Media, uuid.UUID) error) *MockIMediaServiceRemoveTagCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReplaceTags mocks base method.
+func (m *MockIMediaService) ReplaceTags(ctx context.Context, media *domain.Media, tags []*domain.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceTags", ctx, media, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceTags indicates an expected call of ReplaceTags.
+func (mr *MockIMediaServiceMockRecorder) ReplaceTags(ctx, media, tags any) *MockIMediaServiceReplaceTagsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTags", reflect.TypeOf((*MockIMediaService)(nil).ReplaceTags), ctx, media, tags)
+	return &MockIMediaServiceReplaceTagsCall{Call: call}
+}
+
+// MockIMediaServiceReplaceTagsCall wrap *gomock.Call
+type MockIMediaServiceReplaceTagsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceReplaceTagsCall) Return(arg0 error) *MockIMediaServiceReplaceTagsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceReplaceTagsCall) Do(f func(context.Context, *domain.Media, []*domain.Tag) error) *MockIMediaServiceReplaceTagsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceReplaceTagsCall) DoAndReturn(f func(context.Context, *domain.Media, []*domain.Tag) error) *MockIMediaServiceReplaceTagsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Update mocks base method.
+func (m *MockIMediaService) Update(ctx context.Context, item *domain.Media) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIMediaServiceMockRecorder) Update(ctx, item any) *MockIMediaServiceUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIMediaService)(nil).Update), ctx, item)
+	return &MockIMediaServiceUpdateCall{Call: call}
+}
+
+// MockIMediaServiceUpdateCall wrap *gomock.Call
+type MockIMediaServiceUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIMediaServiceUpdateCall) Return(arg0 error) *MockIMediaServiceUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIMediaServiceUpdateCall) Do(f func(context.Context, *domain.Media) error) *MockIMediaServiceUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIMediaServiceUpdateCall) DoAndReturn(f func(context.Context, *domain.Media) error) *MockIMediaServiceUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
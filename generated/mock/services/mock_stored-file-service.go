@@ -0,0 +1,128 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stored-file-service.go
+//
+// Generated by this command:
+//
+//	mockgen -source stored-file-service.go -typed -destination ../generated/mock/services/mock_stored-file-service.go IStoredFileService
+//
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	services "github.com/TheSandyDave/Media-Tags/services"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIStoredFileService is a mock of IStoredFileService interface.
+type MockIStoredFileService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStoredFileServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockIStoredFileServiceMockRecorder is the mock recorder for MockIStoredFileService.
+type MockIStoredFileServiceMockRecorder struct {
+	mock *MockIStoredFileService
+}
+
+// NewMockIStoredFileService creates a new mock instance.
+func NewMockIStoredFileService(ctrl *gomock.Controller) *MockIStoredFileService {
+	mock := &MockIStoredFileService{ctrl: ctrl}
+	mock.recorder = &MockIStoredFileServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStoredFileService) EXPECT() *MockIStoredFileServiceMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockIStoredFileService) Acquire(ctx context.Context, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Acquire", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockIStoredFileServiceMockRecorder) Acquire(ctx any, keys ...any) *MockIStoredFileServiceAcquireCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, keys...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockIStoredFileService)(nil).Acquire), varargs...)
+	return &MockIStoredFileServiceAcquireCall{Call: call}
+}
+
+// MockIStoredFileServiceAcquireCall wrap *gomock.Call
+type MockIStoredFileServiceAcquireCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIStoredFileServiceAcquireCall) Return(arg0 error) *MockIStoredFileServiceAcquireCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIStoredFileServiceAcquireCall) Do(f func(context.Context, ...string) error) *MockIStoredFileServiceAcquireCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIStoredFileServiceAcquireCall) DoAndReturn(f func(context.Context, ...string) error) *MockIStoredFileServiceAcquireCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Release mocks base method.
+func (m *MockIStoredFileService) Release(ctx context.Context, remove services.RemoveFiles, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, remove}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Release", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIStoredFileServiceMockRecorder) Release(ctx, remove any, keys ...any) *MockIStoredFileServiceReleaseCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, remove}, keys...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIStoredFileService)(nil).Release), varargs...)
+	return &MockIStoredFileServiceReleaseCall{Call: call}
+}
+
+// MockIStoredFileServiceReleaseCall wrap *gomock.Call
+type MockIStoredFileServiceReleaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIStoredFileServiceReleaseCall) Return(arg0 error) *MockIStoredFileServiceReleaseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIStoredFileServiceReleaseCall) Do(f func(context.Context, services.RemoveFiles, ...string) error) *MockIStoredFileServiceReleaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIStoredFileServiceReleaseCall) DoAndReturn(f func(context.Context, services.RemoveFiles, ...string) error) *MockIStoredFileServiceReleaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -87,17 +87,22 @@ func (c *MockITagServiceCreateCall) DoAndReturn(f func(context.Context, ...*doma
 }
 
 // Delete mocks base method.
-func (m *MockITagService) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockITagService) Delete(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Tag]) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockITagServiceMockRecorder) Delete(ctx, id any) *MockITagServiceDeleteCall {
+func (mr *MockITagServiceMockRecorder) Delete(ctx, id any, options ...any) *MockITagServiceDeleteCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockITagService)(nil).Delete), ctx, id)
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockITagService)(nil).Delete), varargs...)
 	return &MockITagServiceDeleteCall{Call: call}
 }
 
@@ -113,13 +118,51 @@ func (c *MockITagServiceDeleteCall) Return(arg0 error) *MockITagServiceDeleteCal
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockITagServiceDeleteCall) Do(f func(context.Context, uuid.UUID) error) *MockITagServiceDeleteCall {
+func (c *MockITagServiceDeleteCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Tag]) error) *MockITagServiceDeleteCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockITagServiceDeleteCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockITagServiceDeleteCall {
+func (c *MockITagServiceDeleteCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Tag]) error) *MockITagServiceDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterByNameOption mocks base method.
+func (m *MockITagService) FilterByNameOption(name string) services.Option[domain.Tag] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterByNameOption", name)
+	ret0, _ := ret[0].(services.Option[domain.Tag])
+	return ret0
+}
+
+// FilterByNameOption indicates an expected call of FilterByNameOption.
+func (mr *MockITagServiceMockRecorder) FilterByNameOption(name any) *MockITagServiceFilterByNameOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterByNameOption", reflect.TypeOf((*MockITagService)(nil).FilterByNameOption), name)
+	return &MockITagServiceFilterByNameOptionCall{Call: call}
+}
+
+// MockITagServiceFilterByNameOptionCall wrap *gomock.Call
+type MockITagServiceFilterByNameOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceFilterByNameOptionCall) Return(arg0 services.Option[domain.Tag]) *MockITagServiceFilterByNameOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceFilterByNameOptionCall) Do(f func(string) services.Option[domain.Tag]) *MockITagServiceFilterByNameOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceFilterByNameOptionCall) DoAndReturn(f func(string) services.Option[domain.Tag]) *MockITagServiceFilterByNameOptionCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -168,6 +211,133 @@ func (c *MockITagServiceGetCall) DoAndReturn(f func(context.Context, ...services
 	return c
 }
 
+// GetOwnedWithID mocks base method.
+func (m *MockITagService) GetOwnedWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Tag]) (*domain.Tag, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOwnedWithID", varargs...)
+	ret0, _ := ret[0].(*domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnedWithID indicates an expected call of GetOwnedWithID.
+func (mr *MockITagServiceMockRecorder) GetOwnedWithID(ctx, id any, options ...any) *MockITagServiceGetOwnedWithIDCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnedWithID", reflect.TypeOf((*MockITagService)(nil).GetOwnedWithID), varargs...)
+	return &MockITagServiceGetOwnedWithIDCall{Call: call}
+}
+
+// MockITagServiceGetOwnedWithIDCall wrap *gomock.Call
+type MockITagServiceGetOwnedWithIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceGetOwnedWithIDCall) Return(arg0 *domain.Tag, arg1 error) *MockITagServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceGetOwnedWithIDCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Tag]) (*domain.Tag, error)) *MockITagServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceGetOwnedWithIDCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Tag]) (*domain.Tag, error)) *MockITagServiceGetOwnedWithIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetPage mocks base method.
+func (m *MockITagService) GetPage(ctx context.Context, pagination services.Pagination, options ...services.Option[domain.Tag]) (*domain.Page[domain.Tag], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetPage", varargs...)
+	ret0, _ := ret[0].(*domain.Page[domain.Tag])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage.
+func (mr *MockITagServiceMockRecorder) GetPage(ctx, pagination any, options ...any) *MockITagServiceGetPageCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockITagService)(nil).GetPage), varargs...)
+	return &MockITagServiceGetPageCall{Call: call}
+}
+
+// MockITagServiceGetPageCall wrap *gomock.Call
+type MockITagServiceGetPageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceGetPageCall) Return(arg0 *domain.Page[domain.Tag], arg1 error) *MockITagServiceGetPageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceGetPageCall) Do(f func(context.Context, services.Pagination, ...services.Option[domain.Tag]) (*domain.Page[domain.Tag], error)) *MockITagServiceGetPageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceGetPageCall) DoAndReturn(f func(context.Context, services.Pagination, ...services.Option[domain.Tag]) (*domain.Page[domain.Tag], error)) *MockITagServiceGetPageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetSubtree mocks base method.
+func (m *MockITagService) GetSubtree(ctx context.Context, id uuid.UUID) ([]*domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubtree", ctx, id)
+	ret0, _ := ret[0].([]*domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubtree indicates an expected call of GetSubtree.
+func (mr *MockITagServiceMockRecorder) GetSubtree(ctx, id any) *MockITagServiceGetSubtreeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubtree", reflect.TypeOf((*MockITagService)(nil).GetSubtree), ctx, id)
+	return &MockITagServiceGetSubtreeCall{Call: call}
+}
+
+// MockITagServiceGetSubtreeCall wrap *gomock.Call
+type MockITagServiceGetSubtreeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceGetSubtreeCall) Return(arg0 []*domain.Tag, arg1 error) *MockITagServiceGetSubtreeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceGetSubtreeCall) Do(f func(context.Context, uuid.UUID) ([]*domain.Tag, error)) *MockITagServiceGetSubtreeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceGetSubtreeCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]*domain.Tag, error)) *MockITagServiceGetSubtreeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetWithID mocks base method.
 func (m *MockITagService) GetWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Tag]) (*domain.Tag, error) {
 	m.ctrl.T.Helper()
@@ -255,3 +425,117 @@ func (c *MockITagServiceGetWithIDsCall) DoAndReturn(f func(context.Context, []uu
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// Merge mocks base method.
+func (m *MockITagService) Merge(ctx context.Context, source, target *domain.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, source, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockITagServiceMockRecorder) Merge(ctx, source, target any) *MockITagServiceMergeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockITagService)(nil).Merge), ctx, source, target)
+	return &MockITagServiceMergeCall{Call: call}
+}
+
+// MockITagServiceMergeCall wrap *gomock.Call
+type MockITagServiceMergeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceMergeCall) Return(arg0 error) *MockITagServiceMergeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceMergeCall) Do(f func(context.Context, *domain.Tag, *domain.Tag) error) *MockITagServiceMergeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceMergeCall) DoAndReturn(f func(context.Context, *domain.Tag, *domain.Tag) error) *MockITagServiceMergeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReplaceAliases mocks base method.
+func (m *MockITagService) ReplaceAliases(ctx context.Context, tag *domain.Tag, aliases []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceAliases", ctx, tag, aliases)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceAliases indicates an expected call of ReplaceAliases.
+func (mr *MockITagServiceMockRecorder) ReplaceAliases(ctx, tag, aliases any) *MockITagServiceReplaceAliasesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceAliases", reflect.TypeOf((*MockITagService)(nil).ReplaceAliases), ctx, tag, aliases)
+	return &MockITagServiceReplaceAliasesCall{Call: call}
+}
+
+// MockITagServiceReplaceAliasesCall wrap *gomock.Call
+type MockITagServiceReplaceAliasesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceReplaceAliasesCall) Return(arg0 error) *MockITagServiceReplaceAliasesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceReplaceAliasesCall) Do(f func(context.Context, *domain.Tag, []string) error) *MockITagServiceReplaceAliasesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceReplaceAliasesCall) DoAndReturn(f func(context.Context, *domain.Tag, []string) error) *MockITagServiceReplaceAliasesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Update mocks base method.
+func (m *MockITagService) Update(ctx context.Context, item *domain.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockITagServiceMockRecorder) Update(ctx, item any) *MockITagServiceUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockITagService)(nil).Update), ctx, item)
+	return &MockITagServiceUpdateCall{Call: call}
+}
+
+// MockITagServiceUpdateCall wrap *gomock.Call
+type MockITagServiceUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockITagServiceUpdateCall) Return(arg0 error) *MockITagServiceUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockITagServiceUpdateCall) Do(f func(context.Context, *domain.Tag) error) *MockITagServiceUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockITagServiceUpdateCall) DoAndReturn(f func(context.Context, *domain.Tag) error) *MockITagServiceUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
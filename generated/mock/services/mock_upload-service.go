@@ -0,0 +1,427 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: upload-service.go
+//
+// Generated by this command:
+//
+//	mockgen -source upload-service.go -typed -destination ../generated/mock/services/mock_upload-service.go IUploadService
+//
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/TheSandyDave/Media-Tags/domain"
+	services "github.com/TheSandyDave/Media-Tags/services"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIUploadService is a mock of IUploadService interface.
+type MockIUploadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUploadServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockIUploadServiceMockRecorder is the mock recorder for MockIUploadService.
+type MockIUploadServiceMockRecorder struct {
+	mock *MockIUploadService
+}
+
+// NewMockIUploadService creates a new mock instance.
+func NewMockIUploadService(ctrl *gomock.Controller) *MockIUploadService {
+	mock := &MockIUploadService{ctrl: ctrl}
+	mock.recorder = &MockIUploadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUploadService) EXPECT() *MockIUploadServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIUploadService) Create(ctx context.Context, item ...*domain.Upload) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range item {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIUploadServiceMockRecorder) Create(ctx any, item ...any) *MockIUploadServiceCreateCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, item...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIUploadService)(nil).Create), varargs...)
+	return &MockIUploadServiceCreateCall{Call: call}
+}
+
+// MockIUploadServiceCreateCall wrap *gomock.Call
+type MockIUploadServiceCreateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceCreateCall) Return(arg0 error) *MockIUploadServiceCreateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceCreateCall) Do(f func(context.Context, ...*domain.Upload) error) *MockIUploadServiceCreateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceCreateCall) DoAndReturn(f func(context.Context, ...*domain.Upload) error) *MockIUploadServiceCreateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Delete mocks base method.
+func (m *MockIUploadService) Delete(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Upload]) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIUploadServiceMockRecorder) Delete(ctx, id any, options ...any) *MockIUploadServiceDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIUploadService)(nil).Delete), varargs...)
+	return &MockIUploadServiceDeleteCall{Call: call}
+}
+
+// MockIUploadServiceDeleteCall wrap *gomock.Call
+type MockIUploadServiceDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceDeleteCall) Return(arg0 error) *MockIUploadServiceDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceDeleteCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Upload]) error) *MockIUploadServiceDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceDeleteCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Upload]) error) *MockIUploadServiceDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// FilterExpiredOption mocks base method.
+func (m *MockIUploadService) FilterExpiredOption(now time.Time) services.Option[domain.Upload] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterExpiredOption", now)
+	ret0, _ := ret[0].(services.Option[domain.Upload])
+	return ret0
+}
+
+// FilterExpiredOption indicates an expected call of FilterExpiredOption.
+func (mr *MockIUploadServiceMockRecorder) FilterExpiredOption(now any) *MockIUploadServiceFilterExpiredOptionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterExpiredOption", reflect.TypeOf((*MockIUploadService)(nil).FilterExpiredOption), now)
+	return &MockIUploadServiceFilterExpiredOptionCall{Call: call}
+}
+
+// MockIUploadServiceFilterExpiredOptionCall wrap *gomock.Call
+type MockIUploadServiceFilterExpiredOptionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceFilterExpiredOptionCall) Return(arg0 services.Option[domain.Upload]) *MockIUploadServiceFilterExpiredOptionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceFilterExpiredOptionCall) Do(f func(time.Time) services.Option[domain.Upload]) *MockIUploadServiceFilterExpiredOptionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceFilterExpiredOptionCall) DoAndReturn(f func(time.Time) services.Option[domain.Upload]) *MockIUploadServiceFilterExpiredOptionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Get mocks base method.
+func (m *MockIUploadService) Get(ctx context.Context, options ...services.Option[domain.Upload]) ([]*domain.Upload, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
+	ret0, _ := ret[0].([]*domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIUploadServiceMockRecorder) Get(ctx any, options ...any) *MockIUploadServiceGetCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIUploadService)(nil).Get), varargs...)
+	return &MockIUploadServiceGetCall{Call: call}
+}
+
+// MockIUploadServiceGetCall wrap *gomock.Call
+type MockIUploadServiceGetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceGetCall) Return(arg0 []*domain.Upload, arg1 error) *MockIUploadServiceGetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceGetCall) Do(f func(context.Context, ...services.Option[domain.Upload]) ([]*domain.Upload, error)) *MockIUploadServiceGetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceGetCall) DoAndReturn(f func(context.Context, ...services.Option[domain.Upload]) ([]*domain.Upload, error)) *MockIUploadServiceGetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetOwnedWithID mocks base method.
+func (m *MockIUploadService) GetOwnedWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Upload]) (*domain.Upload, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOwnedWithID", varargs...)
+	ret0, _ := ret[0].(*domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnedWithID indicates an expected call of GetOwnedWithID.
+func (mr *MockIUploadServiceMockRecorder) GetOwnedWithID(ctx, id any, options ...any) *MockIUploadServiceGetOwnedWithIDCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnedWithID", reflect.TypeOf((*MockIUploadService)(nil).GetOwnedWithID), varargs...)
+	return &MockIUploadServiceGetOwnedWithIDCall{Call: call}
+}
+
+// MockIUploadServiceGetOwnedWithIDCall wrap *gomock.Call
+type MockIUploadServiceGetOwnedWithIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceGetOwnedWithIDCall) Return(arg0 *domain.Upload, arg1 error) *MockIUploadServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceGetOwnedWithIDCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Upload]) (*domain.Upload, error)) *MockIUploadServiceGetOwnedWithIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceGetOwnedWithIDCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Upload]) (*domain.Upload, error)) *MockIUploadServiceGetOwnedWithIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetPage mocks base method.
+func (m *MockIUploadService) GetPage(ctx context.Context, pagination services.Pagination, options ...services.Option[domain.Upload]) (*domain.Page[domain.Upload], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, pagination}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetPage", varargs...)
+	ret0, _ := ret[0].(*domain.Page[domain.Upload])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage.
+func (mr *MockIUploadServiceMockRecorder) GetPage(ctx, pagination any, options ...any) *MockIUploadServiceGetPageCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, pagination}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockIUploadService)(nil).GetPage), varargs...)
+	return &MockIUploadServiceGetPageCall{Call: call}
+}
+
+// MockIUploadServiceGetPageCall wrap *gomock.Call
+type MockIUploadServiceGetPageCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceGetPageCall) Return(arg0 *domain.Page[domain.Upload], arg1 error) *MockIUploadServiceGetPageCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceGetPageCall) Do(f func(context.Context, services.Pagination, ...services.Option[domain.Upload]) (*domain.Page[domain.Upload], error)) *MockIUploadServiceGetPageCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceGetPageCall) DoAndReturn(f func(context.Context, services.Pagination, ...services.Option[domain.Upload]) (*domain.Page[domain.Upload], error)) *MockIUploadServiceGetPageCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWithID mocks base method.
+func (m *MockIUploadService) GetWithID(ctx context.Context, id uuid.UUID, options ...services.Option[domain.Upload]) (*domain.Upload, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, id}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetWithID", varargs...)
+	ret0, _ := ret[0].(*domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithID indicates an expected call of GetWithID.
+func (mr *MockIUploadServiceMockRecorder) GetWithID(ctx, id any, options ...any) *MockIUploadServiceGetWithIDCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, id}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithID", reflect.TypeOf((*MockIUploadService)(nil).GetWithID), varargs...)
+	return &MockIUploadServiceGetWithIDCall{Call: call}
+}
+
+// MockIUploadServiceGetWithIDCall wrap *gomock.Call
+type MockIUploadServiceGetWithIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceGetWithIDCall) Return(arg0 *domain.Upload, arg1 error) *MockIUploadServiceGetWithIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceGetWithIDCall) Do(f func(context.Context, uuid.UUID, ...services.Option[domain.Upload]) (*domain.Upload, error)) *MockIUploadServiceGetWithIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceGetWithIDCall) DoAndReturn(f func(context.Context, uuid.UUID, ...services.Option[domain.Upload]) (*domain.Upload, error)) *MockIUploadServiceGetWithIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWithIDs mocks base method.
+func (m *MockIUploadService) GetWithIDs(ctx context.Context, ids []uuid.UUID, options ...services.Option[domain.Upload]) ([]*domain.Upload, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, ids}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetWithIDs", varargs...)
+	ret0, _ := ret[0].([]*domain.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithIDs indicates an expected call of GetWithIDs.
+func (mr *MockIUploadServiceMockRecorder) GetWithIDs(ctx, ids any, options ...any) *MockIUploadServiceGetWithIDsCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, ids}, options...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithIDs", reflect.TypeOf((*MockIUploadService)(nil).GetWithIDs), varargs...)
+	return &MockIUploadServiceGetWithIDsCall{Call: call}
+}
+
+// MockIUploadServiceGetWithIDsCall wrap *gomock.Call
+type MockIUploadServiceGetWithIDsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceGetWithIDsCall) Return(arg0 []*domain.Upload, arg1 error) *MockIUploadServiceGetWithIDsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceGetWithIDsCall) Do(f func(context.Context, []uuid.UUID, ...services.Option[domain.Upload]) ([]*domain.Upload, error)) *MockIUploadServiceGetWithIDsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceGetWithIDsCall) DoAndReturn(f func(context.Context, []uuid.UUID, ...services.Option[domain.Upload]) ([]*domain.Upload, error)) *MockIUploadServiceGetWithIDsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Update mocks base method.
+func (m *MockIUploadService) Update(ctx context.Context, item *domain.Upload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIUploadServiceMockRecorder) Update(ctx, item any) *MockIUploadServiceUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIUploadService)(nil).Update), ctx, item)
+	return &MockIUploadServiceUpdateCall{Call: call}
+}
+
+// MockIUploadServiceUpdateCall wrap *gomock.Call
+type MockIUploadServiceUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIUploadServiceUpdateCall) Return(arg0 error) *MockIUploadServiceUpdateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIUploadServiceUpdateCall) Do(f func(context.Context, *domain.Upload) error) *MockIUploadServiceUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIUploadServiceUpdateCall) DoAndReturn(f func(context.Context, *domain.Upload) error) *MockIUploadServiceUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,274 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: file-store.go
+//
+// Generated by this command:
+//
+//	mockgen -source file-store.go -typed -destination ../generated/mock/storage/mock_file-store.go FileStore
+//
+
+// Package mock_storage is a generated GoMock package.
+package mock_storage
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	storage "github.com/TheSandyDave/Media-Tags/storage"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFileStore is a mock of FileStore interface.
+type MockFileStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockFileStoreMockRecorder is the mock recorder for MockFileStore.
+type MockFileStoreMockRecorder struct {
+	mock *MockFileStore
+}
+
+// NewMockFileStore creates a new mock instance.
+func NewMockFileStore(ctrl *gomock.Controller) *MockFileStore {
+	mock := &MockFileStore{ctrl: ctrl}
+	mock.recorder = &MockFileStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileStore) EXPECT() *MockFileStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockFileStore) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFileStoreMockRecorder) Delete(ctx, key any) *MockFileStoreDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFileStore)(nil).Delete), ctx, key)
+	return &MockFileStoreDeleteCall{Call: call}
+}
+
+// MockFileStoreDeleteCall wrap *gomock.Call
+type MockFileStoreDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFileStoreDeleteCall) Return(arg0 error) *MockFileStoreDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFileStoreDeleteCall) Do(f func(context.Context, string) error) *MockFileStoreDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFileStoreDeleteCall) DoAndReturn(f func(context.Context, string) error) *MockFileStoreDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Get mocks base method.
+func (m *MockFileStore) Get(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(io.ReadSeekCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockFileStoreMockRecorder) Get(ctx, key any) *MockFileStoreGetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFileStore)(nil).Get), ctx, key)
+	return &MockFileStoreGetCall{Call: call}
+}
+
+// MockFileStoreGetCall wrap *gomock.Call
+type MockFileStoreGetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFileStoreGetCall) Return(arg0 io.ReadSeekCloser, arg1 error) *MockFileStoreGetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFileStoreGetCall) Do(f func(context.Context, string) (io.ReadSeekCloser, error)) *MockFileStoreGetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFileStoreGetCall) DoAndReturn(f func(context.Context, string) (io.ReadSeekCloser, error)) *MockFileStoreGetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// List mocks base method.
+func (m *MockFileStore) List(ctx context.Context, prefix string) ([]*storage.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, prefix)
+	ret0, _ := ret[0].([]*storage.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFileStoreMockRecorder) List(ctx, prefix any) *MockFileStoreListCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFileStore)(nil).List), ctx, prefix)
+	return &MockFileStoreListCall{Call: call}
+}
+
+// MockFileStoreListCall wrap *gomock.Call
+type MockFileStoreListCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFileStoreListCall) Return(arg0 []*storage.FileInfo, arg1 error) *MockFileStoreListCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFileStoreListCall) Do(f func(context.Context, string) ([]*storage.FileInfo, error)) *MockFileStoreListCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFileStoreListCall) DoAndReturn(f func(context.Context, string) ([]*storage.FileInfo, error)) *MockFileStoreListCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Move mocks base method.
+func (m *MockFileStore) Move(ctx context.Context, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockFileStoreMockRecorder) Move(ctx, from, to any) *MockFileStoreMoveCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockFileStore)(nil).Move), ctx, from, to)
+	return &MockFileStoreMoveCall{Call: call}
+}
+
+// MockFileStoreMoveCall wrap *gomock.Call
+type MockFileStoreMoveCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFileStoreMoveCall) Return(arg0 error) *MockFileStoreMoveCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFileStoreMoveCall) Do(f func(context.Context, string, string) error) *MockFileStoreMoveCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFileStoreMoveCall) DoAndReturn(f func(context.Context, string, string) error) *MockFileStoreMoveCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Put mocks base method.
+func (m *MockFileStore) Put(ctx context.Context, key string, content io.Reader, size int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, key, content, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockFileStoreMockRecorder) Put(ctx, key, content, size any) *MockFileStorePutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockFileStore)(nil).Put), ctx, key, content, size)
+	return &MockFileStorePutCall{Call: call}
+}
+
+// MockFileStorePutCall wrap *gomock.Call
+type MockFileStorePutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFileStorePutCall) Return(arg0 error) *MockFileStorePutCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFileStorePutCall) Do(f func(context.Context, string, io.Reader, int64) error) *MockFileStorePutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFileStorePutCall) DoAndReturn(f func(context.Context, string, io.Reader, int64) error) *MockFileStorePutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stat mocks base method.
+func (m *MockFileStore) Stat(ctx context.Context, key string) (*storage.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", ctx, key)
+	ret0, _ := ret[0].(*storage.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat.
+func (mr *MockFileStoreMockRecorder) Stat(ctx, key any) *MockFileStoreStatCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockFileStore)(nil).Stat), ctx, key)
+	return &MockFileStoreStatCall{Call: call}
+}
+
+// MockFileStoreStatCall wrap *gomock.Call
+type MockFileStoreStatCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFileStoreStatCall) Return(arg0 *storage.FileInfo, arg1 error) *MockFileStoreStatCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFileStoreStatCall) Do(f func(context.Context, string) (*storage.FileInfo, error)) *MockFileStoreStatCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFileStoreStatCall) DoAndReturn(f func(context.Context, string) (*storage.FileInfo, error)) *MockFileStoreStatCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
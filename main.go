@@ -2,18 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/config"
 	"github.com/TheSandyDave/Media-Tags/router"
 	"github.com/sirupsen/logrus"
 )
 
-const shutdownTimeout = 30 * time.Second
-
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -22,24 +25,75 @@ func main() {
 
 	logger := logrus.WithContext(ctx)
 
-	serveAddress := "localhost:8080"
+	// API keys are managed with "create-api-key NAME ROLE" and "revoke-api-key NAME", followed by the usual settings
+	command, operands, args := "", []string(nil), os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+		for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			operands, args = append(operands, args[0]), args[1:]
+		}
+	}
+
+	configuration, err := config.Load(args, os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Fatal("invalid configuration")
+	}
+
+	// the level is validated when loading the configuration
+	level, _ := logrus.ParseLevel(configuration.Log.Level)
+	logrus.SetLevel(level)
 
 	API := router.TaggedMediaAPI{
-		Spec: spec,
+		Spec:   spec,
+		Config: configuration,
 	}
+
+	switch command {
+	case "":
+	case "create-api-key":
+		if len(operands) != 2 {
+			logger.Fatal("usage: create-api-key NAME ROLE [settings]")
+		}
+		role, err := auth.ParseRole(operands[1])
+		if err != nil {
+			logger.WithError(err).Fatal("invalid role")
+		}
+		key, err := API.CreateAPIKey(ctx, operands[0], role)
+		if err != nil {
+			logger.WithError(err).Fatal("failed creating API key")
+		}
+		// the key is only stored hashed, this is the only time it is shown
+		fmt.Println(key)
+		return
+	case "revoke-api-key":
+		if len(operands) != 1 {
+			logger.Fatal("usage: revoke-api-key NAME [settings]")
+		}
+		if err := API.RevokeAPIKey(ctx, operands[0]); err != nil {
+			logger.WithError(err).Fatal("failed revoking API key")
+		}
+		logger.Infof("revoked API key %s", operands[0])
+		return
+	default:
+		logger.Fatalf("unknown command %q, expected create-api-key or revoke-api-key", command)
+	}
+
 	router := API.Configure(ctx)
 
 	srv := &http.Server{
-		Addr:    serveAddress,
+		Addr:    configuration.Server.Address,
 		Handler: router,
 
-		ReadHeaderTimeout: 20 * time.Second,
+		ReadHeaderTimeout: configuration.Server.ReadHeaderTimeout,
 	}
 
 	// run server in goroutine so graceful shutdowns can be handled
 
 	go func() {
-		logger.Infof("starting server on %s", serveAddress)
+		logger.Infof("starting server on %s", configuration.Server.Address)
 
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Error("failed starting server")
@@ -49,10 +103,10 @@ func main() {
 	<-ctx.Done()
 	stop()
 
-	logger.Infof("shutdown signal received, shutting down in: %v", shutdownTimeout)
+	logger.Infof("shutdown signal received, shutting down in: %v", configuration.Server.ShutdownTimeout)
 
 	//initiate graceful shutdown timer on context to finish current request handling
-	timeoutContext, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	timeoutContext, cancel := context.WithTimeout(context.Background(), configuration.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(timeoutContext); err != nil {
@@ -0,0 +1,232 @@
+// Package metadata extracts the dimensions and EXIF metadata of uploaded images
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+	"golang.org/x/image/webp"
+)
+
+// exifTimeLayout is the format of EXIF date times, which carry no time zone
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// IDs of the tags holding the offsets from UTC of the date times, added in EXIF 2.31
+const (
+	offsetTime         uint16 = 0x9010
+	offsetTimeOriginal uint16 = 0x9011
+)
+
+var decodeConfigs = map[string]func(io.Reader) (image.Config, error){
+	"image/jpeg": jpeg.DecodeConfig,
+	"image/png":  png.DecodeConfig,
+	"image/gif":  gif.DecodeConfig,
+	"image/webp": webp.DecodeConfig,
+}
+
+// Supports reports whether metadata is extracted from content of the MIME type
+func Supports(mimeType string) bool {
+	_, ok := decodeConfigs[mimeType]
+	return ok
+}
+
+// Extract reads the dimensions and EXIF metadata of an image, fields that are not present in the content are left nil.
+// Broken or missing EXIF data is not an error, only content that is not an image of the MIME type is
+func Extract(content io.Reader, mimeType string) (*domain.MediaMetadata, error) {
+	decodeConfig, ok := decodeConfigs[mimeType]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	metadata := &domain.MediaMetadata{
+		Width:  &config.Width,
+		Height: &config.Height,
+	}
+
+	if exifData := exifBlock(data, mimeType); exifData != nil {
+		// a sub-IFD that fails to decode still leaves the other fields usable
+		if exifMetadata, err := exif.Decode(bytes.NewReader(exifData)); err == nil || (exifMetadata != nil && !exif.IsCriticalError(err)) {
+			applyExif(metadata, exifMetadata)
+		}
+	}
+
+	return metadata, nil
+}
+
+// exifBlock returns the part of the content containing the EXIF data, nil if the content has none
+func exifBlock(data []byte, mimeType string) []byte {
+	switch mimeType {
+	case "image/jpeg":
+		// the EXIF decoder finds the APP1 segment itself
+		return data
+	case "image/png":
+		return pngExifChunk(data)
+	case "image/webp":
+		return webpExifChunk(data)
+	}
+	return nil
+}
+
+// pngExifChunk finds the eXIf chunk, which holds raw TIFF data
+func pngExifChunk(data []byte) []byte {
+	// skip the signature, every chunk has a 4 byte length, a 4 byte type, its data and a 4 byte CRC
+	for offset := 8; offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunkType := string(data[offset+4 : offset+8])
+		start, end := offset+8, offset+8+length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		if chunkType == "eXIf" {
+			return data[start:end]
+		}
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			// EXIF data has to come before the image data to be considered
+			return nil
+		}
+		offset = end + 4
+	}
+	return nil
+}
+
+// webpExifChunk finds the EXIF chunk of the RIFF container
+func webpExifChunk(data []byte) []byte {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil
+	}
+
+	// every chunk has a 4 byte identifier, a 4 byte length and its data padded to an even length
+	for offset := 12; offset+8 <= len(data); {
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		start, end := offset+8, offset+8+length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		if string(data[offset:offset+4]) == "EXIF" {
+			return data[start:end]
+		}
+		offset = end + length%2
+	}
+	return nil
+}
+
+func applyExif(metadata *domain.MediaMetadata, exifMetadata *exif.Exif) {
+	if orientation, ok := intTag(exifMetadata, exif.Orientation); ok && orientation >= 1 && orientation <= 8 {
+		metadata.Orientation = &orientation
+	}
+	metadata.CameraMake = stringTag(exifMetadata, exif.Make)
+	metadata.CameraModel = stringTag(exifMetadata, exif.Model)
+	metadata.TakenAt = captureTime(exifMetadata)
+
+	if latitude, longitude, err := exifMetadata.LatLong(); err == nil {
+		metadata.Latitude = &latitude
+		metadata.Longitude = &longitude
+	}
+}
+
+// captureTime reads the original date time, falling back to the date time of the last change.
+// Without an offset tag the time is assumed to be UTC
+func captureTime(exifMetadata *exif.Exif) *time.Time {
+	for _, field := range []struct {
+		time   exif.FieldName
+		offset uint16
+	}{
+		{exif.DateTimeOriginal, offsetTimeOriginal},
+		{exif.DateTime, offsetTime},
+	} {
+		value := stringTag(exifMetadata, field.time)
+		if value == nil {
+			continue
+		}
+
+		location := time.UTC
+		if offset := exifSubTag(exifMetadata, field.offset); offset != nil {
+			if offsetValue, err := offset.StringVal(); err == nil {
+				if parsed, err := time.Parse("-07:00", strings.TrimRight(offsetValue, "\x00")); err == nil {
+					location = parsed.Location()
+				}
+			}
+		}
+
+		if takenAt, err := time.ParseInLocation(exifTimeLayout, *value, location); err == nil {
+			takenAt = takenAt.UTC()
+			return &takenAt
+		}
+	}
+	return nil
+}
+
+// exifSubTag finds a tag of the EXIF sub-IFD by its ID, needed for tags the EXIF decoder does not know about
+func exifSubTag(exifMetadata *exif.Exif, id uint16) *tiff.Tag {
+	pointer, err := exifMetadata.Get(exif.ExifIFDPointer)
+	if err != nil {
+		return nil
+	}
+	offset, err := pointer.Int64(0)
+	if err != nil {
+		return nil
+	}
+
+	reader := bytes.NewReader(exifMetadata.Raw)
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return nil
+	}
+	directory, _, err := tiff.DecodeDir(reader, exifMetadata.Tiff.Order)
+	if err != nil {
+		return nil
+	}
+
+	for _, tag := range directory.Tags {
+		if tag.Id == id && tag.Format() == tiff.StringVal {
+			return tag
+		}
+	}
+	return nil
+}
+
+func stringTag(exifMetadata *exif.Exif, name exif.FieldName) *string {
+	tag, err := exifMetadata.Get(name)
+	if err != nil || tag.Format() != tiff.StringVal {
+		return nil
+	}
+
+	value, err := tag.StringVal()
+	if err != nil {
+		return nil
+	}
+	// values are often padded with spaces or NUL characters
+	value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func intTag(exifMetadata *exif.Exif, name exif.FieldName) (int, bool) {
+	tag, err := exifMetadata.Get(name)
+	if err != nil || tag.Format() != tiff.IntVal {
+		return 0, false
+	}
+
+	value, err := tag.Int(0)
+	return value, err == nil
+}
@@ -0,0 +1,244 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tiffTag is a single entry of an IFD
+type tiffTag struct {
+	id    uint16
+	kind  uint16
+	count uint32
+	data  []byte
+}
+
+func asciiTag(id uint16, value string) tiffTag {
+	return tiffTag{id: id, kind: 2, count: uint32(len(value) + 1), data: append([]byte(value), 0)}
+}
+
+func shortTag(id uint16, value uint16) tiffTag {
+	return tiffTag{id: id, kind: 3, count: 1, data: binary.LittleEndian.AppendUint16(nil, value)}
+}
+
+func longTag(id uint16, value uint32) tiffTag {
+	return tiffTag{id: id, kind: 4, count: 1, data: binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+func rationalTag(id uint16, values ...[2]uint32) tiffTag {
+	var data []byte
+	for _, value := range values {
+		data = binary.LittleEndian.AppendUint32(data, value[0])
+		data = binary.LittleEndian.AppendUint32(data, value[1])
+	}
+	return tiffTag{id: id, kind: 5, count: uint32(len(values)), data: data}
+}
+
+// buildTiff lays out the IFDs one after the other followed by the values that don't fit in an entry,
+// pointers to the EXIF and GPS IFDs are added to the first IFD when they have tags
+func buildTiff(ifd0 []tiffTag, exifIFD []tiffTag, gpsIFD []tiffTag) []byte {
+	ifdSize := func(tags []tiffTag) uint32 {
+		return uint32(2 + 12*len(tags) + 4)
+	}
+
+	ifds := [][]tiffTag{ifd0}
+	if len(exifIFD) > 0 {
+		ifds[0] = append(ifds[0], longTag(0x8769, 0))
+		ifds = append(ifds, exifIFD)
+	}
+	if len(gpsIFD) > 0 {
+		ifds[0] = append(ifds[0], longTag(0x8825, 0))
+		ifds = append(ifds, gpsIFD)
+	}
+
+	offsets := []uint32{8}
+	for _, ifd := range ifds[:len(ifds)-1] {
+		offsets = append(offsets, offsets[len(offsets)-1]+ifdSize(ifd))
+	}
+	dataOffset := offsets[len(offsets)-1] + ifdSize(ifds[len(ifds)-1])
+	// fill in the pointers now the offsets are known
+	for i := 1; i < len(ifds); i++ {
+		ifds[0][len(ifds[0])-len(ifds)+i].data = binary.LittleEndian.AppendUint32(nil, offsets[i])
+	}
+
+	result := []byte("II*\x00")
+	result = binary.LittleEndian.AppendUint32(result, 8)
+	var data []byte
+	for _, ifd := range ifds {
+		result = binary.LittleEndian.AppendUint16(result, uint16(len(ifd)))
+		for _, tag := range ifd {
+			result = binary.LittleEndian.AppendUint16(result, tag.id)
+			result = binary.LittleEndian.AppendUint16(result, tag.kind)
+			result = binary.LittleEndian.AppendUint32(result, tag.count)
+			if len(tag.data) <= 4 {
+				result = append(result, append(tag.data, make([]byte, 4-len(tag.data))...)...)
+			} else {
+				result = binary.LittleEndian.AppendUint32(result, dataOffset+uint32(len(data)))
+				data = append(data, tag.data...)
+			}
+		}
+		result = binary.LittleEndian.AppendUint32(result, 0)
+	}
+
+	return append(result, data...)
+}
+
+func jpegWithExif(t *testing.T, width int, height int, tiff []byte) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, width, height)), nil))
+
+	// the APP1 segment directly follows the start of image marker
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	content := encoded.Bytes()
+	return append(append(append([]byte{}, content[:2]...), app1...), content[2:]...)
+}
+
+func pngWithExif(t *testing.T, width int, height int, tiff []byte) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, image.NewGray(image.Rect(0, 0, width, height))))
+	content := encoded.Bytes()
+	if tiff == nil {
+		return content
+	}
+
+	// the signature and the IHDR chunk take 8 + 25 bytes, the eXIf chunk follows
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(tiff)))
+	chunk = append(chunk, "eXIf"...)
+	chunk = append(chunk, tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	return append(append(append([]byte{}, content[:33]...), chunk...), content[33:]...)
+}
+
+func Test_Extract_ReadsExifFromJPEG(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tiff := buildTiff(
+		[]tiffTag{asciiTag(0x010F, "Canon"), asciiTag(0x0110, "Canon EOS 5D"), shortTag(0x0112, 6)},
+		[]tiffTag{asciiTag(0x9003, "2024:05:01 14:30:00"), asciiTag(0x9011, "+02:00")},
+		[]tiffTag{
+			asciiTag(0x0001, "N"),
+			rationalTag(0x0002, [2]uint32{52, 1}, [2]uint32{30, 1}, [2]uint32{0, 1}),
+			asciiTag(0x0003, "W"),
+			rationalTag(0x0004, [2]uint32{4, 1}, [2]uint32{15, 1}, [2]uint32{0, 1}),
+		},
+	)
+	content := jpegWithExif(t, 40, 30, tiff)
+
+	// Act
+	metadata, err := Extract(bytes.NewReader(content), "image/jpeg")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 40, *metadata.Width)
+	assert.Equal(t, 30, *metadata.Height)
+	assert.Equal(t, 6, *metadata.Orientation)
+	assert.Equal(t, "Canon", *metadata.CameraMake)
+	assert.Equal(t, "Canon EOS 5D", *metadata.CameraModel)
+	if assert.NotNil(t, metadata.TakenAt) {
+		assert.Equal(t, time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), *metadata.TakenAt)
+	}
+	if assert.NotNil(t, metadata.Latitude) && assert.NotNil(t, metadata.Longitude) {
+		assert.InDelta(t, 52.5, *metadata.Latitude, 0.0001)
+		assert.InDelta(t, -4.25, *metadata.Longitude, 0.0001)
+	}
+}
+
+func Test_Extract_ReadsExifFromPNG(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tiff := buildTiff(
+		[]tiffTag{asciiTag(0x010F, "Apple"), asciiTag(0x0132, "2023:12:31 23:59:59")},
+		nil,
+		nil,
+	)
+	content := pngWithExif(t, 8, 4, tiff)
+
+	// Act
+	metadata, err := Extract(bytes.NewReader(content), "image/png")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 8, *metadata.Width)
+	assert.Equal(t, 4, *metadata.Height)
+	assert.Equal(t, "Apple", *metadata.CameraMake)
+	assert.Nil(t, metadata.CameraModel)
+	if assert.NotNil(t, metadata.TakenAt, "the date time of the last change should be used without an original date time") {
+		assert.Equal(t, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), *metadata.TakenAt)
+	}
+	assert.Nil(t, metadata.Latitude)
+}
+
+func Test_Extract_OnlyReadsDimensionsWithoutExif(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := pngWithExif(t, 3, 5, nil)
+
+	// Act
+	metadata, err := Extract(bytes.NewReader(content), "image/png")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, *metadata.Width)
+	assert.Equal(t, 5, *metadata.Height)
+	assert.Nil(t, metadata.Orientation)
+	assert.Nil(t, metadata.CameraMake)
+	assert.Nil(t, metadata.TakenAt)
+	assert.Nil(t, metadata.Latitude)
+}
+
+func Test_Extract_IgnoresBrokenExif(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := jpegWithExif(t, 2, 2, []byte("II*\x00garbage"))
+
+	// Act
+	metadata, err := Extract(bytes.NewReader(content), "image/jpeg")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, *metadata.Width)
+	assert.Nil(t, metadata.CameraMake)
+}
+
+func Test_Extract_FailsOnContentThatIsNotAnImage(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := Extract(bytes.NewReader([]byte("not an image")), "image/png")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func Test_Extract_SkipsUnsupportedTypes(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	metadata, err := Extract(bytes.NewReader([]byte("%PDF-1.7")), "application/pdf")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+}
@@ -0,0 +1,476 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+)
+
+// Block is a group of metadata that can be stripped from stored images
+type Block string
+
+const (
+	// BlockLocation is the GPS information
+	BlockLocation Block = "location"
+	// BlockSerialNumbers are the serial numbers of the camera body and lens, the unique ID of the image and the
+	// maker notes, which cameras commonly use to record serial numbers as well
+	BlockSerialNumbers Block = "serialNumbers"
+	// BlockAll is every EXIF, XMP and IPTC block, only the EXIF orientation is kept since viewers rotate the image
+	// by it
+	BlockAll Block = "all"
+)
+
+var Blocks = []Block{BlockLocation, BlockSerialNumbers, BlockAll}
+
+// Policy decides which metadata is stripped from stored images. XMP can repeat any EXIF field, so it is removed
+// whenever anything is stripped
+type Policy struct {
+	Location      bool
+	SerialNumbers bool
+	All           bool
+}
+
+// ParsePolicy builds the policy stripping the named blocks
+func ParsePolicy(blocks []string) (Policy, error) {
+	var policy Policy
+	for _, block := range blocks {
+		switch Block(block) {
+		case BlockLocation:
+			policy.Location = true
+		case BlockSerialNumbers:
+			policy.SerialNumbers = true
+		case BlockAll:
+			policy.All = true
+		default:
+			return Policy{}, fmt.Errorf("unknown metadata block %q, expected one of %v", block, Blocks)
+		}
+	}
+	return policy, nil
+}
+
+// Empty reports whether the policy leaves the content untouched
+func (policy Policy) Empty() bool {
+	return !policy.Location && !policy.SerialNumbers && !policy.All
+}
+
+// Merge returns the policy stripping the blocks of both policies
+func (policy Policy) Merge(other Policy) Policy {
+	return Policy{
+		Location:      policy.Location || other.Location,
+		SerialNumbers: policy.SerialNumbers || other.SerialNumbers,
+		All:           policy.All || other.All,
+	}
+}
+
+// Redact marks the extracted fields the policy strips from the stored file, record decides whether they are kept
+// at all
+func (policy Policy) Redact(extracted *domain.MediaMetadata, record bool) {
+	if extracted == nil {
+		return
+	}
+
+	if policy.Location || policy.All {
+		extracted.LocationStripped = true
+		if !record {
+			extracted.Latitude, extracted.Longitude = nil, nil
+		}
+	}
+	if policy.All {
+		extracted.ExifStripped = true
+		if !record {
+			extracted.CameraMake, extracted.CameraModel, extracted.TakenAt = nil, nil, nil
+		}
+	}
+}
+
+var strippers = map[string]func(data []byte, policy Policy) []byte{
+	"image/jpeg": stripJPEG,
+	"image/png":  stripPNG,
+	"image/webp": stripWebP,
+}
+
+// CanStrip reports whether metadata is stripped from content of the MIME type
+func CanStrip(mimeType string) bool {
+	_, ok := strippers[mimeType]
+	return ok
+}
+
+// Strip rewrites the content without the metadata blocks of the policy, the image data itself is not touched.
+// EXIF data that can't be parsed is removed entirely rather than risking leaving the blocks behind
+func Strip(data []byte, mimeType string, policy Policy) []byte {
+	stripper, ok := strippers[mimeType]
+	if !ok || policy.Empty() {
+		return data
+	}
+	return stripper(data, policy)
+}
+
+// IDs of the EXIF tags removed by the policies and of the orientation, which is always kept
+const (
+	orientationTag     uint16 = 0x0112
+	exifIFDPointer     uint16 = 0x8769
+	gpsIFDPointer      uint16 = 0x8825
+	makerNote          uint16 = 0x927C
+	imageUniqueID      uint16 = 0xA420
+	bodySerialNumber   uint16 = 0xA431
+	lensSerialNumber   uint16 = 0xA435
+	cameraSerialNumber uint16 = 0xC62F
+)
+
+// Identifiers of the JPEG APP1 segments holding metadata
+var (
+	jpegExifIdentifier        = []byte("Exif\x00\x00")
+	jpegXMPIdentifier         = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	jpegExtendedXMPIdentifier = []byte("http://ns.adobe.com/xmp/extension/\x00")
+)
+
+const (
+	jpegAPP1  = 0xE1
+	jpegAPP13 = 0xED
+	jpegSOS   = 0xDA
+)
+
+// stripJPEG removes or rewrites the metadata segments in front of the image data
+func stripJPEG(data []byte, policy Policy) []byte {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+
+	result := append(make([]byte, 0, len(data)), data[:2]...)
+	offset := 2
+	for offset+4 <= len(data) && data[offset] == 0xFF {
+		marker := data[offset+1]
+		if marker == 0xFF {
+			// markers can be preceded by any number of fill bytes
+			offset++
+			continue
+		}
+		if marker == jpegSOS {
+			break
+		}
+		end := offset + 2 + int(binary.BigEndian.Uint16(data[offset+2:]))
+		if end > len(data) {
+			break
+		}
+		segment := data[offset:end]
+		payload := segment[4:]
+		offset = end
+
+		switch {
+		case marker == jpegAPP1 && bytes.HasPrefix(payload, jpegExifIdentifier):
+			tiff, ok := rewriteTIFF(payload[len(jpegExifIdentifier):], policy)
+			if !ok {
+				continue
+			}
+			result = binary.BigEndian.AppendUint16(append(result, 0xFF, jpegAPP1), uint16(2+len(jpegExifIdentifier)+len(tiff)))
+			result = append(append(result, jpegExifIdentifier...), tiff...)
+		case marker == jpegAPP1 && (bytes.HasPrefix(payload, jpegXMPIdentifier) || bytes.HasPrefix(payload, jpegExtendedXMPIdentifier)):
+			continue
+		case marker == jpegAPP13 && policy.All:
+			continue
+		default:
+			result = append(result, segment...)
+		}
+	}
+
+	return append(result, data[offset:]...)
+}
+
+// keywords of the PNG text chunks holding XMP or, written by ImageMagick, EXIF data
+var pngMetadataKeywords = []string{"XML:com.adobe.xmp", "Raw profile type exif", "Raw profile type xmp", "Raw profile type APP1", "Raw profile type iptc"}
+
+// stripPNG removes or rewrites the eXIf chunk and removes the text chunks holding metadata
+func stripPNG(data []byte, policy Policy) []byte {
+	if len(data) < 8 {
+		return data
+	}
+
+	result := append(make([]byte, 0, len(data)), data[:8]...)
+	offset := 8
+	for offset+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		end := offset + 12 + length
+		if length < 0 || end > len(data) {
+			break
+		}
+		chunk := data[offset:end]
+		chunkType := string(chunk[4:8])
+		chunkData := chunk[8 : 8+length]
+		offset = end
+
+		switch chunkType {
+		case "eXIf":
+			tiff, ok := rewriteTIFF(chunkData, policy)
+			if !ok {
+				continue
+			}
+			rewritten := append(binary.BigEndian.AppendUint32(nil, uint32(len(tiff))), "eXIf"...)
+			rewritten = append(rewritten, tiff...)
+			result = append(result, binary.BigEndian.AppendUint32(rewritten, crc32.ChecksumIEEE(rewritten[4:]))...)
+		case "tEXt", "zTXt", "iTXt":
+			keyword, _, _ := bytes.Cut(chunkData, []byte{0})
+			if isPNGMetadataKeyword(string(keyword)) {
+				continue
+			}
+			result = append(result, chunk...)
+		default:
+			result = append(result, chunk...)
+		}
+	}
+
+	return append(result, data[offset:]...)
+}
+
+func isPNGMetadataKeyword(keyword string) bool {
+	for _, metadataKeyword := range pngMetadataKeywords {
+		if strings.EqualFold(keyword, metadataKeyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// flags of the VP8X chunk announcing metadata chunks
+const (
+	webpXMPFlag  = 0x04
+	webpExifFlag = 0x08
+)
+
+// stripWebP removes or rewrites the EXIF chunk and removes the XMP chunk, updating the container header to match
+func stripWebP(data []byte, policy Policy) []byte {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return data
+	}
+
+	result := append(make([]byte, 0, len(data)), data[:12]...)
+	vp8x := -1
+	removedFlags := byte(0)
+	offset := 12
+	for offset+8 <= len(data) {
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		// chunks are padded to an even length
+		end := offset + 8 + length + length%2
+		if length < 0 || end > len(data) {
+			break
+		}
+		chunk := data[offset:end]
+		offset = end
+
+		switch string(chunk[:4]) {
+		case "VP8X":
+			vp8x = len(result)
+			result = append(result, chunk...)
+		case "EXIF":
+			tiff, ok := rewriteTIFF(chunk[8:8+length], policy)
+			if !ok {
+				removedFlags |= webpExifFlag
+				continue
+			}
+			result = binary.LittleEndian.AppendUint32(append(result, "EXIF"...), uint32(len(tiff)))
+			result = append(result, tiff...)
+			if len(tiff)%2 == 1 {
+				result = append(result, 0)
+			}
+		case "XMP ":
+			removedFlags |= webpXMPFlag
+		default:
+			result = append(result, chunk...)
+		}
+	}
+	result = append(result, data[offset:]...)
+
+	if vp8x >= 0 && len(result) > vp8x+8 {
+		result[vp8x+8] &^= removedFlags
+	}
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result
+}
+
+// sizes in bytes of the values of every TIFF field type
+var tiffTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// tiffDirectory is an IFD of TIFF data, used to remove entries in place so no other offset has to change
+type tiffDirectory struct {
+	data   []byte
+	order  binary.ByteOrder
+	offset int
+}
+
+// rewriteTIFF returns the TIFF data of an EXIF block without the tags of the policy, ok is false if the whole
+// block has to be removed
+func rewriteTIFF(data []byte, policy Policy) (result []byte, ok bool) {
+	if policy.All {
+		return orientationTIFF(data)
+	}
+	return stripTIFF(data, policy)
+}
+
+// parseTIFF finds the first IFD of the TIFF data, ok is false if the data is malformed
+func parseTIFF(data []byte) (ifd0 tiffDirectory, ok bool) {
+	if len(data) < 8 {
+		return tiffDirectory{}, false
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return tiffDirectory{}, false
+	}
+
+	ifd0 = tiffDirectory{data: data, order: order, offset: int(order.Uint32(data[4:]))}
+	return ifd0, ifd0.valid()
+}
+
+// orientationTIFF returns new TIFF data holding nothing but the orientation of the original, ok is false if the
+// original has no orientation to keep
+func orientationTIFF(original []byte) (result []byte, ok bool) {
+	ifd0, ok := parseTIFF(original)
+	if !ok {
+		return nil, false
+	}
+
+	for i := 0; i < ifd0.count(); i++ {
+		entry := ifd0.entry(i)
+		if ifd0.order.Uint16(entry) != orientationTag || ifd0.order.Uint16(entry[2:]) != 3 || ifd0.order.Uint32(entry[4:]) != 1 {
+			continue
+		}
+		// the header followed by a directory with the single entry and no next directory
+		result = make([]byte, 8+2+12+4)
+		copy(result, original[:4])
+		ifd0.order.PutUint32(result[4:], 8)
+		ifd0.order.PutUint16(result[8:], 1)
+		copy(result[10:], entry)
+		return result, true
+	}
+	return nil, false
+}
+
+// stripTIFF returns a copy of the TIFF data without the tags of the policy, ok is false if the data is malformed
+func stripTIFF(original []byte, policy Policy) (result []byte, ok bool) {
+	data := append([]byte{}, original...)
+	ifd0, ok := parseTIFF(data)
+	if !ok {
+		return nil, false
+	}
+
+	if policy.Location {
+		if gps, found := ifd0.pointer(gpsIFDPointer); found {
+			if !gps.valid() {
+				return nil, false
+			}
+			gps.erase()
+			ifd0.remove(gpsIFDPointer)
+		}
+	}
+
+	if policy.SerialNumbers {
+		ifd0.remove(cameraSerialNumber)
+		if exifIFD, found := ifd0.pointer(exifIFDPointer); found {
+			if !exifIFD.valid() {
+				return nil, false
+			}
+			exifIFD.remove(makerNote, imageUniqueID, bodySerialNumber, lensSerialNumber)
+		}
+	}
+
+	return data, true
+}
+
+func (directory tiffDirectory) count() int {
+	return int(directory.order.Uint16(directory.data[directory.offset:]))
+}
+
+func (directory tiffDirectory) entry(index int) []byte {
+	start := directory.offset + 2 + 12*index
+	return directory.data[start : start+12]
+}
+
+// size is the number of bytes of the directory, including the offset of the next directory
+func (directory tiffDirectory) size() int {
+	return 2 + 12*directory.count() + 4
+}
+
+// valid checks that the entries and the values they point to are within the data
+func (directory tiffDirectory) valid() bool {
+	if directory.offset < 8 || directory.offset+2 > len(directory.data) || directory.offset+directory.size() > len(directory.data) {
+		return false
+	}
+	for i := 0; i < directory.count(); i++ {
+		if start, end, outside := directory.value(directory.entry(i)); outside && (start < 0 || end > len(directory.data)) {
+			return false
+		}
+	}
+	return true
+}
+
+// value returns the range of the value of an entry, outside is false for values stored within the entry itself
+func (directory tiffDirectory) value(entry []byte) (start int, end int, outside bool) {
+	size := tiffTypeSizes[directory.order.Uint16(entry[2:])] * int(directory.order.Uint32(entry[4:]))
+	if size <= 4 {
+		return 0, 0, false
+	}
+	start = int(directory.order.Uint32(entry[8:]))
+	return start, start + size, true
+}
+
+// pointer finds the sub-IFD a tag of the directory points to
+func (directory tiffDirectory) pointer(id uint16) (tiffDirectory, bool) {
+	for i := 0; i < directory.count(); i++ {
+		entry := directory.entry(i)
+		if directory.order.Uint16(entry) == id {
+			return tiffDirectory{data: directory.data, order: directory.order, offset: int(directory.order.Uint32(entry[8:]))}, true
+		}
+	}
+	return tiffDirectory{}, false
+}
+
+// remove deletes the entries with the IDs, zeroing their values. The remaining entries move up and the freed
+// space at the end of the directory is zeroed
+func (directory tiffDirectory) remove(ids ...uint16) {
+	count := directory.count()
+	kept := 0
+	for i := 0; i < count; i++ {
+		entry := directory.entry(i)
+		removed := false
+		for _, id := range ids {
+			if directory.order.Uint16(entry) == id {
+				removed = true
+			}
+		}
+		if removed {
+			if start, end, outside := directory.value(entry); outside {
+				clear(directory.data[start:end])
+			}
+			continue
+		}
+		copy(directory.entry(kept), entry)
+		kept++
+	}
+	if kept == count {
+		return
+	}
+
+	next := directory.order.Uint32(directory.data[directory.offset+2+12*count:])
+	directory.order.PutUint16(directory.data[directory.offset:], uint16(kept))
+	nextOffset := directory.offset + 2 + 12*kept
+	directory.order.PutUint32(directory.data[nextOffset:], next)
+	clear(directory.data[nextOffset+4 : directory.offset+2+12*count+4])
+}
+
+// erase zeroes the directory and the values of its entries
+func (directory tiffDirectory) erase() {
+	for i := 0; i < directory.count(); i++ {
+		if start, end, outside := directory.value(directory.entry(i)); outside {
+			clear(directory.data[start:end])
+		}
+	}
+	clear(directory.data[directory.offset : directory.offset+directory.size()])
+}
@@ -0,0 +1,277 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sensitiveTiff() []byte {
+	return buildTiff(
+		[]tiffTag{
+			asciiTag(0x010F, "Canon"),
+			asciiTag(0x0110, "Canon EOS 5D"),
+			shortTag(orientationTag, 6),
+			asciiTag(cameraSerialNumber, "CAMERA-SERIAL"),
+		},
+		[]tiffTag{
+			asciiTag(0x9003, "2024:05:01 14:30:00"),
+			asciiTag(bodySerialNumber, "BODY-SERIAL"),
+			asciiTag(lensSerialNumber, "LENS-SERIAL"),
+			{id: makerNote, kind: 7, count: 16, data: []byte("MAKER-NOTE-DATA!")},
+		},
+		[]tiffTag{
+			asciiTag(0x0001, "N"),
+			rationalTag(0x0002, [2]uint32{52, 1}, [2]uint32{30, 1}, [2]uint32{0, 1}),
+			asciiTag(0x0003, "E"),
+			rationalTag(0x0004, [2]uint32{4, 1}, [2]uint32{15, 1}, [2]uint32{0, 1}),
+			asciiTag(0x001D, "2024:05:01"),
+		},
+	)
+}
+
+func Test_ParsePolicy_ParsesBlocks(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		blocks        []string
+		expected      Policy
+		expectedError bool
+	}{
+		"nothing":        {blocks: nil, expected: Policy{}},
+		"location":       {blocks: []string{"location"}, expected: Policy{Location: true}},
+		"several blocks": {blocks: []string{"serialNumbers", "all"}, expected: Policy{SerialNumbers: true, All: true}},
+		"unknown block":  {blocks: []string{"location", "faces"}, expectedError: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			policy, err := ParsePolicy(testCase.blocks)
+
+			// Assert
+			if testCase.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expected, policy)
+			}
+		})
+	}
+}
+
+func Test_Strip_RemovesSelectedBlocksFromJPEG(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		policy         Policy
+		expectLocation bool
+		expectSerials  bool
+		expectCamera   bool
+	}{
+		"location": {
+			policy:        Policy{Location: true},
+			expectSerials: true,
+			expectCamera:  true,
+		},
+		"serial numbers": {
+			policy:         Policy{SerialNumbers: true},
+			expectLocation: true,
+			expectCamera:   true,
+		},
+		"location and serial numbers": {
+			policy:       Policy{Location: true, SerialNumbers: true},
+			expectCamera: true,
+		},
+		"all": {
+			policy: Policy{All: true},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			content := jpegWithExif(t, 6, 4, sensitiveTiff())
+
+			// Act
+			stripped := Strip(content, "image/jpeg", testCase.policy)
+
+			// Assert
+			metadata, err := Extract(bytes.NewReader(stripped), "image/jpeg")
+			require.NoError(t, err, "the stripped image should still decode")
+			assert.Equal(t, 6, *metadata.Width)
+
+			assert.Equal(t, testCase.expectLocation, metadata.Latitude != nil)
+			assert.Equal(t, testCase.expectLocation, bytes.Contains(stripped, []byte("2024:05:01\x00")), "the GPS date should only remain with the location")
+			for _, serial := range []string{"CAMERA-SERIAL", "BODY-SERIAL", "LENS-SERIAL", "MAKER-NOTE-DATA!"} {
+				assert.Equal(t, testCase.expectSerials, bytes.Contains(stripped, []byte(serial)), serial)
+			}
+			assert.Equal(t, testCase.expectCamera, metadata.CameraMake != nil)
+			assert.Equal(t, testCase.expectCamera, metadata.TakenAt != nil)
+			if assert.NotNil(t, metadata.Orientation, "the orientation should always be kept") {
+				assert.Equal(t, 6, *metadata.Orientation)
+			}
+		})
+	}
+}
+
+func Test_Strip_RemovesXMPFromJPEG(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := jpegWithExif(t, 2, 2, sensitiveTiff())
+	xmp := append(append([]byte{}, jpegXMPIdentifier...), "<x:xmpmeta>exif:GPSLatitude</x:xmpmeta>"...)
+	segment := binary.BigEndian.AppendUint16([]byte{0xFF, jpegAPP1}, uint16(len(xmp)+2))
+	content = append(append(append([]byte{}, content[:2]...), append(segment, xmp...)...), content[2:]...)
+
+	// Act
+	stripped := Strip(content, "image/jpeg", Policy{SerialNumbers: true})
+
+	// Assert
+	assert.NotContains(t, string(stripped), "GPSLatitude")
+	metadata, err := Extract(bytes.NewReader(stripped), "image/jpeg")
+	require.NoError(t, err)
+	assert.NotNil(t, metadata.Latitude, "only the XMP packet should be removed for the location")
+}
+
+func Test_Strip_RemovesBrokenExif(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	// the first IFD is cut off halfway
+	content := jpegWithExif(t, 2, 2, sensitiveTiff()[:40])
+
+	// Act
+	stripped := Strip(content, "image/jpeg", Policy{Location: true})
+
+	// Assert
+	assert.False(t, bytes.Contains(stripped, jpegExifIdentifier))
+	_, err := Extract(bytes.NewReader(stripped), "image/jpeg")
+	assert.NoError(t, err)
+}
+
+func Test_Strip_RemovesExifWithoutOrientation(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := jpegWithExif(t, 2, 2, buildTiff([]tiffTag{asciiTag(0x010F, "Canon")}, nil, nil))
+
+	// Act
+	stripped := Strip(content, "image/jpeg", Policy{All: true})
+
+	// Assert
+	assert.False(t, bytes.Contains(stripped, jpegExifIdentifier))
+	_, err := Extract(bytes.NewReader(stripped), "image/jpeg")
+	assert.NoError(t, err)
+}
+
+func Test_Strip_RewritesPNGExif(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := pngWithExif(t, 3, 3, sensitiveTiff())
+
+	// Act
+	stripped := Strip(content, "image/png", Policy{Location: true, SerialNumbers: true})
+
+	// Assert
+	metadata, err := Extract(bytes.NewReader(stripped), "image/png")
+	require.NoError(t, err)
+	assert.Nil(t, metadata.Latitude)
+	assert.Equal(t, "Canon", *metadata.CameraMake)
+	assert.NotContains(t, string(stripped), "BODY-SERIAL")
+	assert.Len(t, stripped, len(content), "EXIF data is rewritten in place")
+}
+
+func Test_Strip_RemovesPNGExifAndXMP(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := pngWithExif(t, 3, 3, sensitiveTiff())
+	xmp := []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(xmp)))
+	chunk = append(append(chunk, "iTXt"...), xmp...)
+	chunk = binary.BigEndian.AppendUint32(chunk, 0)
+	content = append(append(append([]byte{}, content[:33]...), chunk...), content[33:]...)
+
+	// Act
+	stripped := Strip(content, "image/png", Policy{All: true})
+
+	// Assert
+	assert.NotContains(t, string(pngExifChunk(stripped)), "Canon", "only the orientation should be left")
+	assert.NotContains(t, string(stripped), "xmpmeta")
+	metadata, err := Extract(bytes.NewReader(stripped), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, 3, *metadata.Width)
+	assert.Nil(t, metadata.CameraMake)
+	if assert.NotNil(t, metadata.Orientation) {
+		assert.Equal(t, 6, *metadata.Orientation)
+	}
+}
+
+func Test_Strip_RewritesWebPContainer(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	chunk := func(id string, data []byte) []byte {
+		result := binary.LittleEndian.AppendUint32([]byte(id), uint32(len(data)))
+		result = append(result, data...)
+		if len(data)%2 == 1 {
+			result = append(result, 0)
+		}
+		return result
+	}
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpExifFlag | webpXMPFlag
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", vp8x)...)
+	body = append(body, chunk("VP8L", []byte("image"))...)
+	body = append(body, chunk("EXIF", sensitiveTiff())...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	content := append(binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body))), body...)
+
+	testCases := map[string]struct {
+		policy       Policy
+		expectedFlag byte
+	}{
+		"location": {policy: Policy{Location: true}, expectedFlag: webpExifFlag},
+		"all":      {policy: Policy{All: true}, expectedFlag: webpExifFlag},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			stripped := Strip(content, "image/webp", testCase.policy)
+
+			// Assert
+			assert.Equal(t, uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:]))
+			assert.Equal(t, testCase.expectedFlag, stripped[20])
+			assert.NotContains(t, string(stripped), "xmpmeta")
+			assert.Contains(t, string(stripped), "image", "the image data should be kept")
+
+			exifData := webpExifChunk(stripped)
+			assert.NotNil(t, exifData, "the orientation should always be kept")
+			assert.NotContains(t, string(exifData), "2024:05:01\x00")
+		})
+	}
+}
+
+func Test_Strip_LeavesContentUntouched(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	content := jpegWithExif(t, 2, 2, sensitiveTiff())
+
+	// Assert
+	assert.Equal(t, content, Strip(content, "image/jpeg", Policy{}), "an empty policy strips nothing")
+	assert.Equal(t, []byte("GIF89a"), Strip([]byte("GIF89a"), "image/gif", Policy{All: true}), "types without strippable metadata are kept")
+}
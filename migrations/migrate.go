@@ -0,0 +1,190 @@
+// Package migrations brings the database schema and the rows in it up to date with the domain models
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"gorm.io/gorm"
+)
+
+// legacyFilesPath is the path the file URLs stored before storage keys existed point to the stored file under
+const legacyFilesPath = "/files/"
+
+// Migrate creates or updates the tables of the domain models and converts rows stored by earlier versions. Every
+// migration only changes what it has not converted before, so it is safe to run on every start
+func Migrate(ctx context.Context, database *gorm.DB) error {
+	if err := database.AutoMigrate(domain.Models...); err != nil {
+		return err
+	}
+
+	for _, model := range []any{&domain.Media{}, &domain.Thumbnail{}} {
+		if err := storageKeysFromFileURLs(ctx, database, model); err != nil {
+			return err
+		}
+	}
+
+	if err := rolesOfAPIKeys(ctx, database); err != nil {
+		return err
+	}
+	for _, model := range []any{&domain.Media{}, &domain.Tag{}, &domain.Upload{}} {
+		if err := subjectsOfOwners(ctx, database, model); err != nil {
+			return err
+		}
+	}
+
+	if err := referencesOfStoredFiles(ctx, database); err != nil {
+		return err
+	}
+
+	for _, model := range domain.Models {
+		if err := creationTimesInUTC(ctx, database, model); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storageKeysFromFileURLs derives the storage keys of rows stored with a file URL instead of a key, e.g.
+// localhost:8080/files/abc.png is stored under abc.png. The file URLs are removed once converted since they are
+// derived from the storage key whenever the rows are returned
+func storageKeysFromFileURLs(ctx context.Context, database *gorm.DB, model any) error {
+	logger := utils.NewLogger(ctx)
+
+	migrator := database.Migrator()
+	if !migrator.HasColumn(model, "file_url") {
+		return nil
+	}
+
+	// the key is whatever follows the files path, UpdateColumn leaves UpdatedAt alone since the file didn't change
+	converted := database.Model(model).
+		Where("storage_key = '' OR storage_key IS NULL").
+		Where("instr(file_url, ?) > 0", legacyFilesPath).
+		UpdateColumn("storage_key", gorm.Expr("substr(file_url, instr(file_url, ?) + ?)", legacyFilesPath, len(legacyFilesPath)))
+	if converted.Error != nil {
+		return fmt.Errorf("failed deriving storage keys from file URLs: %w", converted.Error)
+	}
+
+	var unconverted int64
+	if err := database.Model(model).Where("storage_key = '' OR storage_key IS NULL").Count(&unconverted).Error; err != nil {
+		return err
+	}
+	if unconverted > 0 {
+		// these can't be served either way, dropping the column doesn't lose a working URL
+		logger.WithField("rows", unconverted).Warn("rows without a storage key whose file URL has no key either")
+	}
+
+	if err := migrator.DropColumn(model, "file_url"); err != nil {
+		return fmt.Errorf("failed dropping file URLs: %w", err)
+	}
+	logger.WithField("rows", converted.RowsAffected).Info("converted file URLs to storage keys")
+
+	return nil
+}
+
+// rolesOfAPIKeys makes the API keys created before roles existed admins, they could do anything before. Tags and
+// media created back then have no owner, so only admins can change them
+func rolesOfAPIKeys(ctx context.Context, database *gorm.DB) error {
+	logger := utils.NewLogger(ctx)
+
+	converted := database.Model(&domain.APIKey{}).
+		Where("role = '' OR role IS NULL").
+		UpdateColumn("role", string(auth.RoleAdmin))
+	if converted.Error != nil {
+		return fmt.Errorf("failed assigning roles to API keys: %w", converted.Error)
+	}
+	if converted.RowsAffected > 0 {
+		logger.WithField("keys", converted.RowsAffected).Warn("API keys created before roles existed were made admins")
+	}
+
+	return nil
+}
+
+// subjectsOfOwners prefixes the owners stored before subjects named how the principal authenticated. Owners that are
+// the ID of an API key get its subject, the issuer of tokens wasn't stored so records owned through a token are left
+// to admins
+func subjectsOfOwners(ctx context.Context, database *gorm.DB, model any) error {
+	logger := utils.NewLogger(ctx)
+
+	converted := database.Model(model).
+		Where("owner_id IN (?)", database.Model(&domain.APIKey{}).Select("id")).
+		UpdateColumn("owner_id", gorm.Expr("? || owner_id", auth.APIKeySubject("")))
+	if converted.Error != nil {
+		return fmt.Errorf("failed converting owners to subjects: %w", converted.Error)
+	}
+	if converted.RowsAffected > 0 {
+		logger.WithField("rows", converted.RowsAffected).Info("converted owners to API key subjects")
+	}
+
+	var unconverted int64
+	if err := database.Model(model).
+		Where("owner_id <> '' AND owner_id NOT LIKE ? AND owner_id NOT LIKE ?", auth.APIKeySubject("%"), auth.TokenSubject("%", "%")).
+		Count(&unconverted).Error; err != nil {
+		return err
+	}
+	if unconverted > 0 {
+		logger.WithField("rows", unconverted).Warn("rows owned through a token can only be changed by admins")
+	}
+
+	return nil
+}
+
+// referencesOfStoredFiles counts the media and thumbnails referring to the files stored before references were
+// counted. Files that are counted already are left alone, their count has been kept up to date since
+func referencesOfStoredFiles(ctx context.Context, database *gorm.DB) error {
+	logger := utils.NewLogger(ctx)
+
+	// the WHERE clause keeps SQLite from parsing ON CONFLICT as a join constraint
+	counted := database.Exec(`INSERT INTO stored_files (storage_key, reference_count)
+		SELECT storage_key, COUNT(*) FROM (SELECT storage_key FROM media UNION ALL SELECT storage_key FROM thumbnails)
+		WHERE storage_key <> '' GROUP BY storage_key
+		ON CONFLICT (storage_key) DO NOTHING`)
+	if counted.Error != nil {
+		return fmt.Errorf("failed counting references to stored files: %w", counted.Error)
+	}
+	if counted.RowsAffected > 0 {
+		logger.WithField("files", counted.RowsAffected).Info("counted references to stored files")
+	}
+
+	return nil
+}
+
+// creationTimesInUTC converts creation times stored in another offset to UTC. SQLite stores times as text like
+// 2006-01-02 15:04:05.999999999-07:00, pagination compares them as text which is only correct in a single offset
+func creationTimesInUTC(ctx context.Context, database *gorm.DB, model any) error {
+	logger := utils.NewLogger(ctx)
+
+	if !database.Migrator().HasColumn(model, "created_at") {
+		return nil
+	}
+
+	var rows []struct {
+		ID        string
+		CreatedAt time.Time
+	}
+	err := database.Model(model).
+		Select("id", "created_at").
+		Where("created_at NOT LIKE ?", "%+00:00").
+		Find(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed finding creation times to convert: %w", err)
+	}
+
+	for _, row := range rows {
+		// UpdateColumn leaves UpdatedAt alone since the row itself didn't change
+		err := database.Model(model).Where("id = ?", row.ID).UpdateColumn("created_at", row.CreatedAt.UTC()).Error
+		if err != nil {
+			return fmt.Errorf("failed converting creation times to UTC: %w", err)
+		}
+	}
+	if len(rows) > 0 {
+		logger.WithField("rows", len(rows)).Info("converted creation times to UTC")
+	}
+
+	return nil
+}
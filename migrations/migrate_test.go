@@ -0,0 +1,172 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Migrate_DerivesStorageKeysFromFileURLs(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	// quoted like the columns gorm created before file URLs were removed from the models
+	require.NoError(t, database.Exec("ALTER TABLE media ADD COLUMN `file_url` text").Error)
+	require.NoError(t, database.Exec("ALTER TABLE thumbnails ADD COLUMN `file_url` text").Error)
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	legacy, current, broken := uuid.New(), uuid.New(), uuid.New()
+	insert := "INSERT INTO media (id, name, storage_key, file_url, updated_at) VALUES (?, ?, ?, ?, ?)"
+	require.NoError(t, database.Exec(insert, legacy, "legacy", "", "localhost:8080/files/abc.png", updatedAt).Error)
+	require.NoError(t, database.Exec(insert, current, "current", "def.png", "https://example.com/files/def.png", updatedAt).Error)
+	require.NoError(t, database.Exec(insert, broken, "broken", "", "", updatedAt).Error)
+
+	// Act
+	err := Migrate(context.Background(), database)
+
+	// Assert
+	require.NoError(t, err)
+	expectedKeys := map[uuid.UUID]string{legacy: "abc.png", current: "def.png", broken: ""}
+	for id, expectedKey := range expectedKeys {
+		var media domain.Media
+		require.NoError(t, database.First(&media, "id = ?", id).Error)
+		assert.Equal(t, expectedKey, media.StorageKey)
+		assert.True(t, updatedAt.Equal(media.UpdatedAt), "the file of the media didn't change")
+	}
+	assert.False(t, database.Migrator().HasColumn(&domain.Media{}, "file_url"))
+	assert.False(t, database.Migrator().HasColumn(&domain.Thumbnail{}, "file_url"))
+}
+
+func Test_Migrate_MakesAPIKeysWithoutARoleAdmins(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	legacy := &domain.APIKey{Name: "legacy", Hash: "legacy-hash"}
+	viewer := &domain.APIKey{Name: "viewer", Hash: "viewer-hash", Role: "viewer"}
+	require.NoError(t, database.Create([]*domain.APIKey{legacy, viewer}).Error)
+
+	// Act
+	err := Migrate(context.Background(), database)
+
+	// Assert
+	require.NoError(t, err)
+	expectedRoles := map[uuid.UUID]string{legacy.ID: "admin", viewer.ID: "viewer"}
+	for id, expectedRole := range expectedRoles {
+		var key domain.APIKey
+		require.NoError(t, database.First(&key, "id = ?", id).Error)
+		assert.Equal(t, expectedRole, key.Role)
+	}
+}
+
+func Test_Migrate_PrefixesOwnersWithTheirSubject(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	key := &domain.APIKey{Name: "importer", Hash: "importer-hash", Role: "uploader"}
+	require.NoError(t, database.Create(key).Error)
+	tags := []*domain.Tag{
+		{Name: "key", Owned: domain.Owned{OwnerID: key.ID.String()}},
+		{Name: "token", Owned: domain.Owned{OwnerID: "user-1"}},
+		{Name: "converted", Owned: domain.Owned{OwnerID: "jwt:https://id.example.com:user-1"}},
+		{Name: "unowned"},
+	}
+	require.NoError(t, database.Create(tags).Error)
+	media := &domain.Media{Name: "media", Owned: domain.Owned{OwnerID: key.ID.String()}}
+	require.NoError(t, database.Create(media).Error)
+
+	// Act
+	err := Migrate(context.Background(), database)
+
+	// Assert
+	require.NoError(t, err)
+	expectedOwners := map[string]string{
+		"key":       "apikey:" + key.ID.String(),
+		"token":     "user-1",
+		"converted": "jwt:https://id.example.com:user-1",
+		"unowned":   "",
+	}
+	for name, expectedOwner := range expectedOwners {
+		var tag domain.Tag
+		require.NoError(t, database.First(&tag, "name = ?", name).Error)
+		assert.Equal(t, expectedOwner, tag.OwnerID, name)
+	}
+	var migrated domain.Media
+	require.NoError(t, database.First(&migrated, "id = ?", media.ID).Error)
+	assert.Equal(t, "apikey:"+key.ID.String(), migrated.OwnerID)
+}
+
+func Test_Migrate_ConvertsCreationTimesToUTC(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	id := uuid.New()
+	// stored the way times in the local offset of the server were stored before they were converted on creation
+	insert := "INSERT INTO tags (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)"
+	require.NoError(t, database.Exec(insert, id, "tabby", "2024-05-01 14:00:00.5+02:00", "2024-05-01 14:00:00.5+02:00").Error)
+
+	// Act
+	err := Migrate(context.Background(), database)
+
+	// Assert
+	require.NoError(t, err)
+	var createdAt, updatedAt string
+	require.NoError(t, database.Raw("SELECT CAST(created_at AS TEXT), CAST(updated_at AS TEXT) FROM tags WHERE id = ?", id).Row().Scan(&createdAt, &updatedAt))
+	assert.Equal(t, "2024-05-01 12:00:00.5+00:00", createdAt)
+	assert.Equal(t, "2024-05-01 14:00:00.5+02:00", updatedAt, "the tag itself didn't change")
+}
+
+func Test_Migrate_CountsReferencesToStoredFiles(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	thumbnail := func() []*domain.Thumbnail {
+		return []*domain.Thumbnail{{Size: 256, StorageKey: "thumbnails/shared-256.png"}}
+	}
+	media := []*domain.Media{
+		{Name: "first", StorageKey: "shared.png", Thumbnails: thumbnail()},
+		{Name: "second", StorageKey: "shared.png", Thumbnails: thumbnail()},
+		{Name: "counted", StorageKey: "counted.png"},
+		{Name: "legacy"},
+	}
+	require.NoError(t, database.Create(media).Error)
+	// counted since, the count is already up to date
+	require.NoError(t, database.Create(&domain.StoredFile{StorageKey: "counted.png", ReferenceCount: 3}).Error)
+
+	// Act
+	err := Migrate(context.Background(), database)
+
+	// Assert
+	require.NoError(t, err)
+	var files []domain.StoredFile
+	require.NoError(t, database.Order("storage_key").Find(&files).Error)
+	assert.Equal(t, []domain.StoredFile{
+		{StorageKey: "counted.png", ReferenceCount: 3},
+		{StorageKey: "shared.png", ReferenceCount: 2},
+		{StorageKey: "thumbnails/shared-256.png", ReferenceCount: 2},
+	}, files)
+}
+
+func Test_Migrate_CanRunRepeatedly(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, Migrate(context.Background(), database))
+
+	// Act
+	err := Migrate(context.Background(), database)
+
+	// Assert
+	assert.NoError(t, err)
+}
@@ -0,0 +1,72 @@
+// Package phash computes perceptual hashes of images, which stay close for resized or re-encoded copies
+package phash
+
+import (
+	"image"
+	"image/draw"
+	"math/bits"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// BandCount is the number of 16 bit bands a hash is split into
+const BandCount = 4
+
+// Difference computes the difference hash of the image: the image is scaled down to 9x8 grayscale pixels and
+// every bit tells whether a pixel is brighter than its right neighbour
+func Difference(source image.Image) uint64 {
+	// transparent areas are treated as white, like they are in thumbnails
+	scaled := image.NewRGBA(image.Rect(0, 0, 9, 8))
+	draw.Draw(scaled, scaled.Bounds(), image.White, image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), source, source.Bounds(), xdraw.Over, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if luminance(scaled, x, y) > luminance(scaled, x+1, y) {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+func luminance(source *image.RGBA, x int, y int) int {
+	pixel := source.RGBAAt(x, y)
+	return 299*int(pixel.R) + 587*int(pixel.G) + 114*int(pixel.B)
+}
+
+// Distance is the Hamming distance between two hashes, the number of bits that differ
+func Distance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Bands splits the hash into 16 bit bands. Hashes within a distance of d have at least one band within a distance
+// of d / BandCount, so similar hashes are found by looking up the bands near those of a hash
+func Bands(hash uint64) [BandCount]uint16 {
+	var bands [BandCount]uint16
+	for i := range bands {
+		bands[i] = uint16(hash >> (16 * i))
+	}
+	return bands
+}
+
+// Neighbours returns every value within the Hamming distance of the value, including the value itself
+func Neighbours(value uint16, distance int) []uint16 {
+	neighbours := []uint16{value}
+	// flip every combination of up to distance bits, only flipping bits above the last flipped one to avoid repeats
+	var flip func(current uint16, lowest int, remaining int)
+	flip = func(current uint16, lowest int, remaining int) {
+		if remaining == 0 {
+			return
+		}
+		for bit := lowest; bit < 16; bit++ {
+			flipped := current ^ (1 << bit)
+			neighbours = append(neighbours, flipped)
+			flip(flipped, bit+1, remaining-1)
+		}
+	}
+	flip(value, 0, distance)
+	return neighbours
+}
@@ -0,0 +1,121 @@
+package phash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImage draws diagonal bands of varying brightness, scaled to the dimensions
+func testImage(width int, height int) *image.RGBA {
+	source := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := uint8((x*255/width + (y*128/height)*(x%2)) % 256)
+			if x*3 > width && x*3 < 2*width && y*2 > height {
+				value = 255 - value
+			}
+			source.SetRGBA(x, y, color.RGBA{R: value, G: value / 2, B: 255 - value, A: 255})
+		}
+	}
+	return source
+}
+
+func Test_Difference_IsCloseForResizedAndReencodedCopies(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	original := testImage(400, 300)
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, testImage(160, 120), &jpeg.Options{Quality: 40}))
+	copied, err := jpeg.Decode(&encoded)
+	require.NoError(t, err)
+
+	// Act
+	originalHash := Difference(original)
+	copiedHash := Difference(copied)
+
+	// Assert
+	assert.LessOrEqual(t, Distance(originalHash, copiedHash), 6)
+}
+
+func Test_Difference_IsFarForDifferentImages(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	original := testImage(400, 300)
+	mirrored := image.NewRGBA(original.Bounds())
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			mirrored.Set(399-x, y, original.At(x, y))
+		}
+	}
+
+	// Act
+	distance := Distance(Difference(original), Difference(mirrored))
+
+	// Assert
+	assert.GreaterOrEqual(t, distance, 20)
+}
+
+func Test_Difference_TreatsTransparencyAsWhite(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transparent := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	white := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+
+	// Assert
+	assert.Equal(t, Difference(white), Difference(transparent))
+}
+
+func Test_Bands_SplitTheHash(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	bands := Bands(0x0123_4567_89AB_CDEF)
+
+	// Assert
+	assert.Equal(t, [BandCount]uint16{0xCDEF, 0x89AB, 0x4567, 0x0123}, bands)
+}
+
+func Test_Neighbours_ListsEveryValueWithinTheDistance(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		distance      int
+		expectedCount int
+	}{
+		"only the value":  {distance: 0, expectedCount: 1},
+		"single bit":      {distance: 1, expectedCount: 1 + 16},
+		"up to two bits":  {distance: 2, expectedCount: 1 + 16 + 120},
+		"up to four bits": {distance: 4, expectedCount: 1 + 16 + 120 + 560 + 1820},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			neighbours := Neighbours(0xA5A5, testCase.distance)
+
+			// Assert
+			assert.Len(t, neighbours, testCase.expectedCount)
+			seen := map[uint16]bool{}
+			for _, neighbour := range neighbours {
+				assert.False(t, seen[neighbour], "%04x is listed twice", neighbour)
+				seen[neighbour] = true
+				assert.LessOrEqual(t, bits.OnesCount16(neighbour^0xA5A5), testCase.distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Package rendercache keeps rendered images on disk, evicting the least recently used ones once over its budget
+package rendercache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// files that are still being written are prefixed so they are never read or counted
+const temporaryFilePrefix = ".render-"
+
+var ErrInvalidKey = errors.New("invalid cache key")
+
+type entry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// DiskCache stores content in a directory, the total size of the content is kept within MaxSize.
+// The bookkeeping is done in memory, files already in the directory are picked up when the cache is created
+type DiskCache struct {
+	directory string
+	maxSize   int64
+
+	lock    sync.Mutex
+	entries map[string]*entry
+	size    int64
+}
+
+// Key derives a cache key from the parts identifying the content
+func Key(parts ...any) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		// the separator keeps ("ab", "c") and ("a", "bc") apart
+		fmt.Fprintf(hash, "%v\x00", part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// New creates a cache in the directory, content left behind by a previous cache in the same directory is reused
+func New(directory string, maxSize int64) (*DiskCache, error) {
+	if directory == "" {
+		return nil, errors.New("cache directory is required")
+	}
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	cache := &DiskCache{
+		directory: directory,
+		maxSize:   maxSize,
+		entries:   map[string]*entry{},
+	}
+
+	err := filepath.WalkDir(directory, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil || dirEntry.IsDir() {
+			return err
+		}
+		// left behind by an interrupted write
+		if strings.HasPrefix(dirEntry.Name(), temporaryFilePrefix) {
+			return os.Remove(path)
+		}
+
+		// files that aren't cache entries are left alone
+		if expected, err := cache.path(dirEntry.Name()); err != nil || expected != path {
+			return nil
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return err
+		}
+		cache.entries[dirEntry.Name()] = &entry{size: info.Size(), lastUsed: info.ModTime()}
+		cache.size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if err := cache.evict(); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+func (cache *DiskCache) path(key string) (string, error) {
+	if len(key) < 3 || strings.ContainsFunc(key, func(char rune) bool {
+		return !(char >= 'a' && char <= 'z' || char >= '0' && char <= '9')
+	}) {
+		return "", ErrInvalidKey
+	}
+
+	// spread the files over subdirectories to keep directories small
+	return filepath.Join(cache.directory, key[:2], key), nil
+}
+
+// Get returns the cached content, the second return value is false if nothing is cached under the key
+func (cache *DiskCache) Get(key string) ([]byte, bool, error) {
+	path, err := cache.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if entry, ok := cache.entries[key]; ok {
+		entry.lastUsed = time.Now()
+	}
+
+	return content, true, nil
+}
+
+// Put caches the content under the key, evicting the least recently used content when over budget.
+// Content larger than the whole budget is not cached
+func (cache *DiskCache) Put(key string, content []byte) error {
+	path, err := cache.path(key)
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(content))
+	if size > cache.maxSize {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never observe a partially written file
+	file, err := os.CreateTemp(filepath.Dir(path), temporaryFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+
+	if existing, ok := cache.entries[key]; ok {
+		cache.size -= existing.size
+	}
+	cache.entries[key] = &entry{size: size, lastUsed: time.Now()}
+	cache.size += size
+
+	return cache.evict()
+}
+
+// Size returns the total size of the cached content
+func (cache *DiskCache) Size() int64 {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	return cache.size
+}
+
+// evict removes the least recently used content until the cache is within budget, the lock has to be held
+func (cache *DiskCache) evict() error {
+	if cache.size <= cache.maxSize {
+		return nil
+	}
+
+	keys := make([]string, 0, len(cache.entries))
+	for key := range cache.entries {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cache.entries[a].lastUsed.Compare(cache.entries[b].lastUsed)
+	})
+
+	for _, key := range keys {
+		if cache.size <= cache.maxSize {
+			break
+		}
+
+		path, err := cache.path(key)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		cache.size -= cache.entries[key].size
+		delete(cache.entries, key)
+	}
+
+	return nil
+}
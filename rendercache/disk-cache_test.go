@@ -0,0 +1,159 @@
+package rendercache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiskCache_Put_StoresRetrievableContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache, err := New(t.TempDir(), 100)
+	require.NoError(t, err)
+	key := Key("media", 100, "cover")
+
+	// Act
+	putErr := cache.Put(key, []byte("rendered"))
+	content, found, getErr := cache.Get(key)
+
+	// Assert
+	require.NoError(t, putErr)
+	require.NoError(t, getErr)
+	assert.True(t, found)
+	assert.Equal(t, []byte("rendered"), content)
+	assert.Equal(t, int64(8), cache.Size())
+}
+
+func Test_DiskCache_Get_ReportsMissingContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache, err := New(t.TempDir(), 100)
+	require.NoError(t, err)
+
+	// Act
+	_, found, err := cache.Get(Key("missing"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func Test_DiskCache_Put_EvictsLeastRecentlyUsedContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache, err := New(t.TempDir(), 10)
+	require.NoError(t, err)
+	first, second, third := Key(1), Key(2), Key(3)
+	require.NoError(t, cache.Put(first, []byte("1234")))
+	require.NoError(t, cache.Put(second, []byte("1234")))
+	// make the first entry the most recently used one
+	time.Sleep(time.Millisecond)
+	_, _, err = cache.Get(first)
+	require.NoError(t, err)
+
+	// Act
+	err = cache.Put(third, []byte("1234"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), cache.Size())
+	_, found, _ := cache.Get(second)
+	assert.False(t, found, "the least recently used content should be evicted")
+	_, found, _ = cache.Get(first)
+	assert.True(t, found)
+	_, found, _ = cache.Get(third)
+	assert.True(t, found)
+}
+
+func Test_DiskCache_Put_SkipsContentLargerThanTheBudget(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache, err := New(t.TempDir(), 4)
+	require.NoError(t, err)
+	key := Key("large")
+
+	// Act
+	err = cache.Put(key, []byte("12345"))
+
+	// Assert
+	require.NoError(t, err)
+	_, found, _ := cache.Get(key)
+	assert.False(t, found)
+	assert.Equal(t, int64(0), cache.Size())
+}
+
+func Test_DiskCache_Put_ReplacesContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache, err := New(t.TempDir(), 100)
+	require.NoError(t, err)
+	key := Key("replaced")
+	require.NoError(t, cache.Put(key, []byte("old content")))
+
+	// Act
+	err = cache.Put(key, []byte("new"))
+
+	// Assert
+	require.NoError(t, err)
+	content, _, _ := cache.Get(key)
+	assert.Equal(t, []byte("new"), content)
+	assert.Equal(t, int64(3), cache.Size())
+}
+
+func Test_New_ReusesExistingContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	directory := t.TempDir()
+	previous, err := New(directory, 100)
+	require.NoError(t, err)
+	key := Key("kept")
+	require.NoError(t, previous.Put(key, []byte("content")))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "unrelated.txt"), []byte("not cached"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, temporaryFilePrefix+"123"), []byte("partial"), 0o600))
+
+	// Act
+	cache, err := New(directory, 100)
+
+	// Assert
+	require.NoError(t, err)
+	content, found, _ := cache.Get(key)
+	assert.True(t, found)
+	assert.Equal(t, []byte("content"), content)
+	assert.Equal(t, int64(7), cache.Size())
+	assert.NoFileExists(t, filepath.Join(directory, temporaryFilePrefix+"123"))
+	assert.FileExists(t, filepath.Join(directory, "unrelated.txt"))
+}
+
+func Test_DiskCache_RejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache, err := New(t.TempDir(), 100)
+	require.NoError(t, err)
+
+	// Act
+	putErr := cache.Put("../escape", []byte("content"))
+	_, _, getErr := cache.Get("../escape")
+
+	// Assert
+	assert.ErrorIs(t, putErr, ErrInvalidKey)
+	assert.ErrorIs(t, getErr, ErrInvalidKey)
+}
+
+func Test_Key_SeparatesParts(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Key("a", 1), Key("a", 1))
+	assert.NotEqual(t, Key("ab", "c"), Key("a", "bc"))
+}
@@ -0,0 +1,65 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/migrations"
+	"github.com/TheSandyDave/Media-Tags/services"
+)
+
+// CreateAPIKey stores a new API key with the name and role and returns the key, which can't be retrieved afterwards
+func (api *TaggedMediaAPI) CreateAPIKey(ctx context.Context, name string, role auth.Role) (string, error) {
+	apiKeyService, err := api.apiKeyService(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := apiKeyService.Get(ctx, apiKeyService.FilterByNameOption(name))
+	if err != nil {
+		return "", err
+	}
+	if len(existing) > 0 {
+		return "", fmt.Errorf("an API key named %q already exists", name)
+	}
+
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return "", err
+	}
+	if err := apiKeyService.Create(ctx, &domain.APIKey{Name: name, Hash: hash, Role: string(role)}); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey deletes the API key with the name, requests using it are rejected from then on
+func (api *TaggedMediaAPI) RevokeAPIKey(ctx context.Context, name string) error {
+	apiKeyService, err := api.apiKeyService(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing, err := apiKeyService.Get(ctx, apiKeyService.FilterByNameOption(name))
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("no API key named %q exists", name)
+	}
+
+	return apiKeyService.Delete(ctx, existing[0].ID)
+}
+
+// apiKeyService connects to the migrated database without configuring the rest of the API
+func (api *TaggedMediaAPI) apiKeyService(ctx context.Context) (services.IAPIKeyService, error) {
+	api.configureDatabase(ctx)
+	if err := migrations.Migrate(ctx, api.database); err != nil {
+		return nil, err
+	}
+
+	return services.NewAPIKeyService(api.database), nil
+}
@@ -6,10 +6,19 @@ import (
 	"net/http"
 
 	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/config"
 	"github.com/TheSandyDave/Media-Tags/controllers"
 	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/filetype"
 	restgen "github.com/TheSandyDave/Media-Tags/generated/api"
+	"github.com/TheSandyDave/Media-Tags/metadata"
+	"github.com/TheSandyDave/Media-Tags/migrations"
+	"github.com/TheSandyDave/Media-Tags/rendercache"
 	"github.com/TheSandyDave/Media-Tags/services"
+	"github.com/TheSandyDave/Media-Tags/storage"
+	"github.com/TheSandyDave/Media-Tags/thumbnail"
+	"github.com/TheSandyDave/Media-Tags/urlsign"
 	"github.com/TheSandyDave/Media-Tags/utils"
 	"github.com/flowchartsman/swaggerui"
 	"github.com/gin-gonic/gin"
@@ -21,14 +30,60 @@ import (
 // format keeps logs consistent between logrus and gin logs
 var loggerFormatString = "time=\"%s\" level=\"info\" statusCode=\"%v\" path=\"%s\" latency=\"%s\" method=\"%s\" origin=\"%s\" bodySize=\"%d\"\n"
 
+// routeRoles is the role every API route requires, routes missing here are limited to admins. Changing or deleting
+// tags, media and uploads also requires owning them, which the controllers check
+var routeRoles = map[string]auth.Role{
+	"GetTags":          auth.RoleViewer,
+	"GetTagById":       auth.RoleViewer,
+	"GetTagSubtree":    auth.RoleViewer,
+	"GetMedia":         auth.RoleViewer,
+	"GetMediaById":     auth.RoleViewer,
+	"GetSimilarMedia":  auth.RoleViewer,
+	"GetMediaContent":  auth.RoleViewer,
+	"HeadMediaContent": auth.RoleViewer,
+	"RenderMedia":      auth.RoleViewer,
+
+	"CreateTag":         auth.RoleUploader,
+	"UpdateTag":         auth.RoleUploader,
+	"ReplaceTag":        auth.RoleUploader,
+	"DeleteTag":         auth.RoleUploader,
+	"ReplaceTagAliases": auth.RoleUploader,
+	"MergeTag":          auth.RoleUploader,
+	"CreateMedia":       auth.RoleUploader,
+	"UpdateMedia":       auth.RoleUploader,
+	"ReplaceMedia":      auth.RoleUploader,
+	"DeleteMedia":       auth.RoleUploader,
+	"AddMediaTags":      auth.RoleUploader,
+	"ReplaceMediaTags":  auth.RoleUploader,
+	"RemoveMediaTag":    auth.RoleUploader,
+	"CreateUpload":      auth.RoleUploader,
+	"GetUploadOffset":   auth.RoleUploader,
+	"AppendUpload":      auth.RoleUploader,
+	"DeleteUpload":      auth.RoleUploader,
+}
+
+// signedRoutes also accept the signature of a file URL instead of credentials, so browsers can load them directly
+var signedRoutes = map[string]bool{
+	"GetMediaContent":  true,
+	"HeadMediaContent": true,
+}
+
 type TaggedMediaAPI struct {
-	Spec     []byte
-	router   *gin.Engine
-	database *gorm.DB
+	Spec   []byte
+	Config *config.Config
+
+	router    *gin.Engine
+	database  *gorm.DB
+	fileStore storage.FileStore
 
 	// Controllers
-	tagController   controllers.TagController
-	mediaController controllers.MediaController
+	tagController     controllers.TagController
+	mediaController   controllers.MediaController
+	fileController    controllers.FileController
+	renderController  controllers.RenderController
+	contentController controllers.ContentController
+	uploadController  controllers.UploadController
+	authenticator     controllers.Authenticator
 }
 
 func (api *TaggedMediaAPI) Configure(ctx context.Context) *gin.Engine {
@@ -36,6 +91,10 @@ func (api *TaggedMediaAPI) Configure(ctx context.Context) *gin.Engine {
 	logger.Info("configuring the API")
 
 	api.router = gin.New()
+	// X-Forwarded-For is only used for the address of the client when the request came from a trusted proxy
+	if err := api.router.SetTrustedProxies(api.Config.Server.TrustedProxies); err != nil {
+		logger.WithError(err).Fatal("failed configuring trusted proxies")
+	}
 
 	api.router.Use(
 		gin.LoggerWithConfig(gin.LoggerConfig{
@@ -54,12 +113,13 @@ func (api *TaggedMediaAPI) Configure(ctx context.Context) *gin.Engine {
 	)
 
 	api.configureDatabase(ctx)
+	api.configureStorage(ctx)
 	api.configureErrorHandlers(ctx)
-	api.configureControllers()
+	api.configureControllers(ctx)
 	api.configureRoutes()
 
-	if err := api.database.AutoMigrate(domain.Models...); err != nil {
-		logger.Fatal(err)
+	if err := migrations.Migrate(ctx, api.database); err != nil {
+		logger.WithError(err).Fatal("failed migrating the database")
 	}
 	return api.router
 }
@@ -68,7 +128,10 @@ func (api *TaggedMediaAPI) configureDatabase(ctx context.Context) {
 	logger := utils.NewLogger(ctx)
 
 	if api.database == nil {
-		database, err := gorm.Open(sqlite.Open("db"))
+		database, err := gorm.Open(sqlite.Open(api.Config.Database.DSN), &gorm.Config{
+			// turns violated unique constraints into gorm.ErrDuplicatedKey
+			TranslateError: true,
+		})
 		if err != nil {
 			logger.WithError(err).Fatal("failed to configure database")
 		}
@@ -77,14 +140,43 @@ func (api *TaggedMediaAPI) configureDatabase(ctx context.Context) {
 	}
 }
 
+func (api *TaggedMediaAPI) configureStorage(ctx context.Context) {
+	logger := utils.NewLogger(ctx)
+
+	if api.fileStore == nil {
+		fileStore, err := storage.New(ctx, api.Config.Storage)
+		if err != nil {
+			logger.WithError(err).Fatal("failed to configure file storage")
+		}
+
+		api.fileStore = fileStore
+	}
+}
+
 func (api *TaggedMediaAPI) configureErrorHandlers(ctx context.Context) {
 	errorRegistry := ginerr.NewErrorRegistry()
 
 	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleInvalidTagsError)
 	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleInvalidUUIDError)
 	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleRecordNotFoundError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleFileNotFoundError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleInvalidFileSignatureError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleUnauthorizedError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleForbiddenError)
 	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleInvalidFileTypeError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleFileTooLargeError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleRequestTooLargeError)
 	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleRequiredValueMissingError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleInvalidParameterError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleTagCycleError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleTagNameConflictError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleInvalidTagMergeError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleNotRenderableError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleDuplicateMediaError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleUploadConflictError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleUploadExpiredError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleUnsupportedTusVersionError)
+	ginerr.RegisterErrorHandlerOn(errorRegistry, apierrors.HandleUnsupportedContentTypeError)
 
 	errorRegistry.RegisterDefaultHandler(apierrors.DefaultErrorHandler)
 
@@ -100,20 +192,124 @@ func (api *TaggedMediaAPI) configureErrorHandlers(ctx context.Context) {
 	})
 }
 
-func (api *TaggedMediaAPI) configureControllers() {
+func (api *TaggedMediaAPI) configureControllers(ctx context.Context) {
+	logger := utils.NewLogger(ctx)
+
 	var (
-		tagService   = services.NewTagService(api.database)
-		mediaService = services.NewMediaService(api.database)
+		tagService    = services.NewTagService(api.database)
+		mediaService  = services.NewMediaService(api.database)
+		uploadService = services.NewUploadService(api.database)
+		apiKeyService = services.NewAPIKeyService(api.database)
+		fileService   = services.NewStoredFileService(api.database)
 	)
 
+	api.authenticator = controllers.Authenticator{
+		APIKeyService: apiKeyService,
+	}
+	if !api.Config.Auth.JWT.Empty() {
+		tokens, err := auth.NewTokenVerifier(api.Config.Auth.JWT)
+		if err != nil {
+			logger.WithError(err).Fatal("failed loading the token verification keys")
+		}
+		api.authenticator.Tokens = tokens
+	}
+	if !api.Config.Auth.Enabled {
+		logger.Warn("authentication is disabled, anyone who can reach the API can use it")
+	}
+
 	api.tagController = controllers.TagController{
 		TagService: tagService,
 	}
 
+	images := thumbnail.Generator{
+		Sizes:           api.Config.Thumbnails.Sizes,
+		Quality:         api.Config.Thumbnails.Quality,
+		MaxSourcePixels: api.Config.Thumbnails.MaxSourcePixels,
+		WebP:            api.Config.Thumbnails.WebP,
+	}
+
+	uploadRules := map[domain.MediaKind]controllers.UploadRules{}
+	for kind, kindConfig := range api.Config.Uploads.Kinds() {
+		if len(kindConfig.AllowedTypes) == 0 {
+			continue
+		}
+		uploadRules[kind] = controllers.UploadRules{
+			MaxFileSize: kindConfig.MaxSize,
+			FileTypes: filetype.Allowlist{
+				MIMETypes:  kindConfig.AllowedTypes,
+				Extensions: kindConfig.AllowedExtensions,
+			},
+		}
+	}
+
+	// the configuration is validated already, the policy always parses
+	privacy, _ := metadata.ParsePolicy(api.Config.Privacy.Strip)
+
+	signingKeys := api.Config.Files.SigningKeys
+	if len(signingKeys) == 0 {
+		key, err := urlsign.NewRandomKey()
+		if err != nil {
+			logger.WithError(err).Fatal("failed generating a file URL signing key")
+		}
+		logger.Warn("no file URL signing keys configured, file URLs stop working when the API restarts")
+		signingKeys = []string{key}
+	}
+	urlSigner, err := urlsign.New(signingKeys, api.Config.Files.URLExpiration)
+	if err != nil {
+		logger.WithError(err).Fatal("failed configuring file URL signing")
+	}
+	// the configuration is validated already, the proxies always parse
+	trustedProxies, _ := api.Config.Server.TrustedProxyPrefixes()
+	fileURLs := &controllers.FileURLs{
+		BaseURL:        api.Config.Server.PublicBaseURL,
+		TrustedProxies: trustedProxies,
+		Signer:         urlSigner,
+	}
+
 	api.mediaController = controllers.MediaController{
+		MediaService:      mediaService,
+		TagService:        tagService,
+		StoredFileService: fileService,
+		FileStore:         api.fileStore,
+		MaxFileSize:       api.Config.Uploads.MaxSize,
+		MaxRequestSize:    api.Config.Uploads.MaxRequestSize,
+		Kinds:             uploadRules,
+		Thumbnails:        images,
+		Privacy:           privacy,
+		RecordStripped:    api.Config.Privacy.RecordStripped,
+		Duplicates:        controllers.DuplicatePolicy(api.Config.Uploads.Duplicates),
+		FileURLs:          fileURLs,
+	}
+
+	api.uploadController = controllers.UploadController{
+		UploadService: uploadService,
+		FileStore:     api.fileStore,
+		Media:         &api.mediaController,
+		Expiration:    api.Config.Uploads.Resumable.Expiration,
+	}
+	go api.uploadController.RemoveExpiredEvery(ctx, api.Config.Uploads.Resumable.CleanupInterval)
+
+	api.contentController = controllers.ContentController{
 		MediaService: mediaService,
-		TagService:   tagService,
-		IsTest:       false,
+		FileStore:    api.fileStore,
+		URLSigner:    urlSigner,
+	}
+
+	api.fileController = controllers.FileController{
+		FileStore: api.fileStore,
+		URLSigner: urlSigner,
+	}
+
+	renderCache, err := rendercache.New(api.Config.Render.CacheDirectory, api.Config.Render.CacheSize)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to configure the render cache")
+	}
+	api.renderController = controllers.RenderController{
+		MediaService: mediaService,
+		FileStore:    api.fileStore,
+		Renderer:     images,
+		Cache:        renderCache,
+		MaxDimension: api.Config.Render.MaxDimension,
 	}
 
 }
@@ -128,21 +324,64 @@ func (api *TaggedMediaAPI) configureRoutes() {
 		api.router.HandleContext(c)
 	})
 
-	// Media file storage
-	api.router.StaticFS("/files", gin.Dir("static", false))
+	// Media file storage, files are only served through signed URLs
+	api.router.GET("/files/*key", api.fileController.GetFile)
 
 	handlers := restgen.Handlers{
 		// Tags
-		CreateTag:  api.tagController.CreateTag,
-		GetTags:    api.tagController.GetTags,
-		GetTagById: api.tagController.GetTagWithId,
+		CreateTag:         api.tagController.CreateTag,
+		GetTags:           api.tagController.GetTags,
+		GetTagById:        api.tagController.GetTagWithId,
+		GetTagSubtree:     api.tagController.GetTagSubtree,
+		UpdateTag:         api.tagController.UpdateTag,
+		ReplaceTag:        api.tagController.ReplaceTag,
+		DeleteTag:         api.tagController.DeleteTag,
+		ReplaceTagAliases: api.tagController.ReplaceTagAliases,
+		MergeTag:          api.tagController.MergeTag,
 
 		// Media
 
 		CreateMedia:  api.mediaController.CreateMedia,
 		GetMedia:     api.mediaController.GetMedia,
 		GetMediaById: api.mediaController.GetMediaWithId,
+		UpdateMedia:  api.mediaController.UpdateMedia,
+		ReplaceMedia: api.mediaController.ReplaceMedia,
+		DeleteMedia:  api.mediaController.DeleteMedia,
+
+		AddMediaTags:     api.mediaController.AddMediaTags,
+		ReplaceMediaTags: api.mediaController.ReplaceMediaTags,
+		RemoveMediaTag:   api.mediaController.RemoveMediaTag,
+
+		RenderMedia:      api.renderController.RenderMedia,
+		GetSimilarMedia:  api.mediaController.GetSimilarMedia,
+		GetMediaContent:  api.contentController.GetMediaContent,
+		HeadMediaContent: api.contentController.GetMediaContent,
+
+		// Resumable uploads
+		GetUploadOptions: api.uploadController.GetUploadOptions,
+		CreateUpload:     api.uploadController.CreateUpload,
+		GetUploadOffset:  api.uploadController.GetUploadOffset,
+		AppendUpload:     api.uploadController.AppendUpload,
+		DeleteUpload:     api.uploadController.DeleteUpload,
 	}
 	routes := restgen.GetRoutes(handlers)
+	if api.Config.Auth.Enabled {
+		for i, route := range routes {
+			// tus clients discover the supported extensions before authenticating, browsers send preflight
+			// requests without credentials
+			if route.Method == http.MethodOptions {
+				continue
+			}
+			role, ok := routeRoles[route.Name]
+			if !ok {
+				role = auth.RoleAdmin
+			}
+			if signedRoutes[route.Name] {
+				routes[i].HandlerFunc = api.authenticator.RequireUnlessSigned(role, route.HandlerFunc)
+				continue
+			}
+			routes[i].HandlerFunc = api.authenticator.Require(role, route.HandlerFunc)
+		}
+	}
 	restgen.Decorate(api.router, routes)
 }
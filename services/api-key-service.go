@@ -0,0 +1,43 @@
+package services
+
+import (
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// compile time check for the struct implementing the interface
+var _ IAPIKeyService = (*apiKeyService)(nil)
+
+//go:generate go run go.uber.org/mock/mockgen -source $GOFILE -typed -destination ../generated/mock/services/mock_$GOFILE IAPIKeyService
+type IAPIKeyService interface {
+	IBaseService[domain.APIKey]
+	FilterByHashOption(hash string) Option[domain.APIKey]
+	FilterByNameOption(name string) Option[domain.APIKey]
+}
+
+type apiKeyService struct {
+	baseService[domain.APIKey]
+}
+
+func NewAPIKeyService(db *gorm.DB) IAPIKeyService {
+	return &apiKeyService{
+		baseService: baseService[domain.APIKey]{
+			Database: db,
+		},
+	}
+}
+
+// FilterByHashOption only keeps the key with the hash
+func (service *apiKeyService) FilterByHashOption(hash string) Option[domain.APIKey] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "hash"}, Value: hash})
+	}
+}
+
+// FilterByNameOption only keeps the key with the name
+func (service *apiKeyService) FilterByNameOption(name string) Option[domain.APIKey] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "name"}, Value: name})
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_APIKeyService_FilterOptions_FilterTests(t *testing.T) {
+	// Arrange
+	keys := []*domain.APIKey{
+		{Name: "importer", Hash: "importer-hash"},
+		{Name: "backup", Hash: "backup-hash"},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&keys).Error)
+	service := NewAPIKeyService(database)
+
+	// Act
+	byHash, hashErr := service.Get(context.Background(), service.FilterByHashOption("backup-hash"))
+	byName, nameErr := service.Get(context.Background(), service.FilterByNameOption("importer"))
+
+	// Assert
+	require.NoError(t, hashErr)
+	require.NoError(t, nameErr)
+	if assert.Len(t, byHash, 1) {
+		assert.Equal(t, "backup", byHash[0].Name)
+	}
+	if assert.Len(t, byName, 1) {
+		assert.Equal(t, "importer-hash", byName[0].Hash)
+	}
+}
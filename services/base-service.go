@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 
 	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
@@ -24,10 +25,13 @@ type Option[T domain.IDbObject] func(*gorm.DB) *gorm.DB
 
 type IBaseService[T domain.IDbObject] interface {
 	Get(ctx context.Context, options ...Option[T]) ([]*T, error)
+	GetPage(ctx context.Context, pagination Pagination, options ...Option[T]) (*domain.Page[T], error)
 	GetWithID(ctx context.Context, id uuid.UUID, options ...Option[T]) (*T, error)
+	GetOwnedWithID(ctx context.Context, id uuid.UUID, options ...Option[T]) (*T, error)
 	GetWithIDs(ctx context.Context, ids []uuid.UUID, options ...Option[T]) ([]*T, error)
 	Create(ctx context.Context, item ...*T) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Update(ctx context.Context, item *T) error
+	Delete(ctx context.Context, id uuid.UUID, options ...Option[T]) error
 }
 
 func (service *baseService[T]) Get(ctx context.Context, options ...Option[T]) ([]*T, error) {
@@ -51,10 +55,79 @@ func (service *baseService[T]) Get(ctx context.Context, options ...Option[T]) ([
 	return result, nil
 }
 
+// GetPage retrieves a single page of results using keyset pagination, the options are applied as filters
+func (service *baseService[T]) GetPage(ctx context.Context, pagination Pagination, options ...Option[T]) (*domain.Page[T], error) {
+	logger := utils.NewLogger(ctx).WithField("model", reflect.TypeFor[T]().String())
+
+	filtered := service.Database.WithContext(ctx).Model(new(T))
+	for _, option := range options {
+		filtered = option(filtered)
+	}
+	// allow the filtered query to be reused for both counting and retrieving
+	filtered = filtered.Session(&gorm.Session{})
+
+	page := &domain.Page[T]{}
+	if pagination.Cursor == nil {
+		var total int64
+		if err := filtered.Count(&total).Error; err != nil {
+			logger.WithError(err).Error("failed to count")
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	dbQuery := SortOption[T](pagination.Sort, pagination.Descending)(filtered.Preload(clause.Associations))
+	if pagination.Cursor != nil {
+		dbQuery = AfterCursorOption[T](pagination.Sort, pagination.Descending, *pagination.Cursor)(dbQuery)
+	}
+	// retrieve one extra item to know whether there is a next page
+	dbQuery = LimitOption[T](pagination.Limit + 1)(dbQuery)
+
+	var result []*T
+	if err := dbQuery.Find(&result).Error; err != nil {
+		logger.WithError(err).Error("failed to get page")
+		return nil, err
+	}
+
+	if len(result) > pagination.Limit {
+		result = result[:pagination.Limit]
+
+		cursor, err := service.cursorFor(ctx, result[len(result)-1], pagination)
+		if err != nil {
+			logger.WithError(err).Error("failed to create cursor")
+			return nil, err
+		}
+		page.NextCursor = cursor.Encode()
+	}
+	page.Items = result
+
+	return page, nil
+}
+
+func (service *baseService[T]) cursorFor(ctx context.Context, item *T, pagination Pagination) (*Cursor, error) {
+	statement := &gorm.Statement{DB: service.Database}
+	if err := statement.Parse(item); err != nil {
+		return nil, err
+	}
+
+	field := statement.Schema.LookUpField(pagination.Sort.Column)
+	if field == nil {
+		return nil, fmt.Errorf("unknown sort column %s", pagination.Sort.Column)
+	}
+	value, _ := field.ValueOf(ctx, reflect.ValueOf(item).Elem())
+
+	return &Cursor{
+		Sort:       pagination.Sort.Name,
+		Descending: pagination.Descending,
+		Value:      pagination.Sort.format(value),
+		ID:         (*item).GetID(),
+	}, nil
+}
+
 func (service *baseService[T]) GetWithID(ctx context.Context, id uuid.UUID, options ...Option[T]) (*T, error) {
 	logger := utils.NewLogger(ctx)
 
-	dbQuery := service.Database.WithContext(ctx)
+	dbQuery := service.Database.WithContext(ctx).Preload(clause.Associations)
 	for _, option := range options {
 		dbQuery = option(dbQuery)
 	}
@@ -75,6 +148,21 @@ func (service *baseService[T]) GetWithID(ctx context.Context, id uuid.UUID, opti
 	return &result, nil
 }
 
+// GetOwnedWithID retrieves the item when the principal of the context may change it, see OwnedByOption. Items of
+// others fail with a ForbiddenError rather than as not found, since everyone can read them anyway
+func (service *baseService[T]) GetOwnedWithID(ctx context.Context, id uuid.UUID, options ...Option[T]) (*T, error) {
+	item, err := service.GetWithID(ctx, id, append(options, OwnedByOption[T](ctx))...)
+	var notFound *apierrors.RecordNotFoundError
+	if !errors.As(err, &notFound) {
+		return item, err
+	}
+
+	if _, existsErr := service.GetWithID(ctx, id, options...); existsErr != nil {
+		return nil, err
+	}
+	return nil, apierrors.NewForbiddenError(fmt.Sprintf("only the owner of {%s} or an admin can change it", id))
+}
+
 func (service *baseService[T]) GetWithIDs(ctx context.Context, ids []uuid.UUID, options ...Option[T]) ([]*T, error) {
 	logger := utils.NewLogger(ctx)
 
@@ -109,9 +197,14 @@ func (service *baseService[T]) GetWithIDs(ctx context.Context, ids []uuid.UUID,
 }
 
 func (service *baseService[T]) Create(ctx context.Context, item ...*T) error {
-	logger := utils.NewLogger(ctx)
+	return service.createWithin(service.Database.WithContext(ctx), item...)
+}
 
-	if err := service.Database.WithContext(ctx).Create(item).Error; err != nil {
+// createWithin creates the items using the given database handle, allowing it to be part of a transaction
+func (service *baseService[T]) createWithin(database *gorm.DB, item ...*T) error {
+	logger := utils.NewLogger(database.Statement.Context)
+
+	if err := database.Create(item).Error; err != nil {
 		logger.WithError(err).Error("failed creating")
 		return err
 	}
@@ -119,12 +212,46 @@ func (service *baseService[T]) Create(ctx context.Context, item ...*T) error {
 	return nil
 }
 
-func (service *baseService[T]) Delete(ctx context.Context, id uuid.UUID) error {
-	logger := utils.NewLogger(ctx)
-	if err := service.Database.WithContext(ctx).Delete(new(T), id).Error; err != nil {
+// Update saves all fields of the item, associations are left untouched
+func (service *baseService[T]) Update(ctx context.Context, item *T) error {
+	return service.updateWithin(service.Database.WithContext(ctx), item)
+}
+
+// updateWithin updates the item using the given database handle, allowing it to be part of a transaction
+func (service *baseService[T]) updateWithin(database *gorm.DB, item *T) error {
+	logger := utils.NewLogger(database.Statement.Context)
+
+	if err := database.Omit(clause.Associations).Save(item).Error; err != nil {
+		logger.WithError(err).Error("failed updating")
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the item, the options limit the items that can be removed within the same statement. Items they
+// leave out fail as not found
+func (service *baseService[T]) Delete(ctx context.Context, id uuid.UUID, options ...Option[T]) error {
+	return service.deleteWithin(service.Database.WithContext(ctx), id, options...)
+}
+
+// deleteWithin deletes the item using the given database handle, allowing it to be part of a transaction
+func (service *baseService[T]) deleteWithin(database *gorm.DB, id uuid.UUID, options ...Option[T]) error {
+	logger := utils.NewLogger(database.Statement.Context)
+
+	dbQuery := database
+	for _, option := range options {
+		dbQuery = option(dbQuery)
+	}
+	result := dbQuery.Delete(new(T), id)
+	if err := result.Error; err != nil {
 		logger.WithError(err).Error("failed deleting")
 		return err
 	}
 
+	if result.RowsAffected == 0 {
+		return apierrors.NewNotFoundError(id)
+	}
+
 	return nil
 }
@@ -200,3 +200,44 @@ func TestBaseService_Get_AppliesFiltersCorrectly(t *testing.T) {
 	assert.Equal(t, 1, len(res))
 	assert.Equal(t, nameToretrieve, res[0].Name)
 }
+
+func TestBaseService_Delete_failsIfRecordDoesNotExist(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	database := utils.NewInMemoryDatabase(t, TestIDbModel{})
+	service := baseService[TestIDbModel]{
+		Database: database,
+	}
+
+	// Act
+	err := service.Delete(context.Background(), uuid.New())
+
+	// Assert
+	assert.IsType(t, &apierrors.RecordNotFoundError{}, err)
+}
+
+func TestBaseService_Update_savesChangedFields(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	database := utils.NewInMemoryDatabase(t, TestIDbModel{})
+	objToUpdate := TestIDbModel{
+		BaseObject: domain.BaseObject{
+			ID: uuid.New(),
+		},
+		Name: "before",
+	}
+	service := baseService[TestIDbModel]{
+		Database: database,
+	}
+	require.NoError(t, database.Create(&objToUpdate).Error)
+
+	// Act
+	objToUpdate.Name = "after"
+	err := service.Update(context.Background(), &objToUpdate)
+
+	// Assert
+	assert.NoError(t, err)
+	result := TestIDbModel{}
+	database.First(&result, objToUpdate.ID)
+	assert.Equal(t, "after", result.Name)
+}
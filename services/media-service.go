@@ -1,8 +1,20 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
 	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/phash"
+	"github.com/TheSandyDave/Media-Tags/tagquery"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // compile time check for the struct implementing the interface
@@ -13,6 +25,20 @@ var _ IMediaService = (*mediaService)(nil)
 type IMediaService interface {
 	IBaseService[domain.Media]
 	FilterByTagOption(tag string) Option[domain.Media]
+	FilterByAllTagsOption(tags []string, scope TagScope) Option[domain.Media]
+	FilterByAnyTagOption(tags []string, scope TagScope) Option[domain.Media]
+	ExcludeTagsOption(tags []string, scope TagScope) Option[domain.Media]
+	FilterByTagQueryOption(query tagquery.Node, scope TagScope) Option[domain.Media]
+	FilterByKindOption(kind domain.MediaKind) Option[domain.Media]
+	FilterByTakenAfterOption(after time.Time) Option[domain.Media]
+	FilterByTakenBeforeOption(before time.Time) Option[domain.Media]
+	FilterByCameraOption(camera string) Option[domain.Media]
+	FilterByContentHashOption(hash string) Option[domain.Media]
+	GetSimilar(ctx context.Context, hash uint64, maxDistance int, limit int, options ...Option[domain.Media]) ([]*domain.SimilarMedia, error)
+	AddTags(ctx context.Context, media *domain.Media, tags []*domain.Tag) error
+	RemoveTag(ctx context.Context, media *domain.Media, tagID uuid.UUID) error
+	ReplaceTags(ctx context.Context, media *domain.Media, tags []*domain.Tag) error
+	DeleteWithFiles(ctx context.Context, id uuid.UUID, remove RemoveFiles, options ...Option[domain.Media]) error
 }
 
 type mediaService struct {
@@ -27,8 +53,333 @@ func NewMediaService(db *gorm.DB) IMediaService {
 	}
 }
 
+// TagScope decides which tags a tag name in a filter matches
+type TagScope int
+
+const (
+	// ExactTag only matches the tag with the name
+	ExactTag TagScope = iota
+	// TagWithDescendants matches the tag with the name and every tag below it in the hierarchy
+	TagWithDescendants
+)
+
 func (service *mediaService) FilterByTagOption(tag string) Option[domain.Media] {
+	return service.FilterByAllTagsOption([]string{tag}, ExactTag)
+}
+
+// FilterByAllTagsOption only keeps media having every one of the tags
+func (service *mediaService) FilterByAllTagsOption(tags []string, scope TagScope) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, tag := range tags {
+			db = db.Where(hasAnyTag([]string{tag}, scope))
+		}
+		return db
+	}
+}
+
+// FilterByAnyTagOption only keeps media having at least one of the tags
+func (service *mediaService) FilterByAnyTagOption(tags []string, scope TagScope) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(hasAnyTag(tags, scope))
+	}
+}
+
+// ExcludeTagsOption removes media having any of the tags
+func (service *mediaService) ExcludeTagsOption(tags []string, scope TagScope) Option[domain.Media] {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Joins("INNER JOIN media_tags  as mt on media.id = mt.media_id").Joins("INNER JOIN tags on mt.tag_id = tags.id").Where("tags.name = ?", tag)
+		return db.Where(clause.Expr{SQL: "NOT ?", Vars: []any{hasAnyTag(tags, scope)}})
+	}
+}
+
+// hasAnyTag is a condition on the media table checking the media_tags of the media for one of the tags
+func hasAnyTag(tags []string, scope TagScope) clause.Expression {
+	return clause.Expr{
+		SQL:  "EXISTS (SELECT 1 FROM media_tags AS filter_mt WHERE filter_mt.media_id = media.id AND filter_mt.tag_id IN (?))",
+		Vars: []any{tagIDs(tags, scope)},
+	}
+}
+
+// FilterByTagQueryOption only keeps media matching the boolean tag query
+func (service *mediaService) FilterByTagQueryOption(query tagquery.Node, scope TagScope) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(compileTagQuery(query, scope))
+	}
+}
+
+// FilterByKindOption only keeps media of the kind
+func (service *mediaService) FilterByKindOption(kind domain.MediaKind) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "kind"}, Value: kind})
+	}
+}
+
+// FilterByContentHashOption only keeps media whose stored file has the SHA-256 hash
+func (service *mediaService) FilterByContentHashOption(hash string) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "content_hash"}, Value: hash})
+	}
+}
+
+// FilterByTakenAfterOption only keeps media captured after the time, media without a capture time are removed. Like
+// all EXIF filters it never matches media whose EXIF was stripped, see hasExif
+func (service *mediaService) FilterByTakenAfterOption(after time.Time) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		// capture times are stored in UTC, the comparison only works between times in the same zone
+		return db.Where(hasExif("metadata.taken_at > ?", after.UTC()))
+	}
+}
+
+// FilterByTakenBeforeOption only keeps media captured before the time, media without a capture time are removed
+func (service *mediaService) FilterByTakenBeforeOption(before time.Time) Option[domain.Media] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(hasExif("metadata.taken_at < ?", before.UTC()))
+	}
+}
+
+// FilterByCameraOption only keeps media taken with a camera whose make or model contains the text, ignoring case
+func (service *mediaService) FilterByCameraOption(camera string) Option[domain.Media] {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	pattern := "%" + strings.ToLower(replacer.Replace(camera)) + "%"
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(hasExif(
+			`LOWER(COALESCE(metadata.camera_make, '') || ' ' || COALESCE(metadata.camera_model, '')) LIKE ? ESCAPE '\'`,
+			pattern,
+		))
+	}
+}
+
+// hasMetadata is a condition on the media table checking the metadata of the media
+func hasMetadata(condition string, vars ...any) clause.Expression {
+	return clause.Expr{
+		SQL:  "EXISTS (SELECT 1 FROM media_metadata AS metadata WHERE metadata.media_id = media.id AND " + condition + ")",
+		Vars: vars,
+	}
+}
+
+// hasExif is a condition on the EXIF fields of the metadata of the media. Fields stripped from the stored file may
+// still be recorded but are never matched, otherwise repeated filtering would reveal them
+func hasExif(condition string, vars ...any) clause.Expression {
+	return hasMetadata("metadata.exif_stripped IS NOT TRUE AND "+condition, vars...)
+}
+
+// compileTagQuery turns the query into a condition on the media table, every tag becomes a hasAnyTag subquery
+func compileTagQuery(node tagquery.Node, scope TagScope) clause.Expression {
+	switch node := node.(type) {
+	case *tagquery.And:
+		return clause.Expr{SQL: "(? AND ?)", Vars: []any{compileTagQuery(node.Left, scope), compileTagQuery(node.Right, scope)}}
+	case *tagquery.Or:
+		return clause.Expr{SQL: "(? OR ?)", Vars: []any{compileTagQuery(node.Left, scope), compileTagQuery(node.Right, scope)}}
+	case *tagquery.Not:
+		return clause.Expr{SQL: "NOT ?", Vars: []any{compileTagQuery(node.Operand, scope)}}
+	case *tagquery.Tag:
+		return hasAnyTag([]string{node.Name}, scope)
+	}
+
+	panic(fmt.Sprintf("unknown tag query node %T", node))
+}
+
+// Create stores the media along with the bands of their perceptual hashes
+func (service *mediaService) Create(ctx context.Context, media ...*domain.Media) error {
+	return service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := service.createWithin(tx, media...); err != nil {
+			return err
+		}
+
+		var bands []*domain.PerceptualHashBand
+		for _, item := range media {
+			if item.PerceptualHash == nil {
+				continue
+			}
+			for band, value := range phash.Bands(uint64(*item.PerceptualHash)) {
+				bands = append(bands, &domain.PerceptualHashBand{MediaID: item.ID, Band: band, Value: int(value)})
+			}
+		}
+		if len(bands) == 0 {
+			return nil
+		}
+
+		return tx.Create(&bands).Error
+	})
+}
+
+// GetSimilar finds at most limit media whose perceptual hash is within the distance of the hash, closest first.
+// Only media with a band near one of the bands of the hash are compared, any media within the distance has such a
+// band. The candidates are compared on their hash alone, only the closest ones are loaded in full
+func (service *mediaService) GetSimilar(ctx context.Context, hash uint64, maxDistance int, limit int, options ...Option[domain.Media]) ([]*domain.SimilarMedia, error) {
+	logger := utils.NewLogger(ctx)
+
+	var nearBands []clause.Expression
+	for band, value := range phash.Bands(hash) {
+		neighbours := phash.Neighbours(value, maxDistance/phash.BandCount)
+		values := make([]any, len(neighbours))
+		for i, neighbour := range neighbours {
+			values[i] = int(neighbour)
+		}
+		nearBands = append(nearBands, clause.And(
+			clause.Eq{Column: "band", Value: band},
+			clause.IN{Column: "value", Values: values},
+		))
+	}
+	database := service.Database.WithContext(ctx)
+	candidateIDs := database.Model(&domain.PerceptualHashBand{}).Select("media_id").Where(clause.Or(nearBands...))
+
+	dbQuery := database.Model(&domain.Media{}).Select("id", "name", "perceptual_hash").Where("media.id IN (?)", candidateIDs)
+	for _, option := range options {
+		dbQuery = option(dbQuery)
+	}
+
+	var candidates []*domain.Media
+	if err := dbQuery.Find(&candidates).Error; err != nil {
+		logger.WithError(err).Error("failed to get similar media")
+		return nil, err
+	}
+
+	var similar []*domain.SimilarMedia
+	for _, candidate := range candidates {
+		if candidate.PerceptualHash == nil {
+			continue
+		}
+		if distance := phash.Distance(hash, uint64(*candidate.PerceptualHash)); distance <= maxDistance {
+			similar = append(similar, &domain.SimilarMedia{Media: candidate, Distance: distance})
+		}
+	}
+	slices.SortStableFunc(similar, func(a *domain.SimilarMedia, b *domain.SimilarMedia) int {
+		if a.Distance != b.Distance {
+			return a.Distance - b.Distance
+		}
+		return strings.Compare(a.Media.Name, b.Media.Name)
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	if len(similar) == 0 {
+		return similar, nil
+	}
+
+	ids := make([]uuid.UUID, len(similar))
+	for i, item := range similar {
+		ids[i] = item.Media.ID
+	}
+	var media []*domain.Media
+	if err := database.Preload(clause.Associations).Where("media.id IN ?", ids).Find(&media).Error; err != nil {
+		logger.WithError(err).Error("failed to get similar media")
+		return nil, err
+	}
+	loaded := make(map[uuid.UUID]*domain.Media, len(media))
+	for _, item := range media {
+		loaded[item.ID] = item
 	}
+	// media deleted in the meantime is left out
+	return slices.DeleteFunc(similar, func(item *domain.SimilarMedia) bool {
+		item.Media = loaded[item.Media.ID]
+		return item.Media == nil
+	}), nil
+}
+
+// Delete removes the media along with its tag associations, thumbnails and metadata. The stored files are neither
+// removed nor released, use DeleteWithFiles to remove them once nothing refers to them anymore
+func (service *mediaService) Delete(ctx context.Context, id uuid.UUID, options ...Option[domain.Media]) error {
+	return service.DeleteWithFiles(ctx, id, nil, options...)
+}
+
+// DeleteWithFiles deletes the media and releases its stored files in the same transaction, so no upload can start
+// sharing the files in between. remove is called with the files nothing refers to anymore. Without remove the
+// references are kept, so the files stay stored and counted rather than orphaned.
+// Nothing changes when the options leave the media out
+func (service *mediaService) DeleteWithFiles(ctx context.Context, id uuid.UUID, remove RemoveFiles, options ...Option[domain.Media]) error {
+	return service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var keys, thumbnailKeys []string
+		if err := tx.Model(&domain.Media{}).Where("id = ? AND storage_key <> ''", id).Pluck("storage_key", &keys).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Thumbnail{}).Where("media_id = ?", id).Pluck("storage_key", &thumbnailKeys).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM media_tags WHERE media_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM thumbnails WHERE media_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM media_metadata WHERE media_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM perceptual_hash_bands WHERE media_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := service.deleteWithin(tx, id, options...); err != nil {
+			return err
+		}
+
+		return releaseWithin(tx, remove, append(keys, thumbnailKeys...)...)
+	})
+}
+
+// tagAssociation manipulates the media_tags rows of the media, the tags themselves are never modified
+func (service *mediaService) tagAssociation(ctx context.Context, media *domain.Media) *gorm.Association {
+	return service.Database.WithContext(ctx).Model(media).Omit("Tags.*").Association("Tags")
+}
+
+// AddTags attaches the tags to the media, tags that are already attached are ignored
+func (service *mediaService) AddTags(ctx context.Context, media *domain.Media, tags []*domain.Tag) error {
+	logger := utils.NewLogger(ctx)
+
+	var missing []*domain.Tag
+	for _, tag := range tags {
+		if !domain.ContainsID(tag.ID, media.Tags) && !domain.ContainsID(tag.ID, missing) {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := service.tagAssociation(ctx, media).Append(missing); err != nil {
+		logger.WithError(err).Error("failed adding tags")
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTag detaches the tag from the media, failing if the tag was not attached
+func (service *mediaService) RemoveTag(ctx context.Context, media *domain.Media, tagID uuid.UUID) error {
+	logger := utils.NewLogger(ctx)
+
+	index := slices.IndexFunc(media.Tags, func(tag *domain.Tag) bool {
+		return tag.ID == tagID
+	})
+	if index < 0 {
+		return apierrors.NewNotFoundError(tagID)
+	}
+
+	if err := service.tagAssociation(ctx, media).Delete(media.Tags[index]); err != nil {
+		logger.WithError(err).Error("failed removing tag")
+		return err
+	}
+
+	return nil
+}
+
+// ReplaceTags replaces all tags of the media, an empty slice removes all tags
+func (service *mediaService) ReplaceTags(ctx context.Context, media *domain.Media, tags []*domain.Tag) error {
+	logger := utils.NewLogger(ctx)
+
+	association := service.tagAssociation(ctx, media)
+
+	var err error
+	if len(tags) == 0 {
+		err = association.Clear()
+	} else {
+		err = association.Replace(tags)
+	}
+	if err != nil {
+		logger.WithError(err).Error("failed replacing tags")
+		return err
+	}
+
+	return nil
 }
@@ -4,8 +4,12 @@ import (
 	"context"
 	"slices"
 	"testing"
+	"time"
 
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
 	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/tagquery"
 	"github.com/TheSandyDave/Media-Tags/utils"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -82,3 +86,680 @@ func Test_FilterByTagOption_FilterTests(t *testing.T) {
 	}
 
 }
+
+func Test_MediaService_Delete_RemovesTagAssociations(t *testing.T) {
+	// Arrange
+	tag := domain.Tag{Name: "TestTag"}
+	mediaToDelete := domain.Media{Name: "deleted", Tags: []*domain.Tag{&tag}, Thumbnails: []*domain.Thumbnail{{Size: 256}}}
+	mediaToKeep := domain.Media{Name: "kept", Tags: []*domain.Tag{&tag}, Thumbnails: []*domain.Thumbnail{{Size: 256}}}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&[]*domain.Media{&mediaToDelete, &mediaToKeep}).Error)
+	service := NewMediaService(database)
+
+	// Act
+	err := service.Delete(context.Background(), mediaToDelete.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	var associations int64
+	database.Table("media_tags").Where("media_id = ?", mediaToDelete.ID).Count(&associations)
+	assert.Equal(t, int64(0), associations)
+	database.Table("media_tags").Where("media_id = ?", mediaToKeep.ID).Count(&associations)
+	assert.Equal(t, int64(1), associations)
+	var thumbnails int64
+	database.Model(&domain.Thumbnail{}).Where("media_id = ?", mediaToDelete.ID).Count(&thumbnails)
+	assert.Equal(t, int64(0), thumbnails)
+	database.Model(&domain.Thumbnail{}).Where("media_id = ?", mediaToKeep.ID).Count(&thumbnails)
+	assert.Equal(t, int64(1), thumbnails)
+}
+
+func Test_MediaService_DeleteWithFiles_OnlyRemovesFilesNoOtherMediaUse(t *testing.T) {
+	// Arrange
+	thumbnails := func() []*domain.Thumbnail {
+		return []*domain.Thumbnail{{Size: 256, StorageKey: "thumbnails/shared-256.png"}}
+	}
+	first := domain.Media{Name: "first", StorageKey: "shared.png", Thumbnails: thumbnails()}
+	second := domain.Media{Name: "second", StorageKey: "shared.png", Thumbnails: thumbnails()}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&[]*domain.Media{&first, &second}).Error)
+	// acquired the way uploads do before storing the files
+	files := NewStoredFileService(database)
+	for range 2 {
+		require.NoError(t, files.Acquire(context.Background(), "shared.png", "thumbnails/shared-256.png"))
+	}
+	service := NewMediaService(database)
+	var removed []string
+	remove := func(keys ...string) { removed = append(removed, keys...) }
+
+	// Act
+	require.NoError(t, service.DeleteWithFiles(context.Background(), first.ID, remove))
+	removedWhileShared := removed
+	require.NoError(t, service.DeleteWithFiles(context.Background(), second.ID, remove))
+
+	// Assert
+	assert.Empty(t, removedWhileShared)
+	assert.ElementsMatch(t, []string{"shared.png", "thumbnails/shared-256.png"}, removed)
+}
+
+func Test_MediaService_DeleteWithFiles_ChangesNothingForMediaTheOptionsLeaveOut(t *testing.T) {
+	// Arrange
+	tag := &domain.Tag{Name: "tag"}
+	media := domain.Media{
+		Name:       "media",
+		Owned:      domain.Owned{OwnerID: auth.APIKeySubject("owner")},
+		StorageKey: "file.png",
+		Tags:       []*domain.Tag{tag},
+		Thumbnails: []*domain.Thumbnail{{Size: 256, StorageKey: "thumbnails/file-256.png"}},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	require.NoError(t, NewStoredFileService(database).Acquire(context.Background(), "file.png", "thumbnails/file-256.png"))
+	service := NewMediaService(database)
+	principal := &auth.Principal{Subject: auth.APIKeySubject("other"), Role: auth.RoleUploader}
+	ctx := auth.WithPrincipal(context.Background(), principal)
+	var removed []string
+
+	// Act
+	err := service.DeleteWithFiles(ctx, media.ID, func(keys ...string) { removed = append(removed, keys...) }, OwnedByOption[domain.Media](ctx))
+
+	// Assert
+	assert.IsType(t, &apierrors.RecordNotFoundError{}, err)
+	assert.Empty(t, removed)
+	var result domain.Media
+	require.NoError(t, database.Preload("Tags").Preload("Thumbnails").First(&result, media.ID).Error)
+	assert.Len(t, result.Tags, 1)
+	assert.Len(t, result.Thumbnails, 1)
+}
+
+func Test_MediaService_Delete_KeepsTheReferencesToStoredFiles(t *testing.T) {
+	// Arrange
+	media := domain.Media{Name: "media", StorageKey: "file.png"}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	require.NoError(t, NewStoredFileService(database).Acquire(context.Background(), "file.png"))
+	service := NewMediaService(database)
+
+	// Act
+	err := service.Delete(context.Background(), media.ID)
+
+	// Assert
+	require.NoError(t, err)
+	var file domain.StoredFile
+	require.NoError(t, database.First(&file, "storage_key = ?", "file.png").Error)
+	assert.Equal(t, int64(1), file.ReferenceCount)
+}
+
+func Test_MediaService_TagChanges_UpdateAssociations(t *testing.T) {
+	tag1 := domain.Tag{Name: "TestTag1"}
+	tag2 := domain.Tag{Name: "TestTag2"}
+	tag3 := domain.Tag{Name: "TestTag3"}
+
+	testCases := map[string]struct {
+		change       func(service IMediaService, media *domain.Media) error
+		expectedTags []string
+	}{
+		"adding tags keeps existing tags and skips duplicates": {
+			change: func(service IMediaService, media *domain.Media) error {
+				return service.AddTags(context.Background(), media, []*domain.Tag{&tag1, &tag2, &tag2})
+			},
+			expectedTags: []string{tag1.Name, tag2.Name},
+		},
+		"removing a tag detaches it": {
+			change: func(service IMediaService, media *domain.Media) error {
+				return service.RemoveTag(context.Background(), media, tag1.ID)
+			},
+			expectedTags: []string{},
+		},
+		"replacing tags removes tags that are not passed": {
+			change: func(service IMediaService, media *domain.Media) error {
+				return service.ReplaceTags(context.Background(), media, []*domain.Tag{&tag2, &tag3})
+			},
+			expectedTags: []string{tag2.Name, tag3.Name},
+		},
+		"replacing with no tags removes all tags": {
+			change: func(service IMediaService, media *domain.Media) error {
+				return service.ReplaceTags(context.Background(), media, nil)
+			},
+			expectedTags: []string{},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			database := utils.NewInMemoryDatabase(t)
+			require.NoError(t, database.Create(&[]*domain.Tag{&tag1, &tag2, &tag3}).Error)
+			media := domain.Media{Name: "media", Tags: []*domain.Tag{&tag1}}
+			require.NoError(t, database.Omit("Tags.*").Create(&media).Error)
+			service := NewMediaService(database)
+
+			// Act
+			err := testCase.change(service, &media)
+
+			// Assert
+			assert.NoError(t, err)
+			result, err := service.GetWithID(context.Background(), media.ID)
+			require.NoError(t, err)
+			names := []string{}
+			for _, tag := range result.Tags {
+				names = append(names, tag.Name)
+			}
+			assert.ElementsMatch(t, testCase.expectedTags, names)
+
+			var tagCount int64
+			database.Model(&domain.Tag{}).Count(&tagCount)
+			assert.Equal(t, int64(3), tagCount, "tags themselves should not be created or removed")
+		})
+	}
+}
+
+func Test_MediaService_RemoveTag_FailsIfTagIsNotAttached(t *testing.T) {
+	// Arrange
+	attached := domain.Tag{Name: "attached"}
+	media := domain.Media{Name: "media", Tags: []*domain.Tag{&attached}}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+	notAttachedID := uuid.New()
+
+	// Act
+	err := service.RemoveTag(context.Background(), &media, notAttachedID)
+
+	// Assert
+	assert.IsType(t, &apierrors.RecordNotFoundError{}, err)
+	assert.ErrorContains(t, err, notAttachedID.String())
+}
+
+func Test_MediaService_GetPage_CombinesWithTagFilter(t *testing.T) {
+	// Arrange
+	tag := domain.Tag{Name: "TestTag"}
+	media := []*domain.Media{
+		{Name: "b", Tags: []*domain.Tag{&tag}},
+		{Name: "untagged"},
+		{Name: "a", Tags: []*domain.Tag{&tag}},
+		{Name: "c", Tags: []*domain.Tag{&tag}},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+	pagination := Pagination{Limit: 2, Sort: SortByName}
+
+	// Act
+	first, err := service.GetPage(context.Background(), pagination, service.FilterByTagOption(tag.Name))
+	require.NoError(t, err)
+	pagination.Cursor, err = DecodeCursor(first.NextCursor, SortByName, false)
+	require.NoError(t, err)
+	second, err := service.GetPage(context.Background(), pagination, service.FilterByTagOption(tag.Name))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, int64(3), *first.Total)
+	names := []string{}
+	for _, item := range append(first.Items, second.Items...) {
+		names = append(names, item.Name)
+		assert.Len(t, item.Tags, 1)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Empty(t, second.NextCursor)
+}
+
+func Test_FilterByTagQueryOption_FilterTests(t *testing.T) {
+	// Arrange
+	cat := domain.Tag{Name: "cat"}
+	outdoor := domain.Tag{Name: "outdoor"}
+	garden := domain.Tag{Name: "garden"}
+	blurry := domain.Tag{Name: "blurry"}
+	media := []*domain.Media{
+		{Name: "cat outdoor", Tags: []*domain.Tag{&cat, &outdoor}},
+		{Name: "cat garden blurry", Tags: []*domain.Tag{&cat, &garden, &blurry}},
+		{Name: "cat indoor"},
+		{Name: "dog garden", Tags: []*domain.Tag{&garden}},
+	}
+	media[2].Tags = []*domain.Tag{&cat}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+
+	testCases := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"single tag":          {query: "garden", expected: []string{"cat garden blurry", "dog garden"}},
+		"and":                 {query: "cat AND garden", expected: []string{"cat garden blurry"}},
+		"or":                  {query: "outdoor OR garden", expected: []string{"cat outdoor", "cat garden blurry", "dog garden"}},
+		"not":                 {query: "NOT cat", expected: []string{"dog garden"}},
+		"combined":            {query: "cat AND (outdoor OR garden) AND NOT blurry", expected: []string{"cat outdoor"}},
+		"unknown tag":         {query: "horse", expected: []string{}},
+		"negated unknown tag": {query: "cat AND NOT horse", expected: []string{"cat outdoor", "cat garden blurry", "cat indoor"}},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			query, err := tagquery.Parse(testCase.query)
+			require.NoError(t, err)
+
+			// Act
+			res, err := service.Get(context.Background(), service.FilterByTagQueryOption(query, ExactTag))
+
+			// Assert
+			assert.NoError(t, err)
+			names := []string{}
+			for _, item := range res {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
+
+func Test_FilterByTagQueryOption_CombinesWithTagFilter(t *testing.T) {
+	// Arrange
+	cat := domain.Tag{Name: "cat"}
+	blurry := domain.Tag{Name: "blurry"}
+	media := []*domain.Media{
+		{Name: "sharp", Tags: []*domain.Tag{&cat}},
+		{Name: "blurry", Tags: []*domain.Tag{&cat, &blurry}},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+	query, err := tagquery.Parse("NOT blurry")
+	require.NoError(t, err)
+
+	// Act
+	res, err := service.Get(context.Background(), service.FilterByTagOption("cat"), service.FilterByTagQueryOption(query, ExactTag))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "sharp", res[0].Name)
+	}
+}
+
+func Test_MediaService_TagFilterOptions_FilterTests(t *testing.T) {
+	// Arrange
+	cat := domain.Tag{Name: "cat"}
+	dog := domain.Tag{Name: "dog"}
+	blurry := domain.Tag{Name: "blurry"}
+	media := []*domain.Media{
+		{Name: "cat", Tags: []*domain.Tag{&cat}},
+		{Name: "cat dog", Tags: []*domain.Tag{&cat, &dog}},
+		{Name: "dog blurry", Tags: []*domain.Tag{&dog, &blurry}},
+		{Name: "untagged"},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+
+	testCases := map[string]struct {
+		options  []Option[domain.Media]
+		expected []string
+	}{
+		"all tags": {
+			options:  []Option[domain.Media]{service.FilterByAllTagsOption([]string{"cat", "dog"}, ExactTag)},
+			expected: []string{"cat dog"},
+		},
+		"any tag": {
+			options:  []Option[domain.Media]{service.FilterByAnyTagOption([]string{"cat", "blurry"}, ExactTag)},
+			expected: []string{"cat", "cat dog", "dog blurry"},
+		},
+		"excluded tags": {
+			options:  []Option[domain.Media]{service.ExcludeTagsOption([]string{"cat", "blurry"}, ExactTag)},
+			expected: []string{"untagged"},
+		},
+		"any tag without excluded tag": {
+			options: []Option[domain.Media]{
+				service.FilterByAnyTagOption([]string{"cat", "dog"}, ExactTag),
+				service.ExcludeTagsOption([]string{"blurry"}, ExactTag),
+			},
+			expected: []string{"cat", "cat dog"},
+		},
+		"all tags and any tag": {
+			options: []Option[domain.Media]{
+				service.FilterByAllTagsOption([]string{"dog"}, ExactTag),
+				service.FilterByAnyTagOption([]string{"cat", "unknown"}, ExactTag),
+			},
+			expected: []string{"cat dog"},
+		},
+		"unknown tag in all tags": {
+			options:  []Option[domain.Media]{service.FilterByAllTagsOption([]string{"cat", "unknown"}, ExactTag)},
+			expected: []string{},
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			res, err := service.Get(context.Background(), testCase.options...)
+
+			// Assert
+			assert.NoError(t, err)
+			names := []string{}
+			for _, item := range res {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
+
+func Test_MediaService_TagFilterOptions_MatchDescendants(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	tagService := NewTagService(database)
+	_, cat, tabby, dog := createTagTree(t, tagService)
+	media := []*domain.Media{
+		{Name: "tabby", Tags: []*domain.Tag{tabby}},
+		{Name: "cat", Tags: []*domain.Tag{cat}},
+		{Name: "dog", Tags: []*domain.Tag{dog}},
+	}
+	require.NoError(t, database.Omit("Tags.*").Create(&media).Error)
+	service := NewMediaService(database)
+	query, err := tagquery.Parse("animal AND NOT cat")
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		option   Option[domain.Media]
+		expected []string
+	}{
+		"exact tag":              {option: service.FilterByAllTagsOption([]string{"animal"}, ExactTag), expected: []string{}},
+		"all with descendants":   {option: service.FilterByAllTagsOption([]string{"animal"}, TagWithDescendants), expected: []string{"tabby", "cat", "dog"}},
+		"any with descendants":   {option: service.FilterByAnyTagOption([]string{"cat", "unknown"}, TagWithDescendants), expected: []string{"tabby", "cat"}},
+		"exclude descendants":    {option: service.ExcludeTagsOption([]string{"cat"}, TagWithDescendants), expected: []string{"dog"}},
+		"query with descendants": {option: service.FilterByTagQueryOption(query, TagWithDescendants), expected: []string{"dog"}},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			res, err := service.Get(context.Background(), testCase.option)
+
+			// Assert
+			assert.NoError(t, err)
+			names := []string{}
+			for _, item := range res {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
+
+func Test_MediaService_TagFilterOptions_ResolveAliases(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	tagService := NewTagService(database)
+	nyc := &domain.Tag{Name: "New York City", Aliases: []*domain.TagAlias{{Name: "nyc"}}}
+	require.NoError(t, tagService.Create(context.Background(), nyc))
+	media := []*domain.Media{
+		{Name: "skyline", Tags: []*domain.Tag{nyc}},
+		{Name: "untagged"},
+	}
+	require.NoError(t, database.Omit("Tags.*").Create(&media).Error)
+	service := NewMediaService(database)
+	query, err := tagquery.Parse("nyc")
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		option   Option[domain.Media]
+		expected []string
+	}{
+		"single tag":  {option: service.FilterByTagOption("nyc"), expected: []string{"skyline"}},
+		"any tag":     {option: service.FilterByAnyTagOption([]string{"nyc"}, ExactTag), expected: []string{"skyline"}},
+		"exclude tag": {option: service.ExcludeTagsOption([]string{"nyc"}, ExactTag), expected: []string{"untagged"}},
+		"tag query":   {option: service.FilterByTagQueryOption(query, TagWithDescendants), expected: []string{"skyline"}},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			res, err := service.Get(context.Background(), testCase.option)
+
+			// Assert
+			assert.NoError(t, err)
+			names := []string{}
+			for _, item := range res {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
+
+func Test_MediaService_FilterByKindOption_FilterTests(t *testing.T) {
+	// Arrange
+	tag := domain.Tag{Name: "TestTag"}
+	media := []*domain.Media{
+		{Name: "photo", Kind: domain.MediaKindImage, Tags: []*domain.Tag{&tag}},
+		{Name: "clip", Kind: domain.MediaKindVideo, Tags: []*domain.Tag{&tag}},
+		{Name: "song", Kind: domain.MediaKindAudio},
+		// media without a kind were uploaded before kinds existed, when only images were allowed
+		{Name: "legacy", Tags: []*domain.Tag{&tag}},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+
+	testCases := map[string]struct {
+		kind          domain.MediaKind
+		expectedNames []string
+	}{
+		"images include media without a kind": {kind: domain.MediaKindImage, expectedNames: []string{"legacy", "photo"}},
+		"videos":                              {kind: domain.MediaKindVideo, expectedNames: []string{"clip"}},
+		"nothing of the kind":                 {kind: domain.MediaKindDocument, expectedNames: []string{}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			page, err := service.GetPage(
+				context.Background(),
+				Pagination{Limit: DefaultPageSize, Sort: SortByName},
+				service.FilterByTagOption(tag.Name),
+				service.FilterByKindOption(testCase.kind),
+			)
+
+			// Assert
+			require.NoError(t, err)
+			names := []string{}
+			for _, item := range page.Items {
+				names = append(names, item.Name)
+				assert.Equal(t, testCase.kind, item.Kind)
+			}
+			assert.Equal(t, testCase.expectedNames, names)
+		})
+	}
+}
+
+func Test_MediaService_MetadataFilterOptions_FilterTests(t *testing.T) {
+	// Arrange
+	takenAt := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		parsed = parsed.UTC()
+		return &parsed
+	}
+	text := func(value string) *string { return &value }
+
+	media := []*domain.Media{
+		{Name: "beach", Metadata: &domain.MediaMetadata{CameraMake: text("Canon"), CameraModel: text("EOS 5D"), TakenAt: takenAt("2024-05-01T12:00:00Z")}},
+		{Name: "city", Metadata: &domain.MediaMetadata{CameraMake: text("Apple"), CameraModel: text("iPhone 15"), TakenAt: takenAt("2023-01-01T12:00:00Z")}},
+		{Name: "scan", Metadata: &domain.MediaMetadata{CameraModel: text("50%_off")}},
+		{Name: "document"},
+		// recorded although stripped from the stored file, it must not match any filter
+		{Name: "stripped", Metadata: &domain.MediaMetadata{CameraMake: text("Canon"), TakenAt: takenAt("2024-03-01T12:00:00Z"), ExifStripped: true}},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+
+	testCases := map[string]struct {
+		options       []Option[domain.Media]
+		expectedNames []string
+	}{
+		"taken after": {
+			options:       []Option[domain.Media]{service.FilterByTakenAfterOption(*takenAt("2024-01-01T00:00:00Z"))},
+			expectedNames: []string{"beach"},
+		},
+		"taken after in another zone": {
+			options:       []Option[domain.Media]{service.FilterByTakenAfterOption(*takenAt("2024-05-01T13:30:00+02:00"))},
+			expectedNames: []string{"beach"},
+		},
+		"taken before": {
+			options:       []Option[domain.Media]{service.FilterByTakenBeforeOption(*takenAt("2024-05-01T12:00:00Z"))},
+			expectedNames: []string{"city"},
+		},
+		"taken between": {
+			options: []Option[domain.Media]{
+				service.FilterByTakenAfterOption(*takenAt("2022-01-01T00:00:00Z")),
+				service.FilterByTakenBeforeOption(*takenAt("2025-01-01T00:00:00Z")),
+			},
+			expectedNames: []string{"beach", "city"},
+		},
+		"camera make ignoring case": {
+			options:       []Option[domain.Media]{service.FilterByCameraOption("canon")},
+			expectedNames: []string{"beach"},
+		},
+		"camera model": {
+			options:       []Option[domain.Media]{service.FilterByCameraOption("iphone")},
+			expectedNames: []string{"city"},
+		},
+		"camera make and model": {
+			options:       []Option[domain.Media]{service.FilterByCameraOption("apple iphone")},
+			expectedNames: []string{"city"},
+		},
+		"camera with wildcard characters": {
+			options:       []Option[domain.Media]{service.FilterByCameraOption("%_")},
+			expectedNames: []string{"scan"},
+		},
+		"unknown camera": {
+			options:       []Option[domain.Media]{service.FilterByCameraOption("nikon")},
+			expectedNames: []string{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			page, err := service.GetPage(context.Background(), Pagination{Limit: DefaultPageSize, Sort: SortByName}, testCase.options...)
+
+			// Assert
+			require.NoError(t, err)
+			names := []string{}
+			for _, item := range page.Items {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, testCase.expectedNames, names)
+		})
+	}
+}
+
+func Test_MediaService_FilterByContentHashOption_FilterTests(t *testing.T) {
+	// Arrange
+	media := []*domain.Media{
+		{Name: "original", ContentHash: "abc"},
+		{Name: "copy", ContentHash: "abc"},
+		{Name: "other", ContentHash: "def"},
+		{Name: "legacy"},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewMediaService(database)
+
+	// Act
+	result, err := service.Get(context.Background(), service.FilterByContentHashOption("abc"), SortOption[domain.Media](SortByName, false))
+
+	// Assert
+	require.NoError(t, err)
+	names := []string{}
+	for _, item := range result {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"copy", "original"}, names)
+}
+
+func Test_MediaService_GetSimilar_FindsMediaWithinTheDistance(t *testing.T) {
+	// Arrange
+	hash := func(value uint64) *int64 {
+		signed := int64(value)
+		return &signed
+	}
+	media := []*domain.Media{
+		{Name: "same", PerceptualHash: hash(0xFFFF_0000_FFFF_0000)},
+		// three bits differ in different bands
+		{Name: "close", PerceptualHash: hash(0xFFFF_0000_FFFF_0000 ^ 0x0001_0001_0001_0000)},
+		// eight bits differ, two in every band
+		{Name: "spread", PerceptualHash: hash(0xFFFF_0000_FFFF_0000 ^ 0x0003_0003_0003_0003)},
+		// eight bits differ in a single band, the other bands are equal
+		{Name: "concentrated", PerceptualHash: hash(0xFFFF_0000_FFFF_0000 ^ 0x0000_0000_0000_00FF)},
+		// nine bits differ
+		{Name: "far", PerceptualHash: hash(0xFFFF_0000_FFFF_0000 ^ 0x0007_0003_0003_0003)},
+		{Name: "inverted", PerceptualHash: hash(^uint64(0xFFFF_0000_FFFF_0000))},
+		{Name: "document"},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	service := NewMediaService(database)
+	require.NoError(t, service.Create(context.Background(), media...))
+
+	testCases := map[string]struct {
+		maxDistance       int
+		limit             int
+		expectedNames     []string
+		expectedDistances []int
+	}{
+		"identical":       {maxDistance: 0, limit: 25, expectedNames: []string{"same"}, expectedDistances: []int{0}},
+		"close":           {maxDistance: 3, limit: 25, expectedNames: []string{"same", "close"}, expectedDistances: []int{0, 3}},
+		"up to eight":     {maxDistance: 8, limit: 25, expectedNames: []string{"same", "close", "concentrated", "spread"}, expectedDistances: []int{0, 3, 8, 8}},
+		"up to the limit": {maxDistance: 16, limit: 25, expectedNames: []string{"same", "close", "concentrated", "spread", "far"}, expectedDistances: []int{0, 3, 8, 8, 9}},
+		"closest only":    {maxDistance: 16, limit: 3, expectedNames: []string{"same", "close", "concentrated"}, expectedDistances: []int{0, 3, 8}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			similar, err := service.GetSimilar(context.Background(), 0xFFFF_0000_FFFF_0000, testCase.maxDistance, testCase.limit)
+
+			// Assert
+			require.NoError(t, err)
+			names := []string{}
+			distances := []int{}
+			for _, item := range similar {
+				names = append(names, item.Media.Name)
+				distances = append(distances, item.Distance)
+			}
+			assert.Equal(t, testCase.expectedNames, names)
+			assert.Equal(t, testCase.expectedDistances, distances)
+		})
+	}
+}
+
+func Test_MediaService_Delete_RemovesPerceptualHashBands(t *testing.T) {
+	// Arrange
+	hash := int64(42)
+	media := &domain.Media{Name: "media", PerceptualHash: &hash}
+
+	database := utils.NewInMemoryDatabase(t)
+	service := NewMediaService(database)
+	require.NoError(t, service.Create(context.Background(), media))
+
+	var before int64
+	require.NoError(t, database.Model(&domain.PerceptualHashBand{}).Where("media_id = ?", media.ID).Count(&before).Error)
+
+	// Act
+	err := service.Delete(context.Background(), media.ID)
+
+	// Assert
+	require.NoError(t, err)
+	var after int64
+	require.NoError(t, database.Model(&domain.PerceptualHashBand{}).Where("media_id = ?", media.ID).Count(&after).Error)
+	assert.Equal(t, int64(4), before)
+	assert.Equal(t, int64(0), after)
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OwnedByOption only keeps the items owned by the principal of the context, whose owner ID is the subject of the
+// principal. Admins keep every item, as do contexts without a principal, which only reach the services when
+// authentication is disabled
+func OwnedByOption[T domain.IDbObject](ctx context.Context) Option[T] {
+	return func(db *gorm.DB) *gorm.DB {
+		principal, ok := auth.PrincipalFrom(ctx)
+		if !ok || principal.Role == auth.RoleAdmin {
+			return db
+		}
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "owner_id"}, Value: principal.Subject})
+	}
+}
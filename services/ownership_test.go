@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BaseService_GetOwnedWithID_OnlyReturnsItemsThePrincipalMayChange(t *testing.T) {
+	t.Parallel()
+
+	database := utils.NewInMemoryDatabase(t)
+	owner := auth.APIKeySubject(uuid.NewString())
+	tag := &domain.Tag{Name: "owned", Owned: domain.Owned{OwnerID: owner}}
+	require.NoError(t, database.Create(tag).Error)
+	service := baseService[domain.Tag]{Database: database}
+
+	tests := map[string]struct {
+		principal     *auth.Principal
+		id            uuid.UUID
+		expectedError error
+	}{
+		"owner": {
+			principal: &auth.Principal{Subject: owner, Role: auth.RoleUploader},
+			id:        tag.ID,
+		},
+		"admin": {
+			principal: &auth.Principal{Subject: auth.TokenSubject("https://id.example.com", "admin"), Role: auth.RoleAdmin},
+			id:        tag.ID,
+		},
+		"authentication disabled": {
+			id: tag.ID,
+		},
+		"other uploader": {
+			principal:     &auth.Principal{Subject: auth.APIKeySubject(uuid.NewString()), Role: auth.RoleUploader},
+			id:            tag.ID,
+			expectedError: &apierrors.ForbiddenError{},
+		},
+		"token claiming the ID of the API key": {
+			principal:     &auth.Principal{Subject: auth.TokenSubject("https://id.example.com", strings.TrimPrefix(owner, "apikey:")), Role: auth.RoleUploader},
+			id:            tag.ID,
+			expectedError: &apierrors.ForbiddenError{},
+		},
+		"missing": {
+			principal:     &auth.Principal{Subject: owner, Role: auth.RoleUploader},
+			id:            uuid.New(),
+			expectedError: &apierrors.RecordNotFoundError{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctx := context.Background()
+			if test.principal != nil {
+				ctx = auth.WithPrincipal(ctx, test.principal)
+			}
+
+			// Act
+			result, err := service.GetOwnedWithID(ctx, test.id)
+
+			// Assert
+			if test.expectedError != nil {
+				assert.IsType(t, test.expectedError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tag.ID, result.ID)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultPageSize = 25
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortField is a field results can be sorted on, ties are always broken on the ID so the order is stable
+type SortField struct {
+	// Name of the field in the API
+	Name   string
+	Column string
+	isTime bool
+}
+
+var (
+	SortByCreatedAt = SortField{Name: "createdAt", Column: "created_at", isTime: true}
+	SortByName      = SortField{Name: "name", Column: "name"}
+)
+
+// Pagination selects a single page of results
+type Pagination struct {
+	Limit      int
+	Sort       SortField
+	Descending bool
+	// Cursor continues after the last item of a previous page, nil for the first page
+	Cursor *Cursor
+}
+
+// Cursor identifies the last item of a page by its sort value and ID
+type Cursor struct {
+	Sort       string    `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Value      string    `json:"v"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (cursor Cursor) Encode() string {
+	// marshalling a struct of strings and a UUID can't fail
+	content, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// DecodeCursor parses a cursor created by Cursor.Encode, the cursor has to match the sort of the pagination it is used for
+func DecodeCursor(encoded string, sort SortField, descending bool) (*Cursor, error) {
+	content, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(content, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort.Name || cursor.Descending != descending {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := sort.parse(cursor.Value); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func (sort SortField) parse(value string) (any, error) {
+	if sort.isTime {
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return value, nil
+}
+
+func (sort SortField) format(value any) string {
+	if sort.isTime {
+		return value.(time.Time).Format(time.RFC3339Nano)
+	}
+	return value.(string)
+}
+
+// SortOption orders results on the sort field followed by the ID
+func SortOption[T domain.IDbObject](sort SortField, descending bool) Option[T] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: sort.Column}, Desc: descending}).
+			Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}, Desc: descending})
+	}
+}
+
+// AfterCursorOption only keeps results that come after the cursor when sorted with the SortOption of the same sort
+func AfterCursorOption[T domain.IDbObject](sort SortField, descending bool, cursor Cursor) Option[T] {
+	return func(db *gorm.DB) *gorm.DB {
+		// DecodeCursor already validated the value
+		value, _ := sort.parse(cursor.Value)
+		if sort.isTime {
+			// stored times are compared as text, which only works in the UTC offset they are stored in
+			value = value.(time.Time).UTC()
+		}
+
+		column := clause.Column{Table: clause.CurrentTable, Name: sort.Column}
+		idColumn := clause.Column{Table: clause.CurrentTable, Name: "id"}
+
+		after := func(column clause.Column, value any) clause.Expression {
+			if descending {
+				return clause.Lt{Column: column, Value: value}
+			}
+			return clause.Gt{Column: column, Value: value}
+		}
+
+		return db.Where(clause.Or(
+			after(column, value),
+			clause.And(clause.Eq{Column: column, Value: value}, after(idColumn, cursor.ID)),
+		))
+	}
+}
+
+func LimitOption[T domain.IDbObject](limit int) Option[T] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Limit(limit)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createPaginationModels creates models sharing creation times and names, so the ID has to break ties
+func createPaginationModels(t *testing.T, database *gorm.DB) []*TestIDbModel {
+	t.Helper()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	models := make([]*TestIDbModel, 7)
+	for i := range models {
+		models[i] = &TestIDbModel{
+			BaseObject: domain.BaseObject{
+				ID:        uuid.New(),
+				CreatedAt: start.Add(time.Duration(i/2) * time.Millisecond),
+			},
+			Name: fmt.Sprintf("name%d", (len(models)-i)/3),
+		}
+	}
+	require.NoError(t, database.Create(&models).Error)
+
+	return models
+}
+
+func TestBaseService_GetPage_WalksAllPagesInOrder(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		sort       SortField
+		descending bool
+	}{
+		"createdAt ascending":  {sort: SortByCreatedAt},
+		"createdAt descending": {sort: SortByCreatedAt, descending: true},
+		"name ascending":       {sort: SortByName},
+		"name descending":      {sort: SortByName, descending: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			database := utils.NewInMemoryDatabase(t, TestIDbModel{})
+			service := baseService[TestIDbModel]{
+				Database: database,
+			}
+			models := createPaginationModels(t, database)
+
+			expected := slices.Clone(models)
+			slices.SortFunc(expected, func(a, b *TestIDbModel) int {
+				var order int
+				if testCase.sort == SortByName {
+					order = strings.Compare(a.Name, b.Name)
+				} else {
+					order = a.CreatedAt.Compare(b.CreatedAt)
+				}
+				if order == 0 {
+					order = strings.Compare(a.ID.String(), b.ID.String())
+				}
+				if testCase.descending {
+					return -order
+				}
+				return order
+			})
+
+			// Act
+			var result []*TestIDbModel
+			pagination := Pagination{Limit: 3, Sort: testCase.sort, Descending: testCase.descending}
+			for range len(models) {
+				page, err := service.GetPage(context.Background(), pagination)
+				require.NoError(t, err)
+				result = append(result, page.Items...)
+				if page.NextCursor == "" {
+					break
+				}
+
+				pagination.Cursor, err = DecodeCursor(page.NextCursor, testCase.sort, testCase.descending)
+				require.NoError(t, err)
+			}
+
+			// Assert
+			equal := slices.EqualFunc(expected, result, func(a, b *TestIDbModel) bool {
+				return a.ID == b.ID
+			})
+			assert.True(t, equal)
+		})
+	}
+}
+
+func TestBaseService_GetPage_OnlyCountsTotalForTheFirstPage(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	database := utils.NewInMemoryDatabase(t, TestIDbModel{})
+	service := baseService[TestIDbModel]{
+		Database: database,
+	}
+	createPaginationModels(t, database)
+	pagination := Pagination{Limit: 5, Sort: SortByCreatedAt}
+
+	// Act
+	first, err := service.GetPage(context.Background(), pagination)
+	require.NoError(t, err)
+	pagination.Cursor, err = DecodeCursor(first.NextCursor, SortByCreatedAt, false)
+	require.NoError(t, err)
+	second, err := service.GetPage(context.Background(), pagination)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Len(t, first.Items, 5)
+	if assert.NotNil(t, first.Total) {
+		assert.Equal(t, int64(7), *first.Total)
+	}
+	assert.Len(t, second.Items, 2)
+	assert.Nil(t, second.Total)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestBaseService_GetPage_AppliesFiltersToItemsAndTotal(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	database := utils.NewInMemoryDatabase(t, TestIDbModel{})
+	service := baseService[TestIDbModel]{
+		Database: database,
+	}
+	createPaginationModels(t, database)
+	var filter Option[TestIDbModel] = func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ?", "name1")
+	}
+
+	// Act
+	page, err := service.GetPage(context.Background(), Pagination{Limit: 10, Sort: SortByName}, filter)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	assert.Equal(t, int64(3), *page.Total)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestBaseService_GetPage_ComparesCreationTimesAcrossOffsets(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	database := utils.NewInMemoryDatabase(t, TestIDbModel{})
+	service := baseService[TestIDbModel]{
+		Database: database,
+	}
+	ahead := time.FixedZone("ahead", 2*60*60)
+	earlier := &TestIDbModel{BaseObject: domain.BaseObject{CreatedAt: time.Date(2024, 1, 1, 13, 0, 0, 0, ahead)}}
+	later := &TestIDbModel{BaseObject: domain.BaseObject{CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}}
+	require.NoError(t, database.Create([]*TestIDbModel{earlier, later}).Error)
+	// a cursor in another offset than the stored times, between both models
+	cursor := Cursor{Sort: SortByCreatedAt.Name, Value: "2024-01-01T12:30:00+01:00", ID: uuid.Nil}
+
+	// Act
+	page, err := service.GetPage(context.Background(), Pagination{Limit: 10, Sort: SortByCreatedAt, Cursor: &cursor})
+
+	// Assert
+	require.NoError(t, err)
+	var stored string
+	require.NoError(t, database.Raw("SELECT CAST(created_at AS TEXT) FROM test_idb_models WHERE id = ?", earlier.ID).Row().Scan(&stored))
+	assert.Equal(t, "2024-01-01 11:00:00+00:00", stored, "creation times are stored as text in UTC")
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, later.ID, page.Items[0].ID)
+	}
+}
+
+func Test_DecodeCursor_RejectsInvalidCursors(t *testing.T) {
+	t.Parallel()
+
+	valid := Cursor{Sort: SortByCreatedAt.Name, Value: time.Now().Format(time.RFC3339Nano), ID: uuid.New()}
+
+	testCases := map[string]struct {
+		cursor     string
+		sort       SortField
+		descending bool
+	}{
+		"not base64":          {cursor: "%%%", sort: SortByCreatedAt},
+		"not json":            {cursor: "bm90IGpzb24", sort: SortByCreatedAt},
+		"different sort":      {cursor: valid.Encode(), sort: SortByName},
+		"different direction": {cursor: valid.Encode(), sort: SortByCreatedAt, descending: true},
+		"invalid time": {
+			cursor: Cursor{Sort: SortByCreatedAt.Name, Value: "yesterday", ID: uuid.New()}.Encode(),
+			sort:   SortByCreatedAt,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			cursor, err := DecodeCursor(testCase.cursor, testCase.sort, testCase.descending)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+			assert.Nil(t, cursor)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// compile time check for the struct implementing the interface
+var _ IStoredFileService = (*storedFileService)(nil)
+
+//go:generate go run go.uber.org/mock/mockgen -source $GOFILE -typed -destination ../generated/mock/services/mock_$GOFILE IStoredFileService
+
+// IStoredFileService counts the references to stored files, so files shared by media with the same content are only
+// removed once nothing refers to them anymore
+type IStoredFileService interface {
+	// Acquire adds a reference to every file. Files have to be acquired before they are stored, otherwise the last
+	// media referring to them could be deleted in between and take the files along
+	Acquire(ctx context.Context, keys ...string) error
+	// Release removes a reference from every file, remove is called with the files nothing refers to anymore.
+	// Without remove nothing is released, dropping the last reference would leave the files without a record
+	Release(ctx context.Context, remove RemoveFiles, keys ...string) error
+}
+
+// RemoveFiles removes stored files nothing refers to anymore. It is called before the released references are
+// committed, so the files can't be acquired again until they are gone
+type RemoveFiles func(keys ...string)
+
+type storedFileService struct {
+	Database *gorm.DB
+}
+
+func NewStoredFileService(db *gorm.DB) IStoredFileService {
+	return &storedFileService{
+		Database: db,
+	}
+}
+
+func (service *storedFileService) Acquire(ctx context.Context, keys ...string) error {
+	logger := utils.NewLogger(ctx)
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	files := make([]*domain.StoredFile, len(keys))
+	for i, key := range keys {
+		files[i] = &domain.StoredFile{StorageKey: key, ReferenceCount: 1}
+	}
+	err := service.Database.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "storage_key"}},
+			DoUpdates: clause.Assignments(map[string]any{"reference_count": gorm.Expr("reference_count + 1")}),
+		}).
+		Create(&files).Error
+	if err != nil {
+		logger.WithError(err).Error("failed acquiring stored files")
+		return err
+	}
+
+	return nil
+}
+
+func (service *storedFileService) Release(ctx context.Context, remove RemoveFiles, keys ...string) error {
+	return service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return releaseWithin(tx, remove, keys...)
+	})
+}
+
+// releaseWithin releases the files using the given database handle, allowing the references to be released in the
+// same transaction as the rows referring to the files are deleted. Without remove nothing is released
+func releaseWithin(tx *gorm.DB, remove RemoveFiles, keys ...string) error {
+	logger := utils.NewLogger(tx.Statement.Context)
+
+	if len(keys) == 0 || remove == nil {
+		return nil
+	}
+
+	for _, key := range keys {
+		err := tx.Model(&domain.StoredFile{}).
+			Where(clause.Eq{Column: "storage_key", Value: key}).
+			UpdateColumn("reference_count", gorm.Expr("reference_count - 1")).Error
+		if err != nil {
+			logger.WithError(err).Error("failed releasing stored files")
+			return err
+		}
+	}
+
+	var unreferenced []string
+	err := tx.Model(&domain.StoredFile{}).
+		Where("storage_key IN ? AND reference_count <= 0", keys).
+		Pluck("storage_key", &unreferenced).Error
+	if err != nil || len(unreferenced) == 0 {
+		return err
+	}
+
+	if err := tx.Where("storage_key IN ?", unreferenced).Delete(&domain.StoredFile{}).Error; err != nil {
+		logger.WithError(err).Error("failed releasing stored files")
+		return err
+	}
+	remove(unreferenced...)
+
+	return nil
+}
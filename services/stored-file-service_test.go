@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StoredFileService_Release_RemovesFilesOnceUnreferenced(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewStoredFileService(database)
+	require.NoError(t, service.Acquire(context.Background(), "shared.png", "shared.png", "single.png"))
+	var removed []string
+	remove := func(keys ...string) { removed = append(removed, keys...) }
+
+	// Act
+	require.NoError(t, service.Release(context.Background(), remove, "shared.png", "single.png"))
+	firstRemoved := removed
+	require.NoError(t, service.Release(context.Background(), remove, "shared.png"))
+
+	// Assert
+	assert.Equal(t, []string{"single.png"}, firstRemoved)
+	assert.Equal(t, []string{"single.png", "shared.png"}, removed)
+	var count int64
+	database.Model(&domain.StoredFile{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func Test_StoredFileService_Release_KeepsFilesWithoutACount(t *testing.T) {
+	// Arrange
+	service := NewStoredFileService(utils.NewInMemoryDatabase(t))
+	removed := false
+
+	// Act
+	err := service.Release(context.Background(), func(...string) { removed = true }, "unknown.png")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, removed, "files that were never counted can't be known to be unreferenced")
+}
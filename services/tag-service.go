@@ -1,8 +1,17 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
 	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // compile time check for the struct implementing the interface
@@ -11,6 +20,10 @@ var _ ITagService = (*tagService)(nil)
 //go:generate go run go.uber.org/mock/mockgen -source $GOFILE -typed -destination ../generated/mock/services/mock_$GOFILE ITagService
 type ITagService interface {
 	IBaseService[domain.Tag]
+	GetSubtree(ctx context.Context, id uuid.UUID) ([]*domain.Tag, error)
+	FilterByNameOption(name string) Option[domain.Tag]
+	ReplaceAliases(ctx context.Context, tag *domain.Tag, aliases []string) error
+	Merge(ctx context.Context, source *domain.Tag, target *domain.Tag) error
 }
 
 type tagService struct {
@@ -24,3 +37,295 @@ func NewTagService(db *gorm.DB) ITagService {
 		},
 	}
 }
+
+// FilterByNameOption only keeps the tag with the name, or the tag owning an alias with the name
+func (service *tagService) FilterByNameOption(name string) Option[domain.Tag] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("tags.id IN (?)", tagIDs([]string{name}, ExactTag))
+	}
+}
+
+// tagIDs is a subquery selecting the IDs of the tags in scope of the names, aliases resolve to their tag
+func tagIDs(names []string, scope TagScope) clause.Expression {
+	resolved := clause.Expr{
+		SQL:  "SELECT id FROM tags WHERE name IN ? UNION SELECT tag_id FROM tag_aliases WHERE name IN ?",
+		Vars: []any{names, names},
+	}
+
+	if scope == TagWithDescendants {
+		// UNION instead of UNION ALL stops the recursion on cycles
+		return clause.Expr{
+			SQL: `WITH RECURSIVE filter_tree(id) AS (
+				?
+				UNION
+				SELECT tags.id FROM tags INNER JOIN filter_tree ON tags.parent_id = filter_tree.id
+			) SELECT id FROM filter_tree`,
+			Vars: []any{resolved},
+		}
+	}
+
+	return resolved
+}
+
+// GetSubtree retrieves the tag followed by all its descendants, ordered by depth and name
+func (service *tagService) GetSubtree(ctx context.Context, id uuid.UUID) ([]*domain.Tag, error) {
+	logger := utils.NewLogger(ctx)
+
+	if _, err := service.GetWithID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var result []*domain.Tag
+	err := service.Database.WithContext(ctx).Raw(`
+		WITH RECURSIVE subtree(id, depth) AS (
+			SELECT id, 0 FROM tags WHERE id = ?
+			UNION
+			SELECT tags.id, subtree.depth + 1 FROM tags INNER JOIN subtree ON tags.parent_id = subtree.id
+		)
+		SELECT tags.* FROM tags INNER JOIN subtree ON tags.id = subtree.id ORDER BY subtree.depth, tags.name`, id).
+		Scan(&result).Error
+	if err != nil {
+		logger.WithError(err).Error("failed to get subtree")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Create checks the names and parents of the tags before creating them along with their aliases
+func (service *tagService) Create(ctx context.Context, tags ...*domain.Tag) error {
+	return service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, tag := range tags {
+			if err := service.checkName(tx, tag); err != nil {
+				return err
+			}
+			aliases := make([]string, len(tag.Aliases))
+			for i, alias := range tag.Aliases {
+				aliases[i] = alias.Name
+			}
+			if err := service.checkAliases(tx, tag, aliases); err != nil {
+				return err
+			}
+			if err := service.checkParent(tx, tag); err != nil {
+				return err
+			}
+		}
+
+		return nameConflict(service.createWithin(tx, tags...), tagNames(tags)...)
+	})
+}
+
+// Update saves the tag, refusing names in use and parents that would turn the hierarchy into a cycle
+func (service *tagService) Update(ctx context.Context, tag *domain.Tag) error {
+	return service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := service.checkName(tx, tag); err != nil {
+			return err
+		}
+		if err := service.checkParent(tx, tag); err != nil {
+			return err
+		}
+
+		return nameConflict(service.updateWithin(tx, tag), tag.Name)
+	})
+}
+
+// nameConflict reports a violated unique constraint as a conflict on the names of tags or aliases. Names are checked
+// before writing, but a concurrent request can claim a name between the check and the write
+func nameConflict(err error, names ...string) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || len(names) == 0 {
+		return err
+	}
+
+	return apierrors.NewTagNameConflictError(strings.Join(names, ", "))
+}
+
+func tagNames(tags []*domain.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// ReplaceAliases replaces all aliases of the tag, tag.Aliases is updated to the new aliases
+func (service *tagService) ReplaceAliases(ctx context.Context, tag *domain.Tag, aliases []string) error {
+	logger := utils.NewLogger(ctx)
+
+	aliases = slices.Compact(slices.Sorted(slices.Values(aliases)))
+	replacement := make([]*domain.TagAlias, len(aliases))
+	for i, name := range aliases {
+		replacement[i] = &domain.TagAlias{Name: name, TagID: tag.ID}
+	}
+
+	err := service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := service.checkAliases(tx, tag, aliases); err != nil {
+			return err
+		}
+
+		return nameConflict(replaceAliasesWithin(tx, tag.ID, replacement), aliases...)
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed replacing aliases")
+		return err
+	}
+
+	tag.Aliases = replacement
+	return nil
+}
+
+// replaceAliasesWithin replaces the aliases of the tag without checking their names first
+func replaceAliasesWithin(tx *gorm.DB, tagID uuid.UUID, replacement []*domain.TagAlias) error {
+	if err := tx.Where("tag_id = ?", tagID).Delete(&domain.TagAlias{}).Error; err != nil {
+		return err
+	}
+	if len(replacement) == 0 {
+		return nil
+	}
+	return tx.Create(replacement).Error
+}
+
+// Merge folds the source tag into the target, the media, aliases and children of the source move to the target
+// and the name of the source becomes an alias of the target before the source is deleted
+func (service *tagService) Merge(ctx context.Context, source *domain.Tag, target *domain.Tag) error {
+	logger := utils.NewLogger(ctx)
+
+	if source.ID == target.ID {
+		return apierrors.NewInvalidTagMergeError(source.ID, target.ID, "a tag cannot be merged into itself")
+	}
+
+	err := service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ancestors, err := ancestorIDs(tx, target.ID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(ancestors, source.ID) {
+			return apierrors.NewInvalidTagMergeError(source.ID, target.ID, "a tag cannot be merged into one of its descendants")
+		}
+
+		statements := []struct {
+			sql  string
+			vars []any
+		}{
+			// media having both tags only keep the row of the target
+			{"INSERT INTO media_tags (media_id, tag_id) SELECT media_id, ? FROM media_tags WHERE tag_id = ? AND media_id NOT IN (SELECT media_id FROM media_tags WHERE tag_id = ?)", []any{target.ID, source.ID, target.ID}},
+			{"DELETE FROM media_tags WHERE tag_id = ?", []any{source.ID}},
+			{"UPDATE tag_aliases SET tag_id = ? WHERE tag_id = ?", []any{target.ID, source.ID}},
+			{"UPDATE tags SET parent_id = ? WHERE parent_id = ?", []any{target.ID, source.ID}},
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement.sql, statement.vars...).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := service.deleteWithin(tx, source.ID); err != nil {
+			return err
+		}
+
+		return tx.Create(&domain.TagAlias{Name: source.Name, TagID: target.ID}).Error
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed merging tags")
+		return err
+	}
+
+	return nil
+}
+
+// checkName verifies no other tag or alias uses the name of the tag
+func (service *tagService) checkName(tx *gorm.DB, tag *domain.Tag) error {
+	var count int64
+	err := tx.Raw("SELECT (SELECT COUNT(*) FROM tags WHERE name = ? AND id <> ?) + (SELECT COUNT(*) FROM tag_aliases WHERE name = ?)", tag.Name, tag.ID, tag.Name).
+		Scan(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apierrors.NewTagNameConflictError(tag.Name)
+	}
+
+	return nil
+}
+
+// checkAliases verifies the aliases are not used as a tag name or as an alias of another tag
+func (service *tagService) checkAliases(tx *gorm.DB, tag *domain.Tag, aliases []string) error {
+	for _, alias := range aliases {
+		if alias == "" {
+			return apierrors.NewRequiredValueMissingError("alias name")
+		}
+		if alias == tag.Name {
+			return apierrors.NewTagNameConflictError(alias)
+		}
+	}
+
+	var conflicts []string
+	err := tx.Raw("SELECT name FROM tags WHERE name IN ? UNION SELECT name FROM tag_aliases WHERE name IN ? AND tag_id <> ?", aliases, aliases, tag.ID).
+		Scan(&conflicts).Error
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return apierrors.NewTagNameConflictError(conflicts[0])
+	}
+
+	return nil
+}
+
+// checkParent verifies the parent of the tag exists and that the tag is not one of its ancestors
+func (service *tagService) checkParent(tx *gorm.DB, tag *domain.Tag) error {
+	if tag.ParentID == nil {
+		return nil
+	}
+	parentID := *tag.ParentID
+	if parentID == tag.ID {
+		return apierrors.NewTagCycleError(tag.ID, parentID)
+	}
+
+	ancestors, err := ancestorIDs(tx, parentID)
+	if err != nil {
+		return err
+	}
+
+	if len(ancestors) == 0 {
+		return apierrors.NewInvalidTagsError([]uuid.UUID{parentID})
+	}
+	if slices.Contains(ancestors, tag.ID) {
+		return apierrors.NewTagCycleError(tag.ID, parentID)
+	}
+
+	return nil
+}
+
+// ancestorIDs retrieves the ID of the tag followed by the IDs of its ancestors, empty if the tag does not exist
+func ancestorIDs(tx *gorm.DB, id uuid.UUID) ([]uuid.UUID, error) {
+	// UNION instead of UNION ALL stops the recursion if the stored hierarchy somehow already contains a cycle
+	var ancestors []uuid.UUID
+	err := tx.Raw(`
+		WITH RECURSIVE ancestors(id, parent_id) AS (
+			SELECT id, parent_id FROM tags WHERE id = ?
+			UNION
+			SELECT tags.id, tags.parent_id FROM tags INNER JOIN ancestors ON tags.id = ancestors.parent_id
+		)
+		SELECT id FROM ancestors`, id).
+		Scan(&ancestors).Error
+
+	return ancestors, err
+}
+
+// Delete removes the tag and its aliases and detaches it from all media it was attached to, its children move up to its parent.
+// Nothing changes when the options leave the tag out
+func (service *tagService) Delete(ctx context.Context, id uuid.UUID, options ...Option[domain.Tag]) error {
+	return service.Database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM media_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM tag_aliases WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE tags SET parent_id = (SELECT parent_id FROM tags WHERE id = ?) WHERE parent_id = ?", id, id).Error; err != nil {
+			return err
+		}
+
+		return service.deleteWithin(tx, id, options...)
+	})
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	apierrors "github.com/TheSandyDave/Media-Tags/api_errors"
+	"github.com/TheSandyDave/Media-Tags/auth"
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TagService_Delete_DetachesTagFromMedia(t *testing.T) {
+	// Arrange
+	tagToDelete := domain.Tag{Name: "deleted"}
+	tagToKeep := domain.Tag{Name: "kept"}
+	media := domain.Media{Name: "media", Tags: []*domain.Tag{&tagToDelete, &tagToKeep}}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&media).Error)
+	service := NewTagService(database)
+
+	// Act
+	err := service.Delete(context.Background(), tagToDelete.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	var result domain.Media
+	require.NoError(t, database.Preload("Tags").First(&result, media.ID).Error)
+	if assert.Len(t, result.Tags, 1) {
+		assert.Equal(t, tagToKeep.ID, result.Tags[0].ID)
+	}
+}
+
+func idsOf(tags []*domain.Tag) []uuid.UUID {
+	ids := make([]uuid.UUID, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+// createTagTree creates animal > cat > tabby and animal > dog
+func createTagTree(t *testing.T, service ITagService) (animal, cat, tabby, dog *domain.Tag) {
+	t.Helper()
+
+	animal = &domain.Tag{Name: "animal"}
+	require.NoError(t, service.Create(context.Background(), animal))
+	cat = &domain.Tag{Name: "cat", ParentID: &animal.ID}
+	dog = &domain.Tag{Name: "dog", ParentID: &animal.ID}
+	require.NoError(t, service.Create(context.Background(), cat, dog))
+	tabby = &domain.Tag{Name: "tabby", ParentID: &cat.ID}
+	require.NoError(t, service.Create(context.Background(), tabby))
+
+	return animal, cat, tabby, dog
+}
+
+func Test_TagService_GetSubtree_ReturnsDescendantsByDepth(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	animal, cat, tabby, dog := createTagTree(t, service)
+	require.NoError(t, service.Create(context.Background(), &domain.Tag{Name: "unrelated"}))
+
+	// Act
+	subtree, err := service.GetSubtree(context.Background(), animal.ID)
+	catSubtree, catErr := service.GetSubtree(context.Background(), cat.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, catErr)
+	assert.Equal(t, []uuid.UUID{animal.ID, cat.ID, dog.ID, tabby.ID}, idsOf(subtree))
+	assert.Equal(t, []uuid.UUID{cat.ID, tabby.ID}, idsOf(catSubtree))
+}
+
+func Test_TagService_GetSubtree_FailsIfTagDoesNotExist(t *testing.T) {
+	// Arrange
+	service := NewTagService(utils.NewInMemoryDatabase(t))
+
+	// Act
+	subtree, err := service.GetSubtree(context.Background(), uuid.New())
+
+	// Assert
+	assert.Nil(t, subtree)
+	assert.IsType(t, &apierrors.RecordNotFoundError{}, err)
+}
+
+func Test_TagService_Update_PreventsCycles(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	animal, cat, tabby, _ := createTagTree(t, service)
+
+	testCases := map[string]struct {
+		tag    *domain.Tag
+		parent uuid.UUID
+	}{
+		"own parent":         {tag: cat, parent: cat.ID},
+		"child as parent":    {tag: cat, parent: tabby.ID},
+		"descendant as root": {tag: animal, parent: tabby.ID},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tag := *testCase.tag
+			tag.ParentID = &testCase.parent
+
+			// Act
+			err := service.Update(context.Background(), &tag)
+
+			// Assert
+			assert.IsType(t, &apierrors.TagCycleError{}, err)
+			var stored domain.Tag
+			require.NoError(t, database.First(&stored, tag.ID).Error)
+			assert.Equal(t, testCase.tag.ParentID, stored.ParentID)
+		})
+	}
+}
+
+func Test_TagService_Update_MovesTag(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	_, _, tabby, dog := createTagTree(t, service)
+	tabby.ParentID = &dog.ID
+
+	// Act
+	err := service.Update(context.Background(), tabby)
+
+	// Assert
+	require.NoError(t, err)
+	subtree, err := service.GetSubtree(context.Background(), dog.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{dog.ID, tabby.ID}, idsOf(subtree))
+}
+
+func Test_TagService_Create_FailsIfParentDoesNotExist(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	missing := uuid.New()
+
+	// Act
+	err := service.Create(context.Background(), &domain.Tag{Name: "orphan", ParentID: &missing})
+
+	// Assert
+	assert.IsType(t, &apierrors.InvalidTagsError{}, err)
+	var count int64
+	database.Model(&domain.Tag{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func Test_TagService_Create_ReportsViolatedUniqueNameAsConflict(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database).(*tagService)
+	require.NoError(t, service.Create(context.Background(), &domain.Tag{Name: "tabby"}))
+	duplicate := &domain.Tag{Name: "tabby"}
+
+	// Act, skipping the name check like a concurrent request that passed it first
+	err := nameConflict(service.createWithin(database, duplicate), duplicate.Name)
+
+	// Assert
+	assert.IsType(t, &apierrors.TagNameConflictError{}, err)
+}
+
+func Test_TagService_ReplaceAliases_ReportsViolatedUniqueNameAsConflict(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	nyc := &domain.Tag{Name: "New York City", Aliases: []*domain.TagAlias{{Name: "big apple"}}}
+	boston := &domain.Tag{Name: "Boston"}
+	require.NoError(t, service.Create(context.Background(), nyc, boston))
+	duplicate := []*domain.TagAlias{{Name: "big apple", TagID: boston.ID}}
+
+	// Act, skipping the name check like a concurrent request that passed it first
+	err := nameConflict(replaceAliasesWithin(database, boston.ID, duplicate), "big apple")
+
+	// Assert
+	assert.IsType(t, &apierrors.TagNameConflictError{}, err)
+}
+
+func Test_TagService_Delete_MovesChildrenToParent(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	animal, cat, tabby, _ := createTagTree(t, service)
+
+	// Act
+	err := service.Delete(context.Background(), cat.ID)
+
+	// Assert
+	require.NoError(t, err)
+	var stored domain.Tag
+	require.NoError(t, database.First(&stored, tabby.ID).Error)
+	assert.Equal(t, &animal.ID, stored.ParentID)
+}
+
+func Test_TagService_Delete_ChangesNothingForTagsTheOptionsLeaveOut(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	parent := &domain.Tag{Name: "parent", Owned: domain.Owned{OwnerID: auth.APIKeySubject("owner")}}
+	require.NoError(t, service.Create(context.Background(), parent))
+	child := &domain.Tag{Name: "child", ParentID: &parent.ID}
+	media := &domain.Media{Name: "media", Tags: []*domain.Tag{parent}}
+	require.NoError(t, service.Create(context.Background(), child))
+	require.NoError(t, database.Create(media).Error)
+	principal := &auth.Principal{Subject: auth.APIKeySubject("other"), Role: auth.RoleUploader}
+	ctx := auth.WithPrincipal(context.Background(), principal)
+
+	// Act
+	err := service.Delete(ctx, parent.ID, OwnedByOption[domain.Tag](ctx))
+
+	// Assert
+	assert.IsType(t, &apierrors.RecordNotFoundError{}, err)
+	var stored domain.Tag
+	require.NoError(t, database.First(&stored, child.ID).Error)
+	assert.Equal(t, &parent.ID, stored.ParentID)
+	var result domain.Media
+	require.NoError(t, database.Preload("Tags").First(&result, media.ID).Error)
+	assert.Len(t, result.Tags, 1)
+}
+
+func Test_TagService_ReplaceAliases_ResolvesInLookups(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	nyc := &domain.Tag{Name: "New York City", Aliases: []*domain.TagAlias{{Name: "big apple"}}}
+	require.NoError(t, service.Create(context.Background(), nyc, &domain.Tag{Name: "Boston"}))
+
+	// Act
+	err := service.ReplaceAliases(context.Background(), nyc, []string{"nyc", "new-york", "nyc"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, nyc.Aliases, 2)
+	for name, expected := range map[string]int{"nyc": 1, "new-york": 1, "New York City": 1, "big apple": 0, "Boston": 1, "unknown": 0} {
+		tags, err := service.Get(context.Background(), service.FilterByNameOption(name))
+		require.NoError(t, err)
+		if assert.Len(t, tags, expected, name) && expected == 1 && name != "Boston" {
+			assert.Equal(t, nyc.ID, tags[0].ID)
+		}
+	}
+}
+
+func Test_TagService_Aliases_RejectNamesInUse(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	nyc := &domain.Tag{Name: "New York City", Aliases: []*domain.TagAlias{{Name: "nyc"}}}
+	boston := &domain.Tag{Name: "Boston"}
+	require.NoError(t, service.Create(context.Background(), nyc, boston))
+
+	testCases := map[string]func() error{
+		"alias used as alias of other tag": func() error {
+			return service.ReplaceAliases(context.Background(), boston, []string{"nyc"})
+		},
+		"alias used as tag name": func() error {
+			return service.ReplaceAliases(context.Background(), boston, []string{"New York City"})
+		},
+		"alias equal to own name": func() error {
+			return service.ReplaceAliases(context.Background(), boston, []string{"Boston"})
+		},
+		"new tag named like alias": func() error {
+			return service.Create(context.Background(), &domain.Tag{Name: "nyc"})
+		},
+		"new tag with alias named like tag": func() error {
+			return service.Create(context.Background(), &domain.Tag{Name: "Chicago", Aliases: []*domain.TagAlias{{Name: "Boston"}}})
+		},
+		"renaming to alias": func() error {
+			renamed := *boston
+			renamed.Name = "nyc"
+			return service.Update(context.Background(), &renamed)
+		},
+	}
+	for name, change := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := change()
+
+			// Assert
+			assert.IsType(t, &apierrors.TagNameConflictError{}, err)
+		})
+	}
+
+	// keeping its own aliases is not a conflict
+	assert.NoError(t, service.ReplaceAliases(context.Background(), nyc, []string{"nyc", "new-york"}))
+}
+
+func Test_TagService_Merge_FoldsSourceIntoTarget(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	target := &domain.Tag{Name: "New York City"}
+	source := &domain.Tag{Name: "nyc", Aliases: []*domain.TagAlias{{Name: "big apple"}}}
+	require.NoError(t, service.Create(context.Background(), target, source))
+	child := &domain.Tag{Name: "Brooklyn", ParentID: &source.ID}
+	require.NoError(t, service.Create(context.Background(), child))
+
+	media := []*domain.Media{
+		{Name: "only source", Tags: []*domain.Tag{source}},
+		{Name: "both", Tags: []*domain.Tag{source, target}},
+		{Name: "only target", Tags: []*domain.Tag{target}},
+	}
+	require.NoError(t, database.Omit("Tags.*").Create(&media).Error)
+
+	// Act
+	err := service.Merge(context.Background(), source, target)
+
+	// Assert
+	require.NoError(t, err)
+
+	var sourceCount int64
+	database.Model(&domain.Tag{}).Where("id = ?", source.ID).Count(&sourceCount)
+	assert.Equal(t, int64(0), sourceCount)
+
+	for _, item := range media {
+		var result domain.Media
+		require.NoError(t, database.Preload("Tags").First(&result, item.ID).Error)
+		if assert.Len(t, result.Tags, 1, item.Name) {
+			assert.Equal(t, target.ID, result.Tags[0].ID)
+		}
+	}
+
+	merged, err := service.GetWithID(context.Background(), target.ID)
+	require.NoError(t, err)
+	aliases := []string{}
+	for _, alias := range merged.Aliases {
+		aliases = append(aliases, alias.Name)
+	}
+	assert.ElementsMatch(t, []string{"nyc", "big apple"}, aliases)
+
+	var movedChild domain.Tag
+	require.NoError(t, database.First(&movedChild, child.ID).Error)
+	assert.Equal(t, &target.ID, movedChild.ParentID)
+}
+
+func Test_TagService_Merge_RejectsInvalidTargets(t *testing.T) {
+	// Arrange
+	database := utils.NewInMemoryDatabase(t)
+	service := NewTagService(database)
+	animal, cat, tabby, _ := createTagTree(t, service)
+
+	testCases := map[string]struct {
+		source, target *domain.Tag
+	}{
+		"itself":          {source: cat, target: cat},
+		"direct child":    {source: cat, target: tabby},
+		"deeper children": {source: animal, target: tabby},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := service.Merge(context.Background(), testCase.source, testCase.target)
+
+			// Assert
+			assert.IsType(t, &apierrors.InvalidTagMergeError{}, err)
+			var count int64
+			database.Model(&domain.Tag{}).Count(&count)
+			assert.Equal(t, int64(4), count)
+		})
+	}
+}
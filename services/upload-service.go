@@ -0,0 +1,38 @@
+package services
+
+import (
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// compile time check for the struct implementing the interface
+var _ IUploadService = (*uploadService)(nil)
+
+//go:generate go run go.uber.org/mock/mockgen -source $GOFILE -typed -destination ../generated/mock/services/mock_$GOFILE IUploadService
+type IUploadService interface {
+	IBaseService[domain.Upload]
+	FilterExpiredOption(now time.Time) Option[domain.Upload]
+}
+
+type uploadService struct {
+	baseService[domain.Upload]
+}
+
+func NewUploadService(db *gorm.DB) IUploadService {
+	return &uploadService{
+		baseService: baseService[domain.Upload]{
+			Database: db,
+		},
+	}
+}
+
+// FilterExpiredOption only keeps uploads that expired before the time. SQLite compares times as text, so the time is
+// compared in UTC like the expiry times are stored
+func (service *uploadService) FilterExpiredOption(now time.Time) Option[domain.Upload] {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Lt{Column: clause.Column{Table: clause.CurrentTable, Name: "expires_at"}, Value: now.UTC()})
+	}
+}
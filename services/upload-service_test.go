@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheSandyDave/Media-Tags/domain"
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UploadService_FilterExpiredOption_FilterTests(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	uploads := []*domain.Upload{
+		{Name: "expired", ExpiresAt: now.Add(-time.Minute), Tags: []string{"tag"}},
+		{Name: "active", ExpiresAt: now.Add(time.Minute)},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&uploads).Error)
+	service := NewUploadService(database)
+
+	// Act
+	result, err := service.Get(context.Background(), service.FilterExpiredOption(now))
+
+	// Assert
+	require.NoError(t, err)
+	if assert.Len(t, result, 1) {
+		assert.Equal(t, "expired", result[0].Name)
+		assert.Equal(t, []string{"tag"}, result[0].Tags)
+	}
+}
+
+func Test_UploadService_FilterExpiredOption_ComparesTimesOfOtherZonesInUTC(t *testing.T) {
+	// Arrange
+	now := time.Now().UTC()
+	uploads := []*domain.Upload{
+		{Name: "expired", ExpiresAt: now.Add(-time.Hour)},
+		{Name: "active", ExpiresAt: now.Add(time.Hour)},
+	}
+
+	database := utils.NewInMemoryDatabase(t)
+	require.NoError(t, database.Create(&uploads).Error)
+	service := NewUploadService(database)
+
+	// Act
+	result, err := service.Get(context.Background(), service.FilterExpiredOption(now.In(time.FixedZone("UTC+2", 2*60*60))))
+
+	// Assert
+	require.NoError(t, err)
+	if assert.Len(t, result, 1) {
+		assert.Equal(t, "expired", result[0].Name)
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	TypeLocal = "local"
+	TypeS3    = "s3"
+)
+
+type Config struct {
+	// Type selects the FileStore implementation, either "local" or "s3"
+	Type  string      `yaml:"type"`
+	Local LocalConfig `yaml:"local"`
+	S3    S3Config    `yaml:"s3"`
+}
+
+type LocalConfig struct {
+	Directory string `yaml:"directory"`
+}
+
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	AccessKeyID     string `yaml:"accessKeyId"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+	// Prefix is prepended to every key, allowing several deployments to share a bucket
+	Prefix string `yaml:"prefix"`
+	UseSSL bool   `yaml:"useSSL"`
+}
+
+// New creates the FileStore selected by the configuration
+func New(ctx context.Context, config Config) (FileStore, error) {
+	switch config.Type {
+	case TypeLocal, "":
+		return NewLocalFileStore(config.Local.Directory)
+	case TypeS3:
+		return NewS3FileStore(ctx, config.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", config.Type)
+	}
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrFileNotFound = errors.New("file not found")
+	ErrInvalidKey   = errors.New("invalid file key")
+)
+
+// FileInfo describes a single file in a FileStore
+type FileInfo struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+//go:generate go run go.uber.org/mock/mockgen -source $GOFILE -typed -destination ../generated/mock/storage/mock_$GOFILE FileStore
+
+// FileStore persists uploaded files under slash separated keys, e.g. "abc.png" or "thumbnails/abc.jpg"
+type FileStore interface {
+	// Put stores the content under the given key, replacing any existing file. size may be -1 if unknown
+	Put(ctx context.Context, key string, content io.Reader, size int64) error
+	// Get opens the file stored under the given key, the caller is responsible for closing it
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Stat(ctx context.Context, key string) (*FileInfo, error)
+	// Move renames the file stored under from to the key to, replacing any existing file
+	Move(ctx context.Context, from string, to string) error
+	// Delete removes the file stored under the given key, deleting a file that does not exist is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns all files whose key starts with the given prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]*FileInfo, error)
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// every FileStore implementation is expected to behave identically
+func fileStoreImplementations(t *testing.T) map[string]FileStore {
+	t.Helper()
+
+	local, err := NewLocalFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	return map[string]FileStore{
+		"local":  local,
+		"memory": NewMemoryFileStore(),
+		"s3":     newFakeS3FileStore(t),
+	}
+}
+
+func Test_FileStore_Put_StoresRetrievableContent(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			expectedContent := []byte("some file content")
+
+			// Act
+			err := store.Put(ctx, "nested/file.txt", bytes.NewReader(expectedContent), int64(len(expectedContent)))
+
+			// Assert
+			require.NoError(t, err)
+			file, err := store.Get(ctx, "nested/file.txt")
+			require.NoError(t, err)
+			defer file.Close()
+			content, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedContent, content)
+		})
+	}
+}
+
+func Test_FileStore_Put_AcceptsUnknownSize(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			expectedContent := "content of unknown length"
+
+			// Act
+			err := store.Put(ctx, "file.txt", strings.NewReader(expectedContent), -1)
+
+			// Assert
+			require.NoError(t, err)
+			info, err := store.Stat(ctx, "file.txt")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(expectedContent)), info.Size)
+		})
+	}
+}
+
+func Test_FileStore_Get_SupportsSeeking(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			require.NoError(t, store.Put(ctx, "file.txt", strings.NewReader("0123456789"), 10))
+
+			// Act
+			file, err := store.Get(ctx, "file.txt")
+			require.NoError(t, err)
+			defer file.Close()
+			_, err = file.Seek(6, io.SeekStart)
+
+			// Assert
+			require.NoError(t, err)
+			content, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, "6789", string(content))
+		})
+	}
+}
+
+func Test_FileStore_Get_FailsIfFileDoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := store.Get(context.Background(), "missing.txt")
+
+			// Assert
+			assert.ErrorIs(t, err, ErrFileNotFound)
+		})
+	}
+}
+
+func Test_FileStore_Stat_ReturnsFileInfo(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			require.NoError(t, store.Put(ctx, "file.txt", strings.NewReader("12345"), 5))
+
+			// Act
+			info, err := store.Stat(ctx, "file.txt")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "file.txt", info.Key)
+			assert.Equal(t, int64(5), info.Size)
+			assert.False(t, info.ModifiedAt.IsZero())
+
+			_, err = store.Stat(ctx, "missing.txt")
+			assert.ErrorIs(t, err, ErrFileNotFound)
+		})
+	}
+}
+
+func Test_FileStore_Move_RenamesFile(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			require.NoError(t, store.Put(ctx, "uploads/file.part", strings.NewReader("content"), 7))
+
+			// Act
+			err := store.Move(ctx, "uploads/file.part", "nested/file.txt")
+
+			// Assert
+			require.NoError(t, err)
+			_, err = store.Stat(ctx, "uploads/file.part")
+			assert.ErrorIs(t, err, ErrFileNotFound)
+			file, err := store.Get(ctx, "nested/file.txt")
+			require.NoError(t, err)
+			defer file.Close()
+			content, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, "content", string(content))
+		})
+	}
+}
+
+func Test_FileStore_Move_FailsIfFileDoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := store.Move(context.Background(), "missing.txt", "file.txt")
+
+			// Assert
+			assert.ErrorIs(t, err, ErrFileNotFound)
+		})
+	}
+}
+
+func Test_FileStore_Delete_RemovesFile(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			require.NoError(t, store.Put(ctx, "file.txt", strings.NewReader("content"), 7))
+
+			// Act
+			err := store.Delete(ctx, "file.txt")
+
+			// Assert
+			assert.NoError(t, err)
+			_, err = store.Stat(ctx, "file.txt")
+			assert.ErrorIs(t, err, ErrFileNotFound)
+			assert.NoError(t, store.Delete(ctx, "file.txt"), "deleting a missing file should not fail")
+		})
+	}
+}
+
+func Test_FileStore_List_ReturnsFilesMatchingPrefix(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			for _, key := range []string{"b.txt", "thumbs/b.jpg", "a.txt", "thumbs/a.jpg"} {
+				require.NoError(t, store.Put(ctx, key, strings.NewReader(key), int64(len(key))))
+			}
+
+			// Act
+			all, err := store.List(ctx, "")
+			require.NoError(t, err)
+			thumbs, err := store.List(ctx, "thumbs/")
+			require.NoError(t, err)
+
+			// Assert
+			keys := func(infos []*FileInfo) []string {
+				result := make([]string, len(infos))
+				for i, info := range infos {
+					result[i] = info.Key
+				}
+				return result
+			}
+			assert.Equal(t, []string{"a.txt", "b.txt", "thumbs/a.jpg", "thumbs/b.jpg"}, keys(all))
+			assert.Equal(t, []string{"thumbs/a.jpg", "thumbs/b.jpg"}, keys(thumbs))
+		})
+	}
+}
+
+func Test_FileStore_RejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range fileStoreImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			invalidKeys := []string{"", "../escape.txt", "/absolute.txt", "nested/../../escape.txt", ".upload-123", "nested/.upload-123"}
+			for _, key := range invalidKeys {
+				// Act
+				putErr := store.Put(context.Background(), key, strings.NewReader("content"), 7)
+				_, getErr := store.Get(context.Background(), key)
+				_, statErr := store.Stat(context.Background(), key)
+
+				// Assert
+				assert.ErrorIs(t, putErr, ErrInvalidKey, key)
+				assert.ErrorIs(t, getErr, ErrInvalidKey, key)
+				assert.ErrorIs(t, statErr, ErrInvalidKey, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TheSandyDave/Media-Tags/utils"
+)
+
+// compile time check for the struct implementing the interface
+var _ FileStore = (*localFileStore)(nil)
+
+// files that are still being written are prefixed so they are never listed or served
+const temporaryFilePrefix = ".upload-"
+
+type localFileStore struct {
+	directory string
+}
+
+func NewLocalFileStore(directory string) (FileStore, error) {
+	if directory == "" {
+		return nil, errors.New("local storage directory is required")
+	}
+
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &localFileStore{
+		directory: directory,
+	}, nil
+}
+
+func (store *localFileStore) path(key string) (string, error) {
+	if !isValidKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(store.directory, filepath.FromSlash(key)), nil
+}
+
+func (store *localFileStore) Put(ctx context.Context, key string, content io.Reader, _ int64) error {
+	logger := utils.NewLogger(ctx)
+
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never observe a partially written file
+	file, err := os.CreateTemp(filepath.Dir(path), temporaryFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		logger.WithField("key", key).WithError(err).Error("failed writing file")
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (store *localFileStore) Get(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+
+	return file, err
+}
+
+func (store *localFileStore) Stat(_ context.Context, key string) (*FileInfo, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileInfo{
+		Key:        key,
+		Size:       info.Size(),
+		ModifiedAt: info.ModTime(),
+	}, nil
+}
+
+func (store *localFileStore) Move(_ context.Context, from string, to string) error {
+	fromPath, err := store.path(from)
+	if err != nil {
+		return err
+	}
+	toPath, err := store.path(to)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(fromPath); errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return ErrFileNotFound
+	}
+
+	if err := os.MkdirAll(filepath.Dir(toPath), 0o755); err != nil {
+		return err
+	}
+
+	return os.Rename(fromPath, toPath)
+}
+
+func (store *localFileStore) Delete(_ context.Context, key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (store *localFileStore) List(_ context.Context, prefix string) ([]*FileInfo, error) {
+	var result []*FileInfo
+
+	err := filepath.WalkDir(store.directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), temporaryFilePrefix) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(store.directory, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		result = append(result, &FileInfo{
+			Key:        key,
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+// isValidKey rejects empty keys, absolute keys, keys escaping the storage root and keys of files still being written
+func isValidKey(key string) bool {
+	if key == "." || !fs.ValidPath(key) || strings.Contains(key, `\`) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if strings.HasPrefix(segment, temporaryFilePrefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// compile time check for the struct implementing the interface
+var _ FileStore = (*MemoryFileStore)(nil)
+
+// MemoryFileStore keeps files in memory, it is intended for tests which need to inspect stored files
+type MemoryFileStore struct {
+	lock  sync.RWMutex
+	files map[string]memoryFile
+}
+
+type memoryFile struct {
+	content    []byte
+	modifiedAt time.Time
+}
+
+type memoryFileReader struct {
+	*bytes.Reader
+}
+
+func (memoryFileReader) Close() error {
+	return nil
+}
+
+func NewMemoryFileStore() *MemoryFileStore {
+	return &MemoryFileStore{
+		files: map[string]memoryFile{},
+	}
+}
+
+func (store *MemoryFileStore) Put(_ context.Context, key string, content io.Reader, _ int64) error {
+	if !isValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.files[key] = memoryFile{
+		content:    data,
+		modifiedAt: time.Now(),
+	}
+
+	return nil
+}
+
+func (store *MemoryFileStore) Get(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	if !isValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	file, ok := store.files[key]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+
+	return memoryFileReader{bytes.NewReader(file.content)}, nil
+}
+
+func (store *MemoryFileStore) Stat(_ context.Context, key string) (*FileInfo, error) {
+	if !isValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	file, ok := store.files[key]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+
+	return &FileInfo{
+		Key:        key,
+		Size:       int64(len(file.content)),
+		ModifiedAt: file.modifiedAt,
+	}, nil
+}
+
+func (store *MemoryFileStore) Move(_ context.Context, from string, to string) error {
+	if !isValidKey(from) || !isValidKey(to) {
+		return ErrInvalidKey
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	file, ok := store.files[from]
+	if !ok {
+		return ErrFileNotFound
+	}
+	delete(store.files, from)
+	store.files[to] = file
+
+	return nil
+}
+
+func (store *MemoryFileStore) Delete(_ context.Context, key string) error {
+	if !isValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.files, key)
+
+	return nil
+}
+
+func (store *MemoryFileStore) List(_ context.Context, prefix string) ([]*FileInfo, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	var result []*FileInfo
+	for key, file := range store.files {
+		if strings.HasPrefix(key, prefix) {
+			result = append(result, &FileInfo{
+				Key:        key,
+				Size:       int64(len(file.content)),
+				ModifiedAt: file.modifiedAt,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+// Keys returns the keys of all stored files, sorted
+func (store *MemoryFileStore) Keys() []string {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	keys := make([]string, 0, len(store.files))
+	for key := range store.files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Content returns the content stored under the key, or nil if no such file exists
+func (store *MemoryFileStore) Content(key string) []byte {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	return store.files[key].content
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-process stand-in for an S3 compatible server, supporting just enough of the API for the s3FileStore
+type fakeS3 struct {
+	bucket string
+
+	lock      sync.Mutex
+	objects   map[string]fakeS3Object
+	uploads   map[string]map[int][]byte
+	partSizes []int
+	// reportMissingOnDelete answers deletes of missing objects with NoSuchKey like some S3 compatible servers do
+	reportMissingOnDelete bool
+}
+
+type fakeS3Object struct {
+	content    []byte
+	modifiedAt time.Time
+}
+
+func newFakeS3FileStore(t *testing.T) FileStore {
+	t.Helper()
+
+	store, _ := newFakeS3(t)
+	return store
+}
+
+// newFakeS3 returns a store on a new fake server along with the server, to inspect the requests it received
+func newFakeS3(t *testing.T) (FileStore, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{
+		bucket:  "media",
+		objects: map[string]fakeS3Object{},
+		uploads: map[string]map[int][]byte{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3FileStore(context.Background(), S3Config{
+		Endpoint:        strings.TrimPrefix(server.URL, "http://"),
+		Region:          "us-east-1",
+		Bucket:          fake.bucket,
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		Prefix:          "test",
+	})
+	require.NoError(t, err)
+
+	return store, fake
+}
+
+func (fake *fakeS3) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(request.URL.Path, "/"), "/")
+	if bucket != fake.bucket {
+		fake.writeError(writer, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	query := request.URL.Query()
+	switch {
+	case key == "" && request.Method == http.MethodHead:
+		writer.WriteHeader(http.StatusOK)
+	case key == "" && query.Has("location"):
+		fake.writeXML(writer, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+		}{})
+	case key == "" && request.Method == http.MethodGet:
+		fake.list(writer, query.Get("prefix"))
+	case request.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := strconv.Itoa(len(fake.uploads) + 1)
+		fake.uploads[uploadID] = map[int][]byte{}
+		fake.writeXML(writer, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: uploadID})
+	case request.Method == http.MethodPut && query.Has("uploadId"):
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		fake.uploads[query.Get("uploadId")][partNumber] = fake.readBody(request)
+		fake.partSizes = append(fake.partSizes, len(fake.uploads[query.Get("uploadId")][partNumber]))
+		writer.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, partNumber))
+	case request.Method == http.MethodPost && query.Has("uploadId"):
+		parts := fake.uploads[query.Get("uploadId")]
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var content []byte
+		for _, number := range numbers {
+			content = append(content, parts[number]...)
+		}
+		fake.objects[key] = fakeS3Object{content: content, modifiedAt: time.Now()}
+		delete(fake.uploads, query.Get("uploadId"))
+		fake.writeXML(writer, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: `"complete"`})
+	case request.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(fake.uploads, query.Get("uploadId"))
+		writer.WriteHeader(http.StatusNoContent)
+	case request.Method == http.MethodPut && request.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(request.Header.Get("X-Amz-Copy-Source"))
+		_, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+		object, ok := fake.objects[sourceKey]
+		if !ok {
+			fake.writeError(writer, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		fake.objects[key] = fakeS3Object{content: object.content, modifiedAt: time.Now()}
+		fake.writeXML(writer, struct {
+			XMLName      xml.Name `xml:"CopyObjectResult"`
+			ETag         string
+			LastModified string
+		}{ETag: `"object"`, LastModified: time.Now().UTC().Format(time.RFC3339)})
+	case request.Method == http.MethodPut:
+		fake.objects[key] = fakeS3Object{content: fake.readBody(request), modifiedAt: time.Now()}
+		writer.Header().Set("ETag", `"object"`)
+	case request.Method == http.MethodGet || request.Method == http.MethodHead:
+		object, ok := fake.objects[key]
+		if !ok {
+			fake.writeError(writer, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		writer.Header().Set("ETag", `"object"`)
+		http.ServeContent(writer, request, key, object.modifiedAt, bytes.NewReader(object.content))
+	case request.Method == http.MethodDelete:
+		if _, ok := fake.objects[key]; !ok && fake.reportMissingOnDelete {
+			fake.writeError(writer, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		delete(fake.objects, key)
+		writer.WriteHeader(http.StatusNoContent)
+	default:
+		fake.writeError(writer, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (fake *fakeS3) list(writer http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		Size         int64
+		LastModified string
+		ETag         string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Name: fake.bucket, Prefix: prefix}
+
+	for key, object := range fake.objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{
+				Key:          key,
+				Size:         int64(len(object.content)),
+				LastModified: object.modifiedAt.UTC().Format(time.RFC3339),
+				ETag:         `"object"`,
+			})
+		}
+	}
+	result.KeyCount = len(result.Contents)
+
+	fake.writeXML(writer, result)
+}
+
+// readBody decodes aws-chunked bodies, which the client uses to stream uploads over plain HTTP
+func (fake *fakeS3) readBody(request *http.Request) []byte {
+	if !strings.HasPrefix(request.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		body, _ := io.ReadAll(request.Body)
+		return body
+	}
+
+	var body []byte
+	reader := bufio.NewReader(request.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return body
+		}
+		sizeField, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeField, 16, 64)
+		if err != nil || size == 0 {
+			return body
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return body
+		}
+		body = append(body, chunk...)
+		// skip the CRLF terminating the chunk
+		reader.ReadString('\n')
+	}
+}
+
+func (fake *fakeS3) writeXML(writer http.ResponseWriter, value any) {
+	writer.Header().Set("Content-Type", "application/xml")
+	writer.WriteHeader(http.StatusOK)
+	xml.NewEncoder(writer).Encode(value)
+}
+
+func (fake *fakeS3) writeError(writer http.ResponseWriter, status int, code string) {
+	writer.Header().Set("Content-Type", "application/xml")
+	writer.WriteHeader(status)
+	xml.NewEncoder(writer).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: url.QueryEscape(code)})
+}
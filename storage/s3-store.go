@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/TheSandyDave/Media-Tags/utils"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// compile time check for the struct implementing the interface
+var _ FileStore = (*s3FileStore)(nil)
+
+// unknownSizePartSize is the size of the parts content of unknown size is uploaded in. The client buffers a whole part
+// in memory and would otherwise pick parts large enough for the maximum object size of 5 TiB, over 500 MiB each. With
+// the at most 10000 parts of a multipart upload this still allows objects of 160 GiB
+const unknownSizePartSize = 16 << 20
+
+// s3FileStore stores files in a bucket of any S3 compatible object storage
+type s3FileStore struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3FileStore(ctx context.Context, config S3Config) (FileStore, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("s3 endpoint is required")
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed checking s3 bucket %q: %w", config.Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("s3 bucket %q does not exist", config.Bucket)
+	}
+
+	prefix := strings.Trim(config.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &s3FileStore{
+		client: client,
+		bucket: config.Bucket,
+		prefix: prefix,
+	}, nil
+}
+
+func (store *s3FileStore) objectName(key string) (string, error) {
+	if !isValidKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	return store.prefix + key, nil
+}
+
+func (store *s3FileStore) Put(ctx context.Context, key string, content io.Reader, size int64) error {
+	logger := utils.NewLogger(ctx)
+
+	objectName, err := store.objectName(key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.client.PutObject(ctx, store.bucket, objectName, content, size, putObjectOptions(size)); err != nil {
+		logger.WithField("key", key).WithError(err).Error("failed uploading object")
+		return err
+	}
+
+	return nil
+}
+
+// putObjectOptions returns the options to upload content of the size with, a negative size being unknown
+func putObjectOptions(size int64) minio.PutObjectOptions {
+	if size < 0 {
+		return minio.PutObjectOptions{PartSize: unknownSizePartSize}
+	}
+
+	return minio.PutObjectOptions{}
+}
+
+func (store *s3FileStore) Get(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	objectName, err := store.objectName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := store.client.GetObject(ctx, store.bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, convertS3Error(err)
+	}
+
+	// GetObject is lazy, stat the object so missing files are reported here rather than on first read
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, convertS3Error(err)
+	}
+
+	return object, nil
+}
+
+func (store *s3FileStore) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	objectName, err := store.objectName(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := store.client.StatObject(ctx, store.bucket, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, convertS3Error(err)
+	}
+
+	return &FileInfo{
+		Key:        key,
+		Size:       info.Size,
+		ModifiedAt: info.LastModified,
+	}, nil
+}
+
+// Move copies the object on the server and removes the original, S3 has no way of renaming objects
+func (store *s3FileStore) Move(ctx context.Context, from string, to string) error {
+	logger := utils.NewLogger(ctx)
+
+	fromName, err := store.objectName(from)
+	if err != nil {
+		return err
+	}
+	toName, err := store.objectName(to)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: store.bucket, Object: toName},
+		minio.CopySrcOptions{Bucket: store.bucket, Object: fromName},
+	)
+	if err != nil {
+		logger.WithField("key", from).WithError(err).Error("failed copying object")
+		return convertS3Error(err)
+	}
+
+	if err := store.client.RemoveObject(ctx, store.bucket, fromName, minio.RemoveObjectOptions{}); err != nil {
+		logger.WithField("key", from).WithError(err).Error("failed removing moved object")
+		return convertS3Error(err)
+	}
+
+	return nil
+}
+
+func (store *s3FileStore) Delete(ctx context.Context, key string) error {
+	objectName, err := store.objectName(key)
+	if err != nil {
+		return err
+	}
+
+	return convertS3Error(store.client.RemoveObject(ctx, store.bucket, objectName, minio.RemoveObjectOptions{}))
+}
+
+func (store *s3FileStore) List(ctx context.Context, prefix string) ([]*FileInfo, error) {
+	var result []*FileInfo
+
+	objects := store.client.ListObjects(ctx, store.bucket, minio.ListObjectsOptions{
+		Prefix:    store.prefix + prefix,
+		Recursive: true,
+	})
+	for object := range objects {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+
+		result = append(result, &FileInfo{
+			Key:        strings.TrimPrefix(object.Key, store.prefix),
+			Size:       object.Size,
+			ModifiedAt: object.LastModified,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+func convertS3Error(err error) error {
+	response := minio.ToErrorResponse(err)
+	if response.StatusCode == http.StatusNotFound || response.Code == "NoSuchKey" {
+		return ErrFileNotFound
+	}
+
+	return err
+}
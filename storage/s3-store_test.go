@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_putObjectOptions_LimitsPartsOfUnknownSize(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		size            int64
+		expectedOptions minio.PutObjectOptions
+	}{
+		"unknown size": {size: -1, expectedOptions: minio.PutObjectOptions{PartSize: 16 << 20}},
+		"empty":        {size: 0, expectedOptions: minio.PutObjectOptions{}},
+		"known size":   {size: 1 << 30, expectedOptions: minio.PutObjectOptions{}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			options := putObjectOptions(testCase.size)
+
+			// Assert
+			assert.Equal(t, testCase.expectedOptions, options)
+		})
+	}
+}
+
+func Test_S3FileStore_Put_UploadsUnknownSizeInSmallParts(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx := context.Background()
+	store, fake := newFakeS3(t)
+	expectedContent := bytes.Repeat([]byte{1}, unknownSizePartSize+1)
+
+	// Act
+	err := store.Put(ctx, "file", io.MultiReader(bytes.NewReader(expectedContent)), -1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{unknownSizePartSize, 1}, fake.partSizes)
+	file, err := store.Get(ctx, "file")
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedContent, content)
+}
+
+func Test_S3FileStore_Delete_ConvertsErrors(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	store, fake := newFakeS3(t)
+	fake.reportMissingOnDelete = true
+
+	// Act
+	err := store.Delete(context.Background(), "missing")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
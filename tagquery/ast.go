@@ -0,0 +1,84 @@
+// Package tagquery parses boolean tag expressions like `cat AND (outdoor OR garden) AND NOT blurry`
+package tagquery
+
+import "strings"
+
+// Node is an element of a parsed tag query
+type Node interface {
+	// String formats the node as a fully parenthesized query which parses back into the same tree
+	String() string
+}
+
+// Tag matches media having the tag with the name
+type Tag struct {
+	Name string
+}
+
+// And matches media matched by both sides
+type And struct {
+	Left, Right Node
+}
+
+// Or matches media matched by either side
+type Or struct {
+	Left, Right Node
+}
+
+// Not matches media not matched by the operand
+type Not struct {
+	Operand Node
+}
+
+func (node *Tag) String() string {
+	if isBareWord(node.Name) {
+		return node.Name
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(node.Name) + `"`
+}
+
+func (node *And) String() string {
+	return "(" + node.Left.String() + " AND " + node.Right.String() + ")"
+}
+
+func (node *Or) String() string {
+	return "(" + node.Left.String() + " OR " + node.Right.String() + ")"
+}
+
+func (node *Not) String() string {
+	return "NOT " + node.Operand.String()
+}
+
+// Tags returns the distinct tag names used in the query in order of appearance
+func Tags(node Node) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	var walk func(node Node)
+	walk = func(node Node) {
+		switch node := node.(type) {
+		case *Tag:
+			if !seen[node.Name] {
+				seen[node.Name] = true
+				names = append(names, node.Name)
+			}
+		case *And:
+			walk(node.Left)
+			walk(node.Right)
+		case *Or:
+			walk(node.Left)
+			walk(node.Right)
+		case *Not:
+			walk(node.Operand)
+		}
+	}
+	walk(node)
+
+	return names
+}
+
+func isBareWord(name string) bool {
+	if name == "" || keyword(name) != tokenTag {
+		return false
+	}
+	return !strings.ContainsFunc(name, isDelimiter)
+}
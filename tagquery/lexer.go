@@ -0,0 +1,117 @@
+package tagquery
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenTag
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+func (tokenType tokenType) String() string {
+	switch tokenType {
+	case tokenEOF:
+		return "end of query"
+	case tokenTag:
+		return "tag"
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	case tokenOpen:
+		return "'('"
+	case tokenClose:
+		return "')'"
+	}
+	return "unknown token"
+}
+
+type token struct {
+	tokenType tokenType
+	value     string
+	// position is the 1-based character position of the token in the query
+	position int
+}
+
+// tokenize splits the query into tokens, tags are either bare words or double quoted strings with \" and \\ escapes
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		position := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenType: tokenOpen, position: position})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenType: tokenClose, position: position})
+			i++
+		case r == '"':
+			var value strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, newSyntaxError(position, "unterminated quoted tag")
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				if runes[i] == '\\' {
+					if i+1 >= len(runes) || (runes[i+1] != '"' && runes[i+1] != '\\') {
+						return nil, newSyntaxError(i+1, `invalid escape, only \" and \\ are allowed`)
+					}
+					i++
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if value.Len() == 0 {
+				return nil, newSyntaxError(position, "empty quoted tag")
+			}
+			tokens = append(tokens, token{tokenType: tokenTag, value: value.String(), position: position})
+		default:
+			start := i
+			for i < len(runes) && !isDelimiter(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			tokens = append(tokens, token{tokenType: keyword(word), value: word, position: position})
+		}
+	}
+
+	return append(tokens, token{tokenType: tokenEOF, position: len(runes) + 1}), nil
+}
+
+// keyword returns the operator the word stands for, operators are case insensitive
+func keyword(word string) tokenType {
+	switch strings.ToUpper(word) {
+	case "AND":
+		return tokenAnd
+	case "OR":
+		return tokenOr
+	case "NOT":
+		return tokenNot
+	}
+	return tokenTag
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
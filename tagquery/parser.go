@@ -0,0 +1,182 @@
+package tagquery
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// MaxLength is the maximum number of characters in a query
+	MaxLength = 1000
+	// MaxDepth is the maximum nesting of parentheses and NOT operators
+	MaxDepth = 32
+)
+
+// SyntaxError describes why and where a query could not be parsed
+type SyntaxError struct {
+	// Position is the 1-based character position of the error in the query
+	Position int
+	Message  string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", err.Position, err.Message)
+}
+
+func newSyntaxError(position int, message string) error {
+	return &SyntaxError{
+		Position: position,
+		Message:  message,
+	}
+}
+
+// Parse parses a query, NOT binds tighter than AND which binds tighter than OR
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = unary { "AND" unary }
+//	unary   = "NOT" unary | primary
+//	primary = tag | "(" or ")"
+func Parse(query string) (Node, error) {
+	if length := utf8.RuneCountInString(query); length > MaxLength {
+		return nil, newSyntaxError(MaxLength+1, fmt.Sprintf("query is longer than %d characters", MaxLength))
+	}
+
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &parser{tokens: tokens}
+	if parser.peek().tokenType == tokenEOF {
+		return nil, newSyntaxError(1, "query is empty")
+	}
+
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := parser.peek(); next.tokenType != tokenEOF {
+		return nil, parser.unexpected(next, "expected AND, OR or end of query")
+	}
+
+	return node, nil
+}
+
+type parser struct {
+	tokens   []token
+	position int
+	depth    int
+}
+
+func (parser *parser) peek() token {
+	return parser.tokens[parser.position]
+}
+
+func (parser *parser) next() token {
+	token := parser.tokens[parser.position]
+	if token.tokenType != tokenEOF {
+		parser.position++
+	}
+	return token
+}
+
+func (parser *parser) parseOr() (Node, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.peek().tokenType == tokenOr {
+		parser.next()
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (parser *parser) parseAnd() (Node, error) {
+	left, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.peek().tokenType == tokenAnd {
+		parser.next()
+		right, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (parser *parser) parseUnary() (Node, error) {
+	if parser.peek().tokenType != tokenNot {
+		return parser.parsePrimary()
+	}
+
+	not := parser.next()
+	if err := parser.enter(not); err != nil {
+		return nil, err
+	}
+	defer parser.leave()
+
+	operand, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Not{Operand: operand}, nil
+}
+
+func (parser *parser) parsePrimary() (Node, error) {
+	token := parser.next()
+
+	switch token.tokenType {
+	case tokenTag:
+		return &Tag{Name: token.value}, nil
+	case tokenOpen:
+		if err := parser.enter(token); err != nil {
+			return nil, err
+		}
+		defer parser.leave()
+
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := parser.next(); closing.tokenType != tokenClose {
+			return nil, parser.unexpected(closing, fmt.Sprintf("expected ')' to close '(' at position %d", token.position))
+		}
+		return node, nil
+	}
+
+	return nil, parser.unexpected(token, "expected a tag, NOT or '('")
+}
+
+func (parser *parser) enter(token token) error {
+	parser.depth++
+	if parser.depth > MaxDepth {
+		return newSyntaxError(token.position, fmt.Sprintf("query is nested deeper than %d levels", MaxDepth))
+	}
+	return nil
+}
+
+func (parser *parser) leave() {
+	parser.depth--
+}
+
+func (parser *parser) unexpected(token token, expectation string) error {
+	found := token.tokenType.String()
+	if token.tokenType == tokenTag {
+		found = fmt.Sprintf("tag %q", token.value)
+	}
+	return newSyntaxError(token.position, fmt.Sprintf("unexpected %s, %s", found, expectation))
+}
@@ -0,0 +1,102 @@
+package tagquery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_BuildsTree(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query    string
+		expected string
+	}{
+		"single tag":                {query: "cat", expected: "cat"},
+		"and binds tighter than or": {query: "a OR b AND c", expected: "(a OR (b AND c))"},
+		"not binds tightest":        {query: "NOT a AND b", expected: "(NOT a AND b)"},
+		"left associative":          {query: "a AND b AND c", expected: "((a AND b) AND c)"},
+		"parentheses":               {query: "cat AND (outdoor OR garden) AND NOT blurry", expected: "((cat AND (outdoor OR garden)) AND NOT blurry)"},
+		"case insensitive keywords": {query: "a and not b or c", expected: "((a AND NOT b) OR c)"},
+		"double negation":           {query: "NOT NOT a", expected: "NOT NOT a"},
+		"quoted tag":                {query: `"Champions League" OR "and"`, expected: `("Champions League" OR "and")`},
+		"escapes in quoted tag":     {query: `"say \"hi\" \\ bye"`, expected: `"say \"hi\" \\ bye"`},
+		"no spaces around parens":   {query: "(a)AND(b)", expected: "(a AND b)"},
+		"tag with special chars":    {query: "animal/cat:tabby-2", expected: "animal/cat:tabby-2"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			node, err := Parse(testCase.query)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, node.String())
+
+			reparsed, err := Parse(node.String())
+			require.NoError(t, err)
+			assert.Equal(t, node, reparsed)
+		})
+	}
+}
+
+func Test_Parse_ReportsSyntaxErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query    string
+		position int
+		message  string
+	}{
+		"empty":                 {query: "  ", position: 1, message: "query is empty"},
+		"missing operand":       {query: "cat AND", position: 8, message: "unexpected end of query, expected a tag, NOT or '('"},
+		"missing operator":      {query: "cat dog", position: 5, message: `unexpected tag "dog", expected AND, OR or end of query`},
+		"leading operator":      {query: "OR cat", position: 1, message: "unexpected OR, expected a tag, NOT or '('"},
+		"unclosed paren":        {query: "(cat OR dog", position: 12, message: "unexpected end of query, expected ')' to close '(' at position 1"},
+		"unbalanced paren":      {query: "cat)", position: 4, message: "unexpected ')', expected AND, OR or end of query"},
+		"empty parens":          {query: "cat AND ()", position: 10, message: "unexpected ')', expected a tag, NOT or '('"},
+		"unterminated quote":    {query: `cat AND "dog`, position: 9, message: "unterminated quoted tag"},
+		"empty quote":           {query: `""`, position: 1, message: "empty quoted tag"},
+		"invalid escape":        {query: `"a\nb"`, position: 3, message: `invalid escape, only \" and \\ are allowed`},
+		"too deeply nested":     {query: strings.Repeat("(", MaxDepth+1) + "a" + strings.Repeat(")", MaxDepth+1), position: MaxDepth + 1, message: "query is nested deeper than 32 levels"},
+		"too long":              {query: strings.Repeat("a", MaxLength+1), position: MaxLength + 1, message: "query is longer than 1000 characters"},
+		"position counts runes": {query: "chat_été dog", position: 10, message: `unexpected tag "dog", expected AND, OR or end of query`},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			node, err := Parse(testCase.query)
+
+			// Assert
+			assert.Nil(t, node)
+			var syntaxError *SyntaxError
+			if assert.ErrorAs(t, err, &syntaxError) {
+				assert.Equal(t, testCase.position, syntaxError.Position)
+				assert.Equal(t, testCase.message, syntaxError.Message)
+			}
+		})
+	}
+}
+
+func Test_Tags_ReturnsDistinctNamesInOrder(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	node, err := Parse("b AND (a OR NOT b) AND c")
+	require.NoError(t, err)
+
+	// Act
+	names := Tags(node)
+
+	// Assert
+	assert.Equal(t, []string{"b", "a", "c"}, names)
+}
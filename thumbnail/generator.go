@@ -0,0 +1,198 @@
+// Package thumbnail generates downscaled previews and arbitrary renditions of uploaded images using pure Go decoders
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("thumbnails can't be generated for the type")
+	ErrTooManyPixels   = errors.New("image has too many pixels to generate thumbnails")
+)
+
+// decoder decodes either the dimensions or the complete image of a single format
+type decoder struct {
+	decodeConfig func(io.Reader) (image.Config, error)
+	decode       func(io.Reader) (image.Image, error)
+}
+
+var decoders = map[string]decoder{
+	"image/jpeg": {jpeg.DecodeConfig, jpeg.Decode},
+	"image/png":  {png.DecodeConfig, png.Decode},
+	// only the first frame of an animation is used
+	"image/gif":  {gif.DecodeConfig, gif.Decode},
+	"image/webp": {webp.DecodeConfig, webp.Decode},
+}
+
+// Generator creates a thumbnail fitting within each of its sizes
+type Generator struct {
+	// Sizes are the maximum width and height in pixels of every thumbnail
+	Sizes []int
+	// Quality of JPEG encoded thumbnails, from 1 to 100
+	Quality int
+	// MaxSourcePixels protects against decompression bombs by refusing to decode larger images, 0 means no limit
+	MaxSourcePixels int64
+	// WebP enables decoding WebP images
+	WebP bool
+}
+
+// Encoded is an encoded image
+type Encoded struct {
+	Width       int
+	Height      int
+	ContentType string
+	// Extension of the encoded format including the dot
+	Extension string
+	Content   []byte
+}
+
+// Thumbnail is a single encoded thumbnail, JPEG for opaque images and PNG for images with transparency
+type Thumbnail struct {
+	// Size is the size of the Generator the thumbnail fits within
+	Size int
+	Encoded
+}
+
+// Supports reports whether thumbnails are generated for content of the MIME type
+func (generator Generator) Supports(mimeType string) bool {
+	return len(generator.Sizes) > 0 && generator.Decodes(mimeType)
+}
+
+// Decodes reports whether content of the MIME type can be decoded
+func (generator Generator) Decodes(mimeType string) bool {
+	if mimeType == "image/webp" && !generator.WebP {
+		return false
+	}
+
+	_, ok := decoders[mimeType]
+	return ok
+}
+
+// Generate decodes the content and creates a thumbnail for every size, images are never scaled up so a thumbnail
+// of a small image has the dimensions of the image itself
+func (generator Generator) Generate(content io.Reader, mimeType string) ([]*Thumbnail, error) {
+	if !generator.Supports(mimeType) {
+		return nil, ErrUnsupportedType
+	}
+
+	source, err := generator.Decode(content, mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails := make([]*Thumbnail, 0, len(generator.Sizes))
+	for _, size := range generator.Sizes {
+		bounds := source.Bounds()
+		width, height := fit(bounds.Dx(), bounds.Dy(), size)
+
+		encoded, err := generator.encode(scale(source, bounds, width, height), FormatAuto)
+		if err != nil {
+			return nil, err
+		}
+		thumbnails = append(thumbnails, &Thumbnail{Size: size, Encoded: *encoded})
+	}
+
+	return thumbnails, nil
+}
+
+// Decode decodes the content, failing with ErrTooManyPixels for images larger than MaxSourcePixels
+func (generator Generator) Decode(content io.Reader, mimeType string) (image.Image, error) {
+	if !generator.Decodes(mimeType) {
+		return nil, ErrUnsupportedType
+	}
+	decoder := decoders[mimeType]
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the dimensions before decoding, a small file can describe an enormous image
+	config, err := decoder.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding image dimensions: %w", err)
+	}
+	if generator.MaxSourcePixels > 0 && int64(config.Width)*int64(config.Height) > generator.MaxSourcePixels {
+		return nil, ErrTooManyPixels
+	}
+
+	source, err := decoder.decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding image: %w", err)
+	}
+
+	return source, nil
+}
+
+// scale draws the area of the source to a new image of the given dimensions
+func scale(source image.Image, area image.Rectangle, width int, height int) *image.RGBA {
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == area.Dx() && height == area.Dy() {
+		draw.Draw(scaled, scaled.Bounds(), source, area.Min, draw.Src)
+	} else {
+		xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), source, area, xdraw.Src, nil)
+	}
+	return scaled
+}
+
+// encode encodes the image in the format, FormatAuto picks JPEG for opaque images and PNG otherwise
+func (generator Generator) encode(source *image.RGBA, format Format) (*Encoded, error) {
+	if format == FormatAuto {
+		format = FormatPNG
+		if source.Opaque() {
+			format = FormatJPEG
+		}
+	}
+
+	bounds := source.Bounds()
+	encoded := &Encoded{Width: bounds.Dx(), Height: bounds.Dy()}
+
+	var content bytes.Buffer
+	switch format {
+	case FormatJPEG:
+		encoded.ContentType, encoded.Extension = "image/jpeg", ".jpg"
+		if !source.Opaque() {
+			// JPEG has no transparency, transparent areas become white instead of black
+			background := image.NewRGBA(bounds)
+			draw.Draw(background, bounds, image.White, image.Point{}, draw.Src)
+			draw.Draw(background, bounds, source, bounds.Min, draw.Over)
+			source = background
+		}
+		if err := jpeg.Encode(&content, source, &jpeg.Options{Quality: generator.Quality}); err != nil {
+			return nil, err
+		}
+	case FormatPNG:
+		encoded.ContentType, encoded.Extension = "image/png", ".png"
+		if err := png.Encode(&content, source); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	encoded.Content = content.Bytes()
+
+	return encoded, nil
+}
+
+// fit scales the dimensions down to fit within a square of the size, keeping the aspect ratio
+func fit(width int, height int, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
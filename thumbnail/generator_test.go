@@ -0,0 +1,161 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodedImage creates a PNG or JPEG filled with a single color
+func encodedImage(t *testing.T, width int, height int, fill color.Color, mimeType string) []byte {
+	t.Helper()
+
+	source := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			source.Set(x, y, fill)
+		}
+	}
+
+	var encoded bytes.Buffer
+	if mimeType == "image/jpeg" {
+		require.NoError(t, jpeg.Encode(&encoded, source, nil))
+	} else {
+		require.NoError(t, png.Encode(&encoded, source))
+	}
+	return encoded.Bytes()
+}
+
+func Test_Generator_Generate_ScalesToEverySize(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	generator := Generator{Sizes: []int{100, 1000}, Quality: 80}
+	content := encodedImage(t, 400, 200, color.NRGBA{R: 255, A: 255}, "image/jpeg")
+
+	// Act
+	thumbnails, err := generator.Generate(bytes.NewReader(content), "image/jpeg")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, thumbnails, 2)
+
+	assert.Equal(t, 100, thumbnails[0].Size)
+	assert.Equal(t, 100, thumbnails[0].Width)
+	assert.Equal(t, 50, thumbnails[0].Height)
+	assert.Equal(t, "image/jpeg", thumbnails[0].ContentType)
+	assert.Equal(t, ".jpg", thumbnails[0].Extension)
+	decoded, err := jpeg.DecodeConfig(bytes.NewReader(thumbnails[0].Content))
+	require.NoError(t, err)
+	assert.Equal(t, 100, decoded.Width)
+	assert.Equal(t, 50, decoded.Height)
+
+	assert.Equal(t, 1000, thumbnails[1].Size)
+	assert.Equal(t, 400, thumbnails[1].Width, "images should never be scaled up")
+	assert.Equal(t, 200, thumbnails[1].Height)
+}
+
+func Test_Generator_Generate_KeepsTransparency(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	generator := Generator{Sizes: []int{10}, Quality: 80}
+	content := encodedImage(t, 20, 40, color.NRGBA{G: 255, A: 128}, "image/png")
+
+	// Act
+	thumbnails, err := generator.Generate(bytes.NewReader(content), "image/png")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, thumbnails, 1)
+	assert.Equal(t, "image/png", thumbnails[0].ContentType)
+	assert.Equal(t, ".png", thumbnails[0].Extension)
+	assert.Equal(t, 5, thumbnails[0].Width)
+	assert.Equal(t, 10, thumbnails[0].Height)
+	_, err = png.Decode(bytes.NewReader(thumbnails[0].Content))
+	assert.NoError(t, err)
+}
+
+func Test_Generator_Generate_RefusesImagesWithTooManyPixels(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	generator := Generator{Sizes: []int{10}, Quality: 80, MaxSourcePixels: 99}
+	content := encodedImage(t, 10, 10, color.Black, "image/png")
+
+	// Act
+	_, err := generator.Generate(bytes.NewReader(content), "image/png")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
+
+func Test_Generator_Generate_FailsOnInvalidContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	generator := Generator{Sizes: []int{10}, Quality: 80}
+
+	// Act
+	_, err := generator.Generate(bytes.NewReader([]byte("not a png")), "image/png")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func Test_Generator_Supports(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		generator Generator
+		mimeType  string
+		expected  bool
+	}{
+		"jpeg":              {generator: Generator{Sizes: []int{10}}, mimeType: "image/jpeg", expected: true},
+		"gif":               {generator: Generator{Sizes: []int{10}}, mimeType: "image/gif", expected: true},
+		"webp enabled":      {generator: Generator{Sizes: []int{10}, WebP: true}, mimeType: "image/webp", expected: true},
+		"webp disabled":     {generator: Generator{Sizes: []int{10}}, mimeType: "image/webp", expected: false},
+		"not an image":      {generator: Generator{Sizes: []int{10}}, mimeType: "video/mp4", expected: false},
+		"no sizes":          {generator: Generator{}, mimeType: "image/png", expected: false},
+		"unsupported image": {generator: Generator{Sizes: []int{10}}, mimeType: "image/tiff", expected: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expected, testCase.generator.Supports(testCase.mimeType))
+		})
+	}
+}
+
+func Test_fit_KeepsAspectRatio(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		width, height, size           int
+		expectedWidth, expectedHeight int
+	}{
+		"landscape":         {width: 1000, height: 500, size: 100, expectedWidth: 100, expectedHeight: 50},
+		"portrait":          {width: 500, height: 1000, size: 100, expectedWidth: 50, expectedHeight: 100},
+		"square":            {width: 300, height: 300, size: 100, expectedWidth: 100, expectedHeight: 100},
+		"smaller than size": {width: 20, height: 10, size: 100, expectedWidth: 20, expectedHeight: 10},
+		"very narrow":       {width: 10000, height: 1, size: 100, expectedWidth: 100, expectedHeight: 1},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			width, height := fit(testCase.width, testCase.height, testCase.size)
+			assert.Equal(t, testCase.expectedWidth, width)
+			assert.Equal(t, testCase.expectedHeight, height)
+		})
+	}
+}
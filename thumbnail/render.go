@@ -0,0 +1,86 @@
+package thumbnail
+
+import (
+	"image"
+	"io"
+)
+
+// Fit decides how an image is made to fit the requested dimensions
+type Fit string
+
+const (
+	// FitContain scales the image to fit within the dimensions, keeping the aspect ratio
+	FitContain Fit = "contain"
+	// FitCover scales the image to cover the dimensions, cropping the center to keep the aspect ratio
+	FitCover Fit = "cover"
+	// FitFill stretches the image to the dimensions
+	FitFill Fit = "fill"
+)
+
+// Format is the encoding of a rendered image
+type Format string
+
+const (
+	// FormatAuto encodes opaque images as JPEG and images with transparency as PNG
+	FormatAuto Format = ""
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+)
+
+// RenderOptions describe the requested rendition of an image
+type RenderOptions struct {
+	// Width and Height of the rendition, when one of them is 0 it follows from the aspect ratio of the image
+	Width  int
+	Height int
+	Fit    Fit
+	Format Format
+}
+
+// Render decodes the content and resizes, crops and encodes it according to the options, unlike thumbnails a
+// rendition may be larger than the original
+func (generator Generator) Render(content io.Reader, mimeType string, options RenderOptions) (*Encoded, error) {
+	source, err := generator.Decode(content, mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := source.Bounds()
+	area, width, height := layout(bounds, options)
+
+	return generator.encode(scale(source, area, width, height), options.Format)
+}
+
+// layout returns the area of the source that is drawn and the dimensions it is drawn at
+func layout(bounds image.Rectangle, options RenderOptions) (image.Rectangle, int, int) {
+	sourceWidth, sourceHeight := bounds.Dx(), bounds.Dy()
+	width, height := options.Width, options.Height
+
+	// a single dimension always keeps the aspect ratio
+	switch {
+	case width == 0 && height == 0:
+		return bounds, sourceWidth, sourceHeight
+	case width == 0:
+		return bounds, max(1, sourceWidth*height/sourceHeight), height
+	case height == 0:
+		return bounds, width, max(1, sourceHeight*width/sourceWidth)
+	}
+
+	switch options.Fit {
+	case FitFill:
+		return bounds, width, height
+	case FitCover:
+		// crop the largest centered area with the requested aspect ratio
+		cropWidth, cropHeight := sourceWidth, sourceWidth*height/width
+		if cropHeight > sourceHeight {
+			cropWidth, cropHeight = sourceHeight*width/height, sourceHeight
+		}
+		cropWidth, cropHeight = max(1, cropWidth), max(1, cropHeight)
+		origin := bounds.Min.Add(image.Pt((sourceWidth-cropWidth)/2, (sourceHeight-cropHeight)/2))
+		return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(cropWidth, cropHeight))}, width, height
+	default:
+		if sourceWidth*height > sourceHeight*width {
+			return bounds, width, max(1, sourceHeight*width/sourceWidth)
+		}
+		return bounds, max(1, sourceWidth*height/sourceHeight), height
+	}
+}
@@ -0,0 +1,129 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_layout_AppliesFit(t *testing.T) {
+	t.Parallel()
+
+	// a landscape source of 400x200
+	bounds := image.Rect(0, 0, 400, 200)
+
+	testCases := map[string]struct {
+		options        RenderOptions
+		expectedArea   image.Rectangle
+		expectedWidth  int
+		expectedHeight int
+	}{
+		"contain limited by width": {
+			options:      RenderOptions{Width: 100, Height: 100, Fit: FitContain},
+			expectedArea: bounds, expectedWidth: 100, expectedHeight: 50,
+		},
+		"contain limited by height": {
+			options:      RenderOptions{Width: 1000, Height: 100, Fit: FitContain},
+			expectedArea: bounds, expectedWidth: 200, expectedHeight: 100,
+		},
+		"contain can scale up": {
+			options:      RenderOptions{Width: 800, Height: 800, Fit: FitContain},
+			expectedArea: bounds, expectedWidth: 800, expectedHeight: 400,
+		},
+		"cover crops the center": {
+			options:      RenderOptions{Width: 100, Height: 100, Fit: FitCover},
+			expectedArea: image.Rect(100, 0, 300, 200), expectedWidth: 100, expectedHeight: 100,
+		},
+		"cover crops the height": {
+			options:      RenderOptions{Width: 400, Height: 100, Fit: FitCover},
+			expectedArea: image.Rect(0, 50, 400, 150), expectedWidth: 400, expectedHeight: 100,
+		},
+		"fill stretches": {
+			options:      RenderOptions{Width: 100, Height: 100, Fit: FitFill},
+			expectedArea: bounds, expectedWidth: 100, expectedHeight: 100,
+		},
+		"only width": {
+			options:      RenderOptions{Width: 100, Fit: FitCover},
+			expectedArea: bounds, expectedWidth: 100, expectedHeight: 50,
+		},
+		"only height": {
+			options:      RenderOptions{Height: 100, Fit: FitFill},
+			expectedArea: bounds, expectedWidth: 200, expectedHeight: 100,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			area, width, height := layout(bounds, testCase.options)
+			assert.Equal(t, testCase.expectedArea, area)
+			assert.Equal(t, testCase.expectedWidth, width)
+			assert.Equal(t, testCase.expectedHeight, height)
+		})
+	}
+}
+
+func Test_Generator_Render_EncodesTheRequestedFormat(t *testing.T) {
+	t.Parallel()
+
+	generator := Generator{Quality: 80}
+	opaque := encodedImage(t, 40, 20, color.NRGBA{B: 255, A: 255}, "image/png")
+	transparent := encodedImage(t, 40, 20, color.NRGBA{B: 255, A: 100}, "image/png")
+
+	testCases := map[string]struct {
+		content             []byte
+		format              Format
+		expectedContentType string
+	}{
+		"auto opaque":      {content: opaque, format: FormatAuto, expectedContentType: "image/jpeg"},
+		"auto transparent": {content: transparent, format: FormatAuto, expectedContentType: "image/png"},
+		"jpeg transparent": {content: transparent, format: FormatJPEG, expectedContentType: "image/jpeg"},
+		"png opaque":       {content: opaque, format: FormatPNG, expectedContentType: "image/png"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			rendered, err := generator.Render(bytes.NewReader(testCase.content), "image/png", RenderOptions{
+				Width:  10,
+				Height: 10,
+				Fit:    FitCover,
+				Format: testCase.format,
+			})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedContentType, rendered.ContentType)
+			var decoded image.Image
+			if testCase.expectedContentType == "image/jpeg" {
+				decoded, err = jpeg.Decode(bytes.NewReader(rendered.Content))
+			} else {
+				decoded, err = png.Decode(bytes.NewReader(rendered.Content))
+			}
+			require.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 10, 10), decoded.Bounds())
+		})
+	}
+}
+
+func Test_Generator_Render_FailsOnUnsupportedTypes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	generator := Generator{Quality: 80}
+
+	// Act
+	_, err := generator.Render(bytes.NewReader([]byte("RIFF")), "image/webp", RenderOptions{Width: 10, Height: 10})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
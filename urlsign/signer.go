@@ -0,0 +1,108 @@
+// Package urlsign signs URLs so they grant access to their path until they expire
+package urlsign
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// ExpiresParameter is the query parameter holding the Unix time a signed URL expires at
+	ExpiresParameter = "expires"
+	// SignatureParameter is the query parameter holding the signature of a signed URL
+	SignatureParameter = "signature"
+
+	// MinKeyLength is the minimum length of a signing key, shorter keys are too easy to guess
+	MinKeyLength = 32
+)
+
+var (
+	ErrMissingSignature = errors.New("the URL is not signed")
+	ErrExpired          = errors.New("the URL has expired")
+	ErrInvalidSignature = errors.New("the signature does not match the URL")
+)
+
+// Signer signs URLs with the first of its keys, URLs signed with any of its keys are accepted. Keys are rotated by
+// adding the new key in front, the old key can be removed once the URLs it signed have expired
+type Signer struct {
+	keys       [][]byte
+	expiration time.Duration
+	now        func() time.Time
+}
+
+// New creates a signer whose URLs expire after the expiration, at least one key is required
+func New(keys []string, expiration time.Duration) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+	if expiration <= 0 {
+		return nil, errors.New("the expiration must be positive")
+	}
+
+	signer := &Signer{expiration: expiration, now: time.Now}
+	for _, key := range keys {
+		if len(key) < MinKeyLength {
+			return nil, fmt.Errorf("signing keys need at least %d characters", MinKeyLength)
+		}
+		signer.keys = append(signer.keys, []byte(key))
+	}
+
+	return signer, nil
+}
+
+// NewRandomKey generates a signing key for when none is configured
+func NewRandomKey() (string, error) {
+	key := make([]byte, MinKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// Sign returns the query parameters holding an expiry and a signature over the escaped path and the expiry
+func (signer *Signer) Sign(path string) url.Values {
+	expires := strconv.FormatInt(signer.now().Add(signer.expiration).Unix(), 10)
+
+	return url.Values{
+		ExpiresParameter:   {expires},
+		SignatureParameter: {signature(signer.keys[0], path, expires)},
+	}
+}
+
+// Verify checks that the query holds an unexpired signature of the escaped path made with any of the keys
+func (signer *Signer) Verify(path string, query url.Values) error {
+	expires, signed := query.Get(ExpiresParameter), query.Get(SignatureParameter)
+	if expires == "" || signed == "" {
+		return ErrMissingSignature
+	}
+
+	valid := false
+	for _, key := range signer.keys {
+		valid = valid || hmac.Equal([]byte(signed), []byte(signature(key, path, expires)))
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	// the expiry is signed, it only needs to be checked once the signature is known to be valid
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || signer.now().Unix() >= expiresAt {
+		return ErrExpired
+	}
+	return nil
+}
+
+// signature is the HMAC-SHA256 of the path and the expiry, the separator keeps the two apart
+func signature(key []byte, path string, expires string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(path + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
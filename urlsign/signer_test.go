@@ -0,0 +1,147 @@
+package urlsign
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	currentKey  = strings.Repeat("c", MinKeyLength)
+	previousKey = strings.Repeat("p", MinKeyLength)
+)
+
+// newSigner creates a signer whose clock is fixed at the time
+func newSigner(t *testing.T, now time.Time, keys ...string) *Signer {
+	t.Helper()
+
+	signer, err := New(keys, time.Hour)
+	require.NoError(t, err)
+	signer.now = func() time.Time { return now }
+	return signer
+}
+
+func Test_Signer_Sign_AddsExpiryAndSignature(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	signer := newSigner(t, now, currentKey)
+
+	// Act
+	query := signer.Sign("/files/abc.jpg")
+
+	// Assert
+	assert.Equal(t, "1714568400", query.Get(ExpiresParameter))
+	assert.NotEmpty(t, query.Get(SignatureParameter))
+}
+
+func Test_Signer_Verify_AcceptsSignedURLs(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	now := time.Now()
+	signer := newSigner(t, now, currentKey)
+	path := "/files/abc.jpg"
+	query := signer.Sign(path)
+
+	// Act
+	err := signer.Verify(path, query)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func Test_Signer_Verify_AcceptsURLsSignedBeforeAKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	now := time.Now()
+	path := "/files/abc.jpg"
+	query := newSigner(t, now, previousKey).Sign(path)
+	rotated := newSigner(t, now, currentKey, previousKey)
+
+	// Act
+	err := rotated.Verify(path, query)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func Test_Signer_Verify_RejectsInvalidURLs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	signer := newSigner(t, now, currentKey)
+	path := "/files/abc.jpg"
+	query := signer.Sign(path)
+
+	tests := map[string]struct {
+		signer        *Signer
+		path          string
+		query         func() url.Values
+		expectedError error
+	}{
+		"unsigned": {
+			signer:        signer,
+			path:          path,
+			query:         func() url.Values { return url.Values{} },
+			expectedError: ErrMissingSignature,
+		},
+		"other path": {
+			signer:        signer,
+			path:          "/files/other.jpg",
+			query:         func() url.Values { return query },
+			expectedError: ErrInvalidSignature,
+		},
+		"extended expiry": {
+			signer: signer,
+			path:   path,
+			query: func() url.Values {
+				extended := url.Values{}
+				extended.Set(SignatureParameter, query.Get(SignatureParameter))
+				extended.Set(ExpiresParameter, "99999999999")
+				return extended
+			},
+			expectedError: ErrInvalidSignature,
+		},
+		"removed key": {
+			signer:        newSigner(t, now, previousKey),
+			path:          path,
+			query:         func() url.Values { return query },
+			expectedError: ErrInvalidSignature,
+		},
+		"expired": {
+			signer:        newSigner(t, now.Add(2*time.Hour), currentKey),
+			path:          path,
+			query:         func() url.Values { return query },
+			expectedError: ErrExpired,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := test.signer.Verify(test.path, test.query())
+
+			// Assert
+			assert.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
+
+func Test_New_RejectsShortKeys(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := New([]string{"secret"}, time.Hour)
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -15,7 +15,7 @@ import (
 func NewInMemoryDatabase(t *testing.T, models ...any) *gorm.DB {
 	t.Helper()
 
-	database, err := gorm.Open(sqlite.Open(":memory:"))
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		t.Error(err)
 		return nil